@@ -0,0 +1,45 @@
+-- ============================================
+-- RECURRING EXPENSE RULES
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS recurring_expense_rules (
+    id             SERIAL PRIMARY KEY,
+    user_id        INT            NOT NULL REFERENCES users (id),
+    title          VARCHAR(255)   NOT NULL,
+    amount         NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    category_id    INT            REFERENCES expense_categories (id),
+    notes          TEXT,
+    frequency      VARCHAR(16)    NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly', 'custom')),
+    interval_count INT            NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+    day_of_month   INT            CHECK (day_of_month BETWEEN -1 AND 31 AND day_of_month <> 0),
+    rrule          TEXT,
+    start_date     DATE           NOT NULL,
+    end_date       DATE,
+    next_run_date  DATE,
+    last_run_date  DATE,
+    is_paused      BOOLEAN        NOT NULL DEFAULT FALSE,
+    created_at     TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at     TIMESTAMP,
+    CHECK (end_date IS NULL OR end_date >= start_date),
+    CHECK (frequency <> 'custom' OR rrule IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_expense_rules_user
+    ON recurring_expense_rules (user_id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_recurring_expense_rules_due
+    ON recurring_expense_rules (next_run_date)
+    WHERE deleted_at IS NULL AND is_paused = FALSE;
+
+-- One row per materialised occurrence. The unique key is what makes the
+-- scheduler idempotent: an occurrence is claimed before its expense is created.
+CREATE TABLE IF NOT EXISTS recurring_expense_occurrences (
+    id              SERIAL PRIMARY KEY,
+    rule_id         INT       NOT NULL REFERENCES recurring_expense_rules (id) ON DELETE CASCADE,
+    occurrence_date DATE      NOT NULL,
+    expense_id      INT       REFERENCES expenses (id),
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (rule_id, occurrence_date)
+);
@@ -0,0 +1,55 @@
+-- ============================================
+-- BUDGETS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id          SERIAL PRIMARY KEY,
+    user_id     INT            NOT NULL REFERENCES users (id),
+    name        VARCHAR(255)   NOT NULL,
+    category_id INT            REFERENCES expense_categories (id), -- NULL = overall budget
+    amount      NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    period      VARCHAR(16)    NOT NULL CHECK (period IN ('weekly', 'monthly', 'custom')),
+    start_date  DATE           NOT NULL,
+    end_date    DATE,
+    rollover    BOOLEAN        NOT NULL DEFAULT FALSE,
+    thresholds  INT[]          NOT NULL DEFAULT '{80,100}',
+    created_at  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP,
+    CHECK (end_date IS NULL OR end_date >= start_date),
+    CHECK (period <> 'custom' OR end_date IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_user
+    ON budgets (user_id)
+    WHERE deleted_at IS NULL;
+
+-- One row per (budget, period, threshold) so each alert fires only once per period
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id           SERIAL PRIMARY KEY,
+    budget_id    INT            NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    period_start DATE           NOT NULL,
+    threshold    INT            NOT NULL,
+    spent        NUMERIC(12, 2) NOT NULL,
+    created_at   TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (budget_id, period_start, threshold)
+);
+
+-- ============================================
+-- IN-APP NOTIFICATIONS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id),
+    type       VARCHAR(64)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    message    TEXT         NOT NULL,
+    data       JSONB,
+    read_at    TIMESTAMP,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+    ON notifications (user_id, created_at DESC)
+    WHERE read_at IS NULL;
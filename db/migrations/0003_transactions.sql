@@ -0,0 +1,294 @@
+-- ============================================
+-- ACCOUNTS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id),
+    name       VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_user
+    ON accounts (user_id)
+    WHERE deleted_at IS NULL;
+
+-- ============================================
+-- CATEGORY KIND (expense / income)
+-- ============================================
+
+ALTER TABLE expense_categories
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'expense'
+        CHECK (kind IN ('expense', 'income'));
+
+-- get_categories with a kind filter; the 2-argument version is kept for existing callers
+CREATE OR REPLACE FUNCTION get_categories(p_limit INT, p_offset INT, p_kind TEXT)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_total INT;
+    v_items JSONB;
+BEGIN
+    SELECT COUNT(*) INTO v_total
+    FROM expense_categories
+    WHERE p_kind IS NULL OR kind = p_kind;
+
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'id', c.id,
+               'name', c.name,
+               'description', c.description,
+               'kind', c.kind,
+               'createdAt', c.created_at,
+               'updatedAt', c.updated_at
+           ) ORDER BY c.name, c.id), '[]'::jsonb)
+    INTO v_items
+    FROM (
+        SELECT * FROM expense_categories
+        WHERE p_kind IS NULL OR kind = p_kind
+        ORDER BY name, id
+        LIMIT p_limit OFFSET p_offset
+    ) c;
+
+    RETURN jsonb_build_object(
+        'categories', v_items,
+        'pagination', jsonb_build_object('total', v_total, 'limit', p_limit, 'offset', p_offset)
+    );
+END;
+$$;
+
+-- ============================================
+-- TRANSACTIONS (expense / income / transfer)
+-- ============================================
+
+ALTER TABLE expenses RENAME TO transactions;
+
+ALTER TABLE transactions
+    ADD COLUMN type                VARCHAR(16) NOT NULL DEFAULT 'expense'
+        CHECK (type IN ('expense', 'income', 'transfer')),
+    ADD COLUMN account_id          INT REFERENCES accounts (id),
+    ADD COLUMN transfer_account_id INT REFERENCES accounts (id),
+    ADD CONSTRAINT transactions_transfer_accounts_check CHECK (
+        type <> 'transfer'
+        OR (account_id IS NOT NULL AND transfer_account_id IS NOT NULL AND account_id <> transfer_account_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_type_date
+    ON transactions (user_id, type, date)
+    WHERE deleted_at IS NULL;
+
+-- Keep category kinds and account ownership consistent, whichever path writes the row
+CREATE OR REPLACE FUNCTION check_transaction()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_kind TEXT;
+BEGIN
+    IF NEW.type = 'transfer' THEN
+        IF NEW.category_id IS NOT NULL THEN
+            RAISE EXCEPTION 'invalid transaction: transfers cannot have a category';
+        END IF;
+    ELSIF NEW.category_id IS NOT NULL THEN
+        SELECT kind INTO v_kind FROM expense_categories WHERE id = NEW.category_id;
+        IF v_kind IS DISTINCT FROM NEW.type THEN
+            RAISE EXCEPTION 'invalid transaction: category % is not an % category', NEW.category_id, NEW.type;
+        END IF;
+    END IF;
+
+    IF NEW.account_id IS NOT NULL AND NOT EXISTS (
+        SELECT 1 FROM accounts WHERE id = NEW.account_id AND user_id = NEW.user_id AND deleted_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'invalid transaction: account % not found', NEW.account_id;
+    END IF;
+
+    IF NEW.transfer_account_id IS NOT NULL AND NOT EXISTS (
+        SELECT 1 FROM accounts WHERE id = NEW.transfer_account_id AND user_id = NEW.user_id AND deleted_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'invalid transaction: account % not found', NEW.transfer_account_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_check_transaction ON transactions;
+CREATE TRIGGER trg_check_transaction
+    BEFORE INSERT OR UPDATE OF type, category_id, account_id, transfer_account_id ON transactions
+    FOR EACH ROW EXECUTE FUNCTION check_transaction();
+
+-- Compatible view for the existing expense functions and endpoints.
+-- Inserts through the view get type = 'expense' from the column default.
+CREATE OR REPLACE VIEW expenses AS
+    SELECT * FROM transactions
+    WHERE type = 'expense'
+    WITH CASCADED CHECK OPTION;
+
+-- ============================================
+-- TRANSACTION FUNCTIONS
+-- ============================================
+
+-- Single source of truth for list filters (keys follow ExpenseFilters / TransactionFilters)
+CREATE OR REPLACE FUNCTION filter_transactions(p_user_id INT, p_filters JSONB)
+RETURNS SETOF transactions
+LANGUAGE sql STABLE AS $$
+    SELECT t.*
+    FROM transactions t
+    WHERE t.user_id = p_user_id
+      AND t.deleted_at IS NULL
+      AND (p_filters->>'type' IS NULL OR t.type = p_filters->>'type')
+      AND (p_filters->>'title' IS NULL OR t.title ILIKE '%' || (p_filters->>'title') || '%')
+      AND (p_filters->>'minAmount' IS NULL OR t.amount >= (p_filters->>'minAmount')::NUMERIC)
+      AND (p_filters->>'maxAmount' IS NULL OR t.amount <= (p_filters->>'maxAmount')::NUMERIC)
+      AND (p_filters->>'categoryId' IS NULL OR t.category_id = (p_filters->>'categoryId')::INT)
+      AND (p_filters->>'accountId' IS NULL
+           OR t.account_id = (p_filters->>'accountId')::INT
+           OR t.transfer_account_id = (p_filters->>'accountId')::INT)
+      AND (p_filters->>'startDate' IS NULL OR t.date >= (p_filters->>'startDate')::DATE)
+      AND (p_filters->>'endDate' IS NULL OR t.date <= (p_filters->>'endDate')::DATE)
+$$;
+
+CREATE OR REPLACE FUNCTION transaction_to_jsonb(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', t.id,
+        'type', t.type,
+        'title', t.title,
+        'amount', t.amount,
+        'category', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = t.category_id
+        ),
+        'accountId', t.account_id,
+        'transferAccountId', t.transfer_account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION get_transactions(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_limit   INT := COALESCE((p_filters->>'limit')::INT, 50);
+    v_offset  INT := COALESCE((p_filters->>'offset')::INT, 0);
+    v_total   INT;
+    v_income  NUMERIC;
+    v_expense NUMERIC;
+    v_items   JSONB;
+BEGIN
+    SELECT COUNT(*),
+           COALESCE(SUM(amount) FILTER (WHERE type = 'income'), 0),
+           COALESCE(SUM(amount) FILTER (WHERE type = 'expense'), 0)
+    INTO v_total, v_income, v_expense
+    FROM filter_transactions(p_user_id, p_filters);
+
+    -- Select the whole row so it keeps the transactions type for transaction_to_jsonb
+    SELECT COALESCE(jsonb_agg(transaction_to_jsonb(s.t) ORDER BY (s.t).date DESC, (s.t).id DESC), '[]'::jsonb)
+    INTO v_items
+    FROM (
+        SELECT t FROM filter_transactions(p_user_id, p_filters) t
+        ORDER BY t.date DESC, t.id DESC
+        LIMIT v_limit OFFSET v_offset
+    ) s;
+
+    RETURN jsonb_build_object(
+        'transactions', v_items,
+        'summary', jsonb_build_object('income', v_income, 'expense', v_expense, 'net', v_income - v_expense),
+        'pagination', jsonb_build_object('total', v_total, 'limit', v_limit, 'offset', v_offset)
+    );
+END;
+$$;
+
+-- /expenses listing is now the expense slice of get_transactions
+DROP FUNCTION IF EXISTS get_expenses(INT, JSONB);
+CREATE FUNCTION get_expenses(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object('expenses', r->'transactions', 'pagination', r->'pagination')
+    FROM get_transactions(p_user_id, p_filters || jsonb_build_object('type', 'expense')) r
+$$;
+
+CREATE OR REPLACE FUNCTION get_transaction_by_id(p_user_id INT, p_transaction_id INT)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT transaction_to_jsonb(t)
+    FROM transactions t
+    WHERE t.id = p_transaction_id AND t.user_id = p_user_id AND t.deleted_at IS NULL
+$$;
+
+CREATE OR REPLACE FUNCTION create_transaction(p_user_id INT, p_data JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row transactions;
+BEGIN
+    INSERT INTO transactions
+        (user_id, type, title, amount, category_id, account_id, transfer_account_id, date, notes, image_url)
+    VALUES (
+        p_user_id,
+        p_data->>'type',
+        p_data->>'title',
+        (p_data->>'amount')::NUMERIC,
+        (p_data->>'categoryId')::INT,
+        (p_data->>'accountId')::INT,
+        (p_data->>'transferAccountId')::INT,
+        (p_data->>'date')::DATE,
+        p_data->>'notes',
+        p_data->>'imageUrl'
+    )
+    RETURNING * INTO v_row;
+
+    RETURN transaction_to_jsonb(v_row);
+END;
+$$;
+
+-- Partial update: keys that are absent or null keep their current value
+CREATE OR REPLACE FUNCTION update_transaction(p_user_id INT, p_transaction_id INT, p_data JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row transactions;
+BEGIN
+    UPDATE transactions SET
+        type                = COALESCE(p_data->>'type', type),
+        title               = COALESCE(p_data->>'title', title),
+        amount              = COALESCE((p_data->>'amount')::NUMERIC, amount),
+        category_id         = CASE WHEN COALESCE(p_data->>'type', type) = 'transfer' THEN NULL
+                                   ELSE COALESCE((p_data->>'categoryId')::INT, category_id) END,
+        account_id          = COALESCE((p_data->>'accountId')::INT, account_id),
+        transfer_account_id = CASE WHEN COALESCE(p_data->>'type', type) <> 'transfer' THEN NULL
+                                   ELSE COALESCE((p_data->>'transferAccountId')::INT, transfer_account_id) END,
+        date                = COALESCE((p_data->>'date')::DATE, date),
+        notes               = COALESCE(p_data->>'notes', notes),
+        image_url           = COALESCE(p_data->>'imageUrl', image_url),
+        updated_at          = CURRENT_TIMESTAMP
+    WHERE id = p_transaction_id AND user_id = p_user_id AND deleted_at IS NULL
+    RETURNING * INTO v_row;
+
+    IF v_row.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+    RETURN transaction_to_jsonb(v_row);
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION delete_transaction(p_user_id INT, p_transaction_id INT)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_image_url TEXT;
+    v_found     BOOLEAN;
+BEGIN
+    UPDATE transactions
+    SET deleted_at = CURRENT_TIMESTAMP
+    WHERE id = p_transaction_id AND user_id = p_user_id AND deleted_at IS NULL
+    RETURNING image_url, TRUE INTO v_image_url, v_found;
+
+    RETURN jsonb_build_object('isDeleted', COALESCE(v_found, FALSE), 'imageUrl', v_image_url);
+END;
+$$;
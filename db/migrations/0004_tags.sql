@@ -0,0 +1,99 @@
+-- ============================================
+-- TAGS
+-- ============================================
+
+-- Per-user free-form tags; names are stored trimmed and lower-cased
+CREATE TABLE IF NOT EXISTS tags (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT         NOT NULL REFERENCES users (id),
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id INT NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    tag_id         INT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag
+    ON transaction_tags (tag_id);
+
+-- ============================================
+-- TRANSACTION FUNCTIONS (tags)
+-- ============================================
+
+CREATE OR REPLACE FUNCTION transaction_to_jsonb(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', t.id,
+        'type', t.type,
+        'title', t.title,
+        'amount', t.amount,
+        'category', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = t.category_id
+        ),
+        'accountId', t.account_id,
+        'transferAccountId', t.transfer_account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb),
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at
+    )
+$$;
+
+-- tags: array of tag names; tagMatch: 'any' (default) or 'all'
+CREATE OR REPLACE FUNCTION filter_transactions(p_user_id INT, p_filters JSONB)
+RETURNS SETOF transactions
+LANGUAGE sql STABLE AS $$
+    WITH wanted AS (
+        SELECT DISTINCT lower(trim(x)) AS name
+        FROM jsonb_array_elements_text(
+            CASE WHEN jsonb_typeof(p_filters->'tags') = 'array' THEN p_filters->'tags' ELSE '[]'::jsonb END
+        ) x
+    )
+    SELECT t.*
+    FROM transactions t
+    WHERE t.user_id = p_user_id
+      AND t.deleted_at IS NULL
+      AND (p_filters->>'type' IS NULL OR t.type = p_filters->>'type')
+      AND (p_filters->>'title' IS NULL OR t.title ILIKE '%' || (p_filters->>'title') || '%')
+      AND (p_filters->>'minAmount' IS NULL OR t.amount >= (p_filters->>'minAmount')::NUMERIC)
+      AND (p_filters->>'maxAmount' IS NULL OR t.amount <= (p_filters->>'maxAmount')::NUMERIC)
+      AND (p_filters->>'categoryId' IS NULL OR t.category_id = (p_filters->>'categoryId')::INT)
+      AND (p_filters->>'accountId' IS NULL
+           OR t.account_id = (p_filters->>'accountId')::INT
+           OR t.transfer_account_id = (p_filters->>'accountId')::INT)
+      AND (p_filters->>'startDate' IS NULL OR t.date >= (p_filters->>'startDate')::DATE)
+      AND (p_filters->>'endDate' IS NULL OR t.date <= (p_filters->>'endDate')::DATE)
+      AND (
+          NOT EXISTS (SELECT 1 FROM wanted)
+          OR (
+              SELECT COUNT(*)
+              FROM transaction_tags tt
+              JOIN tags tg ON tg.id = tt.tag_id
+              WHERE tt.transaction_id = t.id AND tg.name IN (SELECT name FROM wanted)
+          ) >= CASE WHEN p_filters->>'tagMatch' = 'all' THEN (SELECT COUNT(*) FROM wanted) ELSE 1 END
+      )
+$$;
+
+-- Expenses read back through transaction_to_jsonb so they carry their tags
+DROP FUNCTION IF EXISTS get_expense_by_id(INT, INT);
+CREATE FUNCTION get_expense_by_id(p_user_id INT, p_expense_id INT)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT transaction_to_jsonb(t)
+    FROM transactions t
+    WHERE t.id = p_expense_id AND t.user_id = p_user_id AND t.type = 'expense' AND t.deleted_at IS NULL
+$$;
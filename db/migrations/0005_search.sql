@@ -0,0 +1,186 @@
+-- ============================================
+-- FULL-TEXT SEARCH
+-- ============================================
+
+-- Title (A), category name and tags (B), notes (C); 'simple' keeps mixed-language words unstemmed
+CREATE OR REPLACE FUNCTION build_transaction_search_vector(
+    p_transaction_id INT, p_title TEXT, p_notes TEXT, p_category_id INT
+)
+RETURNS TSVECTOR
+LANGUAGE sql STABLE AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_title, '')), 'A')
+        || setweight(to_tsvector('simple', COALESCE(
+               (SELECT name FROM expense_categories WHERE id = p_category_id), '')), 'B')
+        || setweight(to_tsvector('simple', COALESCE(
+               (SELECT string_agg(tg.name, ' ')
+                FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+                WHERE tt.transaction_id = p_transaction_id), '')), 'B')
+        || setweight(to_tsvector('simple', COALESCE(p_notes, '')), 'C')
+$$;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+UPDATE transactions
+SET search_vector = build_transaction_search_vector(id, title, notes, category_id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_search
+    ON transactions USING GIN (search_vector);
+
+-- Keep the vector current from every source it is built from
+
+CREATE OR REPLACE FUNCTION trg_transactions_search_vector()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := build_transaction_search_vector(NEW.id, NEW.title, NEW.notes, NEW.category_id);
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_transactions_search_vector ON transactions;
+CREATE TRIGGER trg_transactions_search_vector
+    BEFORE INSERT OR UPDATE OF title, notes, category_id ON transactions
+    FOR EACH ROW EXECUTE FUNCTION trg_transactions_search_vector();
+
+CREATE OR REPLACE FUNCTION trg_transaction_tags_search_vector()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_transaction_id INT := CASE WHEN TG_OP = 'DELETE' THEN OLD.transaction_id ELSE NEW.transaction_id END;
+BEGIN
+    UPDATE transactions
+    SET search_vector = build_transaction_search_vector(id, title, notes, category_id)
+    WHERE id = v_transaction_id;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_transaction_tags_search_vector ON transaction_tags;
+CREATE TRIGGER trg_transaction_tags_search_vector
+    AFTER INSERT OR DELETE ON transaction_tags
+    FOR EACH ROW EXECUTE FUNCTION trg_transaction_tags_search_vector();
+
+CREATE OR REPLACE FUNCTION trg_tags_search_vector()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE transactions t
+    SET search_vector = build_transaction_search_vector(t.id, t.title, t.notes, t.category_id)
+    FROM transaction_tags tt
+    WHERE tt.tag_id = NEW.id AND tt.transaction_id = t.id;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_tags_search_vector ON tags;
+CREATE TRIGGER trg_tags_search_vector
+    AFTER UPDATE OF name ON tags
+    FOR EACH ROW EXECUTE FUNCTION trg_tags_search_vector();
+
+CREATE OR REPLACE FUNCTION trg_categories_search_vector()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE transactions
+    SET search_vector = build_transaction_search_vector(id, title, notes, category_id)
+    WHERE category_id = NEW.id;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_categories_search_vector ON expense_categories;
+CREATE TRIGGER trg_categories_search_vector
+    AFTER UPDATE OF name ON expense_categories
+    FOR EACH ROW EXECUTE FUNCTION trg_categories_search_vector();
+
+-- ============================================
+-- TRANSACTION FUNCTIONS (search)
+-- ============================================
+
+-- searchQuery: a to_tsquery expression built by the API from the q parameter
+CREATE OR REPLACE FUNCTION filter_transactions(p_user_id INT, p_filters JSONB)
+RETURNS SETOF transactions
+LANGUAGE sql STABLE AS $$
+    WITH wanted AS (
+        SELECT DISTINCT lower(trim(x)) AS name
+        FROM jsonb_array_elements_text(
+            CASE WHEN jsonb_typeof(p_filters->'tags') = 'array' THEN p_filters->'tags' ELSE '[]'::jsonb END
+        ) x
+    )
+    SELECT t.*
+    FROM transactions t
+    WHERE t.user_id = p_user_id
+      AND t.deleted_at IS NULL
+      AND (p_filters->>'type' IS NULL OR t.type = p_filters->>'type')
+      AND (p_filters->>'title' IS NULL OR t.title ILIKE '%' || (p_filters->>'title') || '%')
+      AND (p_filters->>'searchQuery' IS NULL
+           OR t.search_vector @@ to_tsquery('simple', p_filters->>'searchQuery'))
+      AND (p_filters->>'minAmount' IS NULL OR t.amount >= (p_filters->>'minAmount')::NUMERIC)
+      AND (p_filters->>'maxAmount' IS NULL OR t.amount <= (p_filters->>'maxAmount')::NUMERIC)
+      AND (p_filters->>'categoryId' IS NULL OR t.category_id = (p_filters->>'categoryId')::INT)
+      AND (p_filters->>'accountId' IS NULL
+           OR t.account_id = (p_filters->>'accountId')::INT
+           OR t.transfer_account_id = (p_filters->>'accountId')::INT)
+      AND (p_filters->>'startDate' IS NULL OR t.date >= (p_filters->>'startDate')::DATE)
+      AND (p_filters->>'endDate' IS NULL OR t.date <= (p_filters->>'endDate')::DATE)
+      AND (
+          NOT EXISTS (SELECT 1 FROM wanted)
+          OR (
+              SELECT COUNT(*)
+              FROM transaction_tags tt
+              JOIN tags tg ON tg.id = tt.tag_id
+              WHERE tt.transaction_id = t.id AND tg.name IN (SELECT name FROM wanted)
+          ) >= CASE WHEN p_filters->>'tagMatch' = 'all' THEN (SELECT COUNT(*) FROM wanted) ELSE 1 END
+      )
+$$;
+
+-- With a search query, results are ordered by relevance and carry rank and highlighted snippets
+CREATE OR REPLACE FUNCTION get_transactions(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_limit   INT := COALESCE((p_filters->>'limit')::INT, 50);
+    v_offset  INT := COALESCE((p_filters->>'offset')::INT, 0);
+    v_query   TSQUERY := CASE WHEN p_filters->>'searchQuery' IS NULL THEN NULL
+                              ELSE to_tsquery('simple', p_filters->>'searchQuery') END;
+    v_total   INT;
+    v_income  NUMERIC;
+    v_expense NUMERIC;
+    v_items   JSONB;
+BEGIN
+    SELECT COUNT(*),
+           COALESCE(SUM(amount) FILTER (WHERE type = 'income'), 0),
+           COALESCE(SUM(amount) FILTER (WHERE type = 'expense'), 0)
+    INTO v_total, v_income, v_expense
+    FROM filter_transactions(p_user_id, p_filters);
+
+    -- Select the whole row so it keeps the transactions type for transaction_to_jsonb
+    SELECT COALESCE(jsonb_agg(
+               CASE WHEN v_query IS NULL THEN transaction_to_jsonb(s.t)
+               ELSE transaction_to_jsonb(s.t) || jsonb_build_object(
+                   'rank', s.rank,
+                   'highlights', jsonb_build_object(
+                       'title', ts_headline('simple', (s.t).title, v_query,
+                                            'StartSel=<mark>, StopSel=</mark>, HighlightAll=TRUE'),
+                       'notes', CASE WHEN (s.t).notes IS NULL THEN NULL
+                                ELSE ts_headline('simple', (s.t).notes, v_query,
+                                                 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')
+                                END
+                   )
+               ) END
+               ORDER BY s.rank DESC NULLS LAST, (s.t).date DESC, (s.t).id DESC), '[]'::jsonb)
+    INTO v_items
+    FROM (
+        SELECT t, CASE WHEN v_query IS NULL THEN NULL ELSE ts_rank(t.search_vector, v_query) END AS rank
+        FROM filter_transactions(p_user_id, p_filters) t
+        ORDER BY rank DESC NULLS LAST, t.date DESC, t.id DESC
+        LIMIT v_limit OFFSET v_offset
+    ) s;
+
+    RETURN jsonb_build_object(
+        'transactions', v_items,
+        'summary', jsonb_build_object('income', v_income, 'expense', v_expense, 'net', v_income - v_expense),
+        'pagination', jsonb_build_object('total', v_total, 'limit', v_limit, 'offset', v_offset)
+    );
+END;
+$$;
@@ -0,0 +1,160 @@
+-- ============================================
+-- SORTING AND KEYSET (CURSOR) PAGINATION
+-- ============================================
+
+-- Keyset lookups for the default date sort and the other sortable columns
+CREATE INDEX IF NOT EXISTS idx_transactions_user_date_id
+    ON transactions (user_id, date, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_amount_id
+    ON transactions (user_id, amount, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_created_id
+    ON transactions (user_id, created_at, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_title_id
+    ON transactions (user_id, title, id)
+    WHERE deleted_at IS NULL;
+
+-- Filter keys (besides those of filter_transactions):
+--   sortBy: date | amount | createdAt | title | relevance (needs searchQuery)
+--   order:  asc | desc
+--   cursor: {"value": <sort key as text>, "id": <row id>, "dir": "next" | "prev"}
+--           the row the page continues after (next) or before (prev); offset is ignored
+-- Besides the page, returns "cursors": {"next": {value, id}, "prev": {value, id}}
+-- with the keys the API turns into opaque nextCursor / prevCursor tokens.
+CREATE OR REPLACE FUNCTION get_transactions(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_limit     INT := COALESCE((p_filters->>'limit')::INT, 50);
+    v_offset    INT := COALESCE((p_filters->>'offset')::INT, 0);
+    v_query     TSQUERY := CASE WHEN p_filters->>'searchQuery' IS NULL THEN NULL
+                                ELSE to_tsquery('simple', p_filters->>'searchQuery') END;
+    v_sort_by   TEXT := COALESCE(p_filters->>'sortBy',
+                                 CASE WHEN p_filters->>'searchQuery' IS NULL THEN 'date' ELSE 'relevance' END);
+    v_desc      BOOLEAN := COALESCE(p_filters->>'order', 'desc') = 'desc';
+    v_cursor    JSONB := CASE WHEN jsonb_typeof(p_filters->'cursor') = 'object' THEN p_filters->'cursor' END;
+    v_prev      BOOLEAN := COALESCE(p_filters->'cursor'->>'dir', 'next') = 'prev';
+    v_sort_expr TEXT;
+    v_sort_type TEXT;
+    v_scan_desc BOOLEAN;
+    v_sql       TEXT;
+    v_rows      JSONB;
+    v_has_more  BOOLEAN;
+    v_items     JSONB;
+    v_first     JSONB;
+    v_last      JSONB;
+    v_next      JSONB;
+    v_prev_key  JSONB;
+    v_total     INT;
+    v_income    NUMERIC;
+    v_expense   NUMERIC;
+BEGIN
+    CASE v_sort_by
+        WHEN 'date'      THEN v_sort_expr := 't.date';       v_sort_type := 'date';
+        WHEN 'amount'    THEN v_sort_expr := 't.amount';     v_sort_type := 'numeric';
+        WHEN 'createdAt' THEN v_sort_expr := 't.created_at'; v_sort_type := 'timestamp';
+        WHEN 'title'     THEN v_sort_expr := 't.title';      v_sort_type := 'text';
+        WHEN 'relevance' THEN
+            IF v_query IS NULL THEN
+                RAISE EXCEPTION 'sortBy relevance requires a search query';
+            END IF;
+            v_sort_expr := 'ts_rank(t.search_vector, $3)'; v_sort_type := 'real';
+        ELSE
+            RAISE EXCEPTION 'unsupported sortBy %', v_sort_by;
+    END CASE;
+
+    SELECT COUNT(*),
+           COALESCE(SUM(amount) FILTER (WHERE type = 'income'), 0),
+           COALESCE(SUM(amount) FILTER (WHERE type = 'expense'), 0)
+    INTO v_total, v_income, v_expense
+    FROM filter_transactions(p_user_id, p_filters);
+
+    -- Previous pages are read walking backwards and flipped afterwards
+    v_scan_desc := v_desc <> (v_cursor IS NOT NULL AND v_prev);
+
+    -- Fetch one extra row to know whether more rows follow in the scan direction
+    v_sql := format($f$
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+                   'item', CASE WHEN $3 IS NULL THEN transaction_to_jsonb(s.t)
+                           ELSE transaction_to_jsonb(s.t) || jsonb_build_object(
+                               'rank', ts_rank((s.t).search_vector, $3),
+                               'highlights', jsonb_build_object(
+                                   'title', ts_headline('simple', (s.t).title, $3,
+                                                        'StartSel=<mark>, StopSel=</mark>, HighlightAll=TRUE'),
+                                   'notes', CASE WHEN (s.t).notes IS NULL THEN NULL
+                                            ELSE ts_headline('simple', (s.t).notes, $3,
+                                                             'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')
+                                            END))
+                           END,
+                   'key', jsonb_build_object('value', s.sort_key::text, 'id', (s.t).id)
+               ) ORDER BY s.sort_key %1$s, (s.t).id %1$s), '[]'::jsonb)
+        FROM (
+            SELECT t, %2$s AS sort_key
+            FROM filter_transactions($1, $2) t
+            WHERE %3$s
+            ORDER BY %2$s %1$s, t.id %1$s
+            LIMIT $5 OFFSET $6
+        ) s
+    $f$,
+        CASE WHEN v_scan_desc THEN 'DESC' ELSE 'ASC' END,
+        v_sort_expr,
+        CASE WHEN v_cursor IS NULL THEN 'TRUE'
+             ELSE format('(%s, t.id) %s ($4::%s, $7::INT)',
+                         v_sort_expr, CASE WHEN v_scan_desc THEN '<' ELSE '>' END, v_sort_type)
+        END
+    );
+
+    EXECUTE v_sql INTO v_rows
+    USING p_user_id, p_filters, v_query, v_cursor->>'value', v_limit + 1,
+          CASE WHEN v_cursor IS NULL THEN v_offset ELSE 0 END, v_cursor->>'id';
+
+    v_has_more := jsonb_array_length(v_rows) > v_limit;
+
+    -- Trim the look-ahead row and restore the requested order
+    SELECT COALESCE(jsonb_agg(e ORDER BY CASE WHEN v_cursor IS NOT NULL AND v_prev THEN -i ELSE i END), '[]'::jsonb)
+    INTO v_rows
+    FROM jsonb_array_elements(v_rows) WITH ORDINALITY a(e, i)
+    WHERE i <= v_limit;
+
+    SELECT COALESCE(jsonb_agg(e->'item' ORDER BY i), '[]'::jsonb)
+    INTO v_items
+    FROM jsonb_array_elements(v_rows) WITH ORDINALITY a(e, i);
+
+    v_first := v_rows->0->'key';
+    v_last  := v_rows->-1->'key';
+
+    IF v_cursor IS NULL THEN
+        v_next     := CASE WHEN v_has_more THEN v_last END;
+        v_prev_key := CASE WHEN v_offset > 0 THEN v_first END;
+    ELSIF v_prev THEN
+        v_next     := v_last;
+        v_prev_key := CASE WHEN v_has_more THEN v_first END;
+    ELSE
+        v_next     := CASE WHEN v_has_more THEN v_last END;
+        v_prev_key := v_first;
+    END IF;
+
+    RETURN jsonb_build_object(
+        'transactions', v_items,
+        'summary', jsonb_build_object('income', v_income, 'expense', v_expense, 'net', v_income - v_expense),
+        'pagination', jsonb_build_object(
+            'total', v_total,
+            'limit', v_limit,
+            'offset', CASE WHEN v_cursor IS NULL THEN v_offset ELSE NULL END
+        ),
+        'cursors', jsonb_build_object('next', v_next, 'prev', v_prev_key)
+    );
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION get_expenses(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object('expenses', r->'transactions', 'pagination', r->'pagination', 'cursors', r->'cursors')
+    FROM get_transactions(p_user_id, p_filters || jsonb_build_object('type', 'expense')) r
+$$;
@@ -0,0 +1,170 @@
+-- ============================================
+-- SPENDING REPORTS
+-- ============================================
+
+-- Every report takes the ExpenseFilters JSON and covers expenses only.
+-- startDate and endDate are always set by the API (default: current month to date).
+
+CREATE OR REPLACE FUNCTION report_by_category(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    WITH totals AS (
+        SELECT f.category_id, SUM(f.amount) AS total, COUNT(*) AS cnt
+        FROM filter_transactions(p_user_id, p_filters || '{"type": "expense"}'::jsonb) f
+        GROUP BY f.category_id
+    ), shares AS (
+        SELECT t.*, ROUND(t.total * 100 / NULLIF(SUM(t.total) OVER (), 0), 2) AS share
+        FROM totals t
+    )
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'categoryId', s.category_id,
+               'categoryName', COALESCE(c.name, 'Uncategorized'),
+               'total', s.total,
+               'count', s.cnt,
+               'share', COALESCE(s.share, 0)
+           ) ORDER BY s.total DESC, c.name), '[]'::jsonb)
+    FROM shares s
+    LEFT JOIN expense_categories c ON c.id = s.category_id
+$$;
+
+-- p_interval: day | week | month | year; weeks start on Monday. Empty periods are included.
+CREATE OR REPLACE FUNCTION report_over_time(p_user_id INT, p_filters JSONB, p_interval TEXT)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_step   INTERVAL;
+    v_result JSONB;
+BEGIN
+    IF p_interval NOT IN ('day', 'week', 'month', 'year') THEN
+        RAISE EXCEPTION 'unsupported interval %', p_interval;
+    END IF;
+    v_step := ('1 ' || p_interval)::INTERVAL;
+
+    WITH buckets AS (
+        SELECT b::DATE AS period_start
+        FROM generate_series(
+            date_trunc(p_interval, (p_filters->>'startDate')::TIMESTAMP),
+            date_trunc(p_interval, (p_filters->>'endDate')::TIMESTAMP),
+            v_step
+        ) b
+    ), totals AS (
+        SELECT date_trunc(p_interval, f.date::TIMESTAMP)::DATE AS period_start,
+               SUM(f.amount) AS total,
+               COUNT(*) AS cnt
+        FROM filter_transactions(p_user_id, p_filters || '{"type": "expense"}'::jsonb) f
+        GROUP BY 1
+    )
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'periodStart', b.period_start,
+               'periodEnd', (b.period_start + v_step - INTERVAL '1 day')::DATE,
+               'total', COALESCE(t.total, 0),
+               'count', COALESCE(t.cnt, 0)
+           ) ORDER BY b.period_start), '[]'::jsonb)
+    INTO v_result
+    FROM buckets b
+    LEFT JOIN totals t ON t.period_start = b.period_start;
+
+    RETURN v_result;
+END;
+$$;
+
+-- An expense with several tags counts toward each of them; "tag": null holds untagged expenses
+CREATE OR REPLACE FUNCTION report_by_tag(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'tag', x.tag,
+               'total', x.total,
+               'count', x.cnt
+           ) ORDER BY x.total DESC, x.tag), '[]'::jsonb)
+    FROM (
+        SELECT tg.name AS tag, SUM(f.amount) AS total, COUNT(*) AS cnt
+        FROM filter_transactions(p_user_id, p_filters || '{"type": "expense"}'::jsonb) f
+        LEFT JOIN transaction_tags tt ON tt.transaction_id = f.id
+        LEFT JOIN tags tg ON tg.id = tt.tag_id
+        GROUP BY tg.name
+    ) x
+$$;
+
+-- Titles are grouped case-insensitively and shown with their most recent spelling
+CREATE OR REPLACE FUNCTION report_top_titles(p_user_id INT, p_filters JSONB, p_limit INT)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'title', x.title,
+               'total', x.total,
+               'count', x.cnt,
+               'lastDate', x.last_date
+           ) ORDER BY x.total DESC, x.title), '[]'::jsonb)
+    FROM (
+        SELECT (array_agg(f.title ORDER BY f.date DESC, f.id DESC))[1] AS title,
+               SUM(f.amount) AS total,
+               COUNT(*) AS cnt,
+               MAX(f.date) AS last_date
+        FROM filter_transactions(p_user_id, p_filters || '{"type": "expense"}'::jsonb) f
+        GROUP BY lower(trim(f.title))
+        ORDER BY total DESC
+        LIMIT p_limit
+    ) x
+$$;
+
+-- Totals and averages for the period, compared with the preceding period of the same length
+CREATE OR REPLACE FUNCTION report_summary(p_user_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_start      DATE := (p_filters->>'startDate')::DATE;
+    v_end        DATE := (p_filters->>'endDate')::DATE;
+    v_days       INT  := (p_filters->>'endDate')::DATE - (p_filters->>'startDate')::DATE + 1;
+    v_prev_start DATE;
+    v_prev_end   DATE;
+    v_total      NUMERIC;
+    v_count      INT;
+    v_prev_total NUMERIC;
+    v_prev_count INT;
+BEGIN
+    v_prev_end   := v_start - 1;
+    v_prev_start := v_start - v_days;
+
+    SELECT COALESCE(SUM(amount), 0), COUNT(*)
+    INTO v_total, v_count
+    FROM filter_transactions(p_user_id, p_filters || '{"type": "expense"}'::jsonb);
+
+    SELECT COALESCE(SUM(amount), 0), COUNT(*)
+    INTO v_prev_total, v_prev_count
+    FROM filter_transactions(p_user_id, p_filters || jsonb_build_object(
+        'type', 'expense', 'startDate', v_prev_start, 'endDate', v_prev_end));
+
+    RETURN jsonb_build_object(
+        'period', jsonb_build_object('startDate', v_start, 'endDate', v_end, 'days', v_days),
+        'total', v_total,
+        'count', v_count,
+        'averageExpense', CASE WHEN v_count = 0 THEN 0 ELSE ROUND(v_total / v_count, 2) END,
+        'averageDaily', ROUND(v_total / v_days, 2),
+        'previous', jsonb_build_object(
+            'period', jsonb_build_object('startDate', v_prev_start, 'endDate', v_prev_end, 'days', v_days),
+            'total', v_prev_total,
+            'count', v_prev_count,
+            'averageDaily', ROUND(v_prev_total / v_days, 2)
+        ),
+        'change', jsonb_build_object(
+            'amount', v_total - v_prev_total,
+            'percent', CASE WHEN v_prev_total = 0 THEN NULL
+                            ELSE ROUND((v_total - v_prev_total) * 100 / v_prev_total, 2) END
+        ),
+        'topTitles', report_top_titles(p_user_id, p_filters, 5)
+    );
+END;
+$$;
+
+-- Changes whenever anything a report reads changes; part of every report cache key
+CREATE OR REPLACE FUNCTION report_fingerprint(p_user_id INT)
+RETURNS TEXT
+LANGUAGE sql STABLE AS $$
+    SELECT concat_ws(':',
+        (SELECT COUNT(*) FROM transactions WHERE user_id = p_user_id AND deleted_at IS NULL),
+        (SELECT MAX(updated_at) FROM transactions WHERE user_id = p_user_id),
+        (SELECT MAX(updated_at) FROM tags WHERE user_id = p_user_id),
+        (SELECT COUNT(*) FROM tags WHERE user_id = p_user_id)
+    )
+$$;
@@ -0,0 +1,41 @@
+-- ============================================
+-- BANK STATEMENT IMPORT
+-- ============================================
+
+-- The bank's transaction ID, prefixed with the statement format and account
+-- (e.g. "ofx:12345678:20261005001"); re-importing a statement skips known IDs.
+-- Soft-deleted rows keep their ID so a deleted bank transaction is not re-created.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_user_external_id
+    ON transactions (user_id, external_id)
+    WHERE external_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION transaction_to_jsonb(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', t.id,
+        'type', t.type,
+        'title', t.title,
+        'amount', t.amount,
+        'category', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = t.category_id
+        ),
+        'accountId', t.account_id,
+        'transferAccountId', t.transfer_account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'externalId', t.external_id,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb),
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at
+    )
+$$;
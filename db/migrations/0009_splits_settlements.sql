@@ -0,0 +1,90 @@
+-- ============================================
+-- SPLIT EXPENSES AND SETTLEMENTS
+-- ============================================
+
+-- The expense owner paid; each share says how much of it a participant owes
+CREATE TABLE IF NOT EXISTS expense_splits (
+    id         SERIAL PRIMARY KEY,
+    expense_id INT         NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    method     VARCHAR(20) NOT NULL CHECK (method IN ('equal', 'exact', 'percentage', 'shares')),
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- weight is the participant's input: 1 (equal), the amount (exact), the percentage
+-- or the number of shares. Amounts are derived from it in expense_split_amounts.
+CREATE TABLE IF NOT EXISTS expense_split_shares (
+    split_id INT            NOT NULL REFERENCES expense_splits(id) ON DELETE CASCADE,
+    user_id  INT            NOT NULL REFERENCES users(id),
+    weight   NUMERIC(14, 4) NOT NULL CHECK (weight > 0),
+    PRIMARY KEY (split_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_split_shares_user ON expense_split_shares (user_id);
+
+-- payer_id paid payee_id back; recorded by either of them
+CREATE TABLE IF NOT EXISTS settlements (
+    id         SERIAL PRIMARY KEY,
+    payer_id   INT            NOT NULL REFERENCES users(id),
+    payee_id   INT            NOT NULL REFERENCES users(id),
+    amount     NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    date       DATE           NOT NULL,
+    notes      TEXT,
+    created_by INT            NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CHECK (payer_id <> payee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_payer ON settlements (payer_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_settlements_payee ON settlements (payee_id) WHERE deleted_at IS NULL;
+
+-- Resolves weights to cent amounts that always add up to the expense amount
+-- (largest remainder; ties go to the lower user ID). Because it is derived,
+-- editing the expense amount re-splits it, scaling exact amounts proportionally.
+-- Deleted expenses and expenses turned into another type drop out.
+CREATE OR REPLACE VIEW expense_split_amounts AS
+WITH base AS (
+    SELECT s.id AS split_id, s.expense_id, s.method, t.user_id AS payer_id, t.title, t.date,
+           sh.user_id, sh.weight,
+           ROUND(t.amount * 100)::BIGINT AS total_cents,
+           ROUND(t.amount * 100) * sh.weight / SUM(sh.weight) OVER (PARTITION BY s.id) AS exact_cents
+    FROM expense_splits s
+    JOIN transactions t ON t.id = s.expense_id AND t.type = 'expense' AND t.deleted_at IS NULL
+    JOIN expense_split_shares sh ON sh.split_id = s.id
+), ranked AS (
+    SELECT b.*,
+           FLOOR(b.exact_cents)::BIGINT AS floor_cents,
+           b.total_cents - SUM(FLOOR(b.exact_cents)) OVER (PARTITION BY b.split_id) AS leftover,
+           ROW_NUMBER() OVER (PARTITION BY b.split_id
+                              ORDER BY b.exact_cents - FLOOR(b.exact_cents) DESC, b.user_id) AS rn
+    FROM base b
+)
+SELECT split_id, expense_id, method, payer_id, title, date, user_id, weight,
+       ((floor_cents + CASE WHEN rn <= leftover THEN 1 ELSE 0 END) / 100.0)::NUMERIC(12, 2) AS amount
+FROM ranked;
+
+-- Net balance with every counterparty; positive means they owe p_user_id
+CREATE OR REPLACE FUNCTION user_balances(p_user_id INT)
+RETURNS TABLE (other_user_id INT, balance NUMERIC)
+LANGUAGE sql STABLE AS $$
+    SELECT x.other_user_id, SUM(x.amount)
+    FROM (
+        SELECT a.user_id AS other_user_id, a.amount
+        FROM expense_split_amounts a
+        WHERE a.payer_id = p_user_id AND a.user_id <> p_user_id
+        UNION ALL
+        SELECT a.payer_id, -a.amount
+        FROM expense_split_amounts a
+        WHERE a.user_id = p_user_id AND a.payer_id <> p_user_id
+        UNION ALL
+        SELECT s.payee_id, s.amount
+        FROM settlements s
+        WHERE s.payer_id = p_user_id AND s.deleted_at IS NULL
+        UNION ALL
+        SELECT s.payer_id, -s.amount
+        FROM settlements s
+        WHERE s.payee_id = p_user_id AND s.deleted_at IS NULL
+    ) x
+    GROUP BY x.other_user_id
+$$;
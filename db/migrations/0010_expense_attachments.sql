@@ -0,0 +1,112 @@
+-- ============================================
+-- EXPENSE ATTACHMENTS
+-- ============================================
+
+-- Receipts and other files of a transaction. storage says where the file lives:
+-- 'local' under ./assets, or 'cloudinary'. checksum is the SHA-256 of the content.
+CREATE TABLE IF NOT EXISTS expense_attachments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT          NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    url            TEXT         NOT NULL,
+    storage        VARCHAR(20)  NOT NULL CHECK (storage IN ('local', 'cloudinary')),
+    filename       VARCHAR(255) NOT NULL,
+    content_type   VARCHAR(100),
+    size_bytes     BIGINT,
+    checksum       CHAR(64),
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_attachments_transaction ON expense_attachments (transaction_id);
+
+-- The same file cannot be attached to a transaction twice
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_attachments_checksum
+    ON expense_attachments (transaction_id, checksum)
+    WHERE checksum IS NOT NULL;
+
+-- Existing images become attachments; their size and checksum are unknown
+INSERT INTO expense_attachments (transaction_id, url, storage, filename, created_at)
+SELECT t.id, t.image_url,
+       CASE WHEN t.image_url LIKE '%res.cloudinary.com%' THEN 'cloudinary' ELSE 'local' END,
+       LEFT(regexp_replace(t.image_url, '^.*/', ''), 255),
+       t.created_at
+FROM transactions t
+WHERE t.image_url IS NOT NULL AND t.image_url <> ''
+  AND NOT EXISTS (SELECT 1 FROM expense_attachments a WHERE a.transaction_id = t.id AND a.url = t.image_url);
+
+CREATE OR REPLACE FUNCTION transaction_to_jsonb(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', t.id,
+        'type', t.type,
+        'title', t.title,
+        'amount', t.amount,
+        'category', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = t.category_id
+        ),
+        'accountId', t.account_id,
+        'transferAccountId', t.transfer_account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'externalId', t.external_id,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb),
+        'attachments', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'id', a.id, 'url', a.url, 'storage', a.storage, 'filename', a.filename,
+                'contentType', a.content_type, 'size', a.size_bytes, 'checksum', a.checksum,
+                'createdAt', a.created_at
+            ) ORDER BY a.id)
+            FROM expense_attachments a
+            WHERE a.transaction_id = t.id
+        ), '[]'::jsonb),
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at
+    )
+$$;
+
+-- Deleting returns every attachment so the caller can remove the files
+CREATE OR REPLACE FUNCTION delete_transaction(p_user_id INT, p_transaction_id INT)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_image_url TEXT;
+    v_found     BOOLEAN;
+BEGIN
+    UPDATE transactions
+    SET deleted_at = CURRENT_TIMESTAMP
+    WHERE id = p_transaction_id AND user_id = p_user_id AND deleted_at IS NULL
+    RETURNING image_url, TRUE INTO v_image_url, v_found;
+
+    RETURN jsonb_build_object(
+        'isDeleted', COALESCE(v_found, FALSE),
+        'imageUrl', v_image_url,
+        'attachments', CASE WHEN v_found THEN COALESCE((
+            SELECT jsonb_agg(jsonb_build_object('url', a.url, 'storage', a.storage) ORDER BY a.id)
+            FROM expense_attachments a
+            WHERE a.transaction_id = p_transaction_id
+        ), '[]'::jsonb) ELSE '[]'::jsonb END
+    );
+END;
+$$;
+
+DROP FUNCTION IF EXISTS delete_expense(INT, INT);
+CREATE FUNCTION delete_expense(p_user_id INT, p_expense_id INT)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM transactions
+        WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense' AND deleted_at IS NULL
+    ) THEN
+        RETURN jsonb_build_object('isDeleted', FALSE, 'imageUrl', NULL, 'attachments', '[]'::jsonb);
+    END IF;
+    RETURN delete_transaction(p_user_id, p_expense_id);
+END;
+$$;
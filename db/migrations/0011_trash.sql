@@ -0,0 +1,51 @@
+-- ============================================
+-- TRASH: RESTORE AND PURGE
+-- ============================================
+
+CREATE INDEX IF NOT EXISTS idx_transactions_trash
+    ON transactions (user_id, deleted_at DESC)
+    WHERE deleted_at IS NOT NULL;
+
+-- Hard-deletes up to p_limit transactions soft-deleted before p_deleted_before
+-- and returns the files they referenced, which the caller removes from storage.
+-- Tags, attachments and splits cascade; recurring occurrences keep their row
+-- (so the date is not materialised again) but lose the link.
+CREATE OR REPLACE FUNCTION purge_deleted_transactions(p_deleted_before TIMESTAMP, p_limit INT)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_ids   INT[];
+    v_files JSONB;
+BEGIN
+    SELECT array_agg(x.id) INTO v_ids
+    FROM (
+        SELECT id FROM transactions
+        WHERE deleted_at IS NOT NULL AND deleted_at < p_deleted_before
+        ORDER BY deleted_at
+        LIMIT p_limit
+        FOR UPDATE SKIP LOCKED
+    ) x;
+
+    IF v_ids IS NULL THEN
+        RETURN jsonb_build_object('purged', 0, 'files', '[]'::jsonb);
+    END IF;
+
+    SELECT COALESCE(jsonb_agg(jsonb_build_object('url', f.url, 'storage', f.storage)), '[]'::jsonb)
+    INTO v_files
+    FROM (
+        SELECT a.url, a.storage
+        FROM expense_attachments a
+        WHERE a.transaction_id = ANY (v_ids)
+        UNION
+        SELECT t.image_url,
+               CASE WHEN t.image_url LIKE '%res.cloudinary.com%' THEN 'cloudinary' ELSE 'local' END
+        FROM transactions t
+        WHERE t.id = ANY (v_ids) AND t.image_url IS NOT NULL AND t.image_url <> ''
+    ) f;
+
+    UPDATE recurring_expense_occurrences SET expense_id = NULL WHERE expense_id = ANY (v_ids);
+    DELETE FROM transactions WHERE id = ANY (v_ids);
+
+    RETURN jsonb_build_object('purged', cardinality(v_ids), 'files', v_files);
+END;
+$$;
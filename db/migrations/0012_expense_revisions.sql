@@ -0,0 +1,129 @@
+-- ============================================
+-- EXPENSE CHANGE HISTORY
+-- ============================================
+
+-- One row per change of an expense. snapshot is the tracked state after the
+-- change and changes the field-level before/after diff against the previous
+-- revision. Rows are never updated; they only go when the expense is purged.
+CREATE TABLE IF NOT EXISTS expense_revisions (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT          NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    revision       INT          NOT NULL,
+    actor_id       INT          REFERENCES users(id),
+    source         VARCHAR(20)  NOT NULL
+                   CHECK (source IN ('baseline', 'api', 'batch', 'import', 'recurring', 'revert')),
+    source_ref     VARCHAR(100),
+    changes        JSONB        NOT NULL,
+    snapshot       JSONB        NOT NULL,
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, revision)
+);
+
+CREATE OR REPLACE FUNCTION expense_revisions_immutable()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'expense revisions are immutable';
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_expense_revisions_immutable ON expense_revisions;
+CREATE TRIGGER trg_expense_revisions_immutable
+    BEFORE UPDATE ON expense_revisions
+    FOR EACH ROW EXECUTE FUNCTION expense_revisions_immutable();
+
+-- The fields a revision tracks, in the shape of the expense JSON
+CREATE OR REPLACE FUNCTION expense_revision_state(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'title', t.title,
+        'amount', t.amount,
+        'categoryId', t.category_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb)
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION expense_revision_to_jsonb(r expense_revisions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'revision', r.revision,
+        'actorId', r.actor_id,
+        'source', r.source,
+        'sourceRef', r.source_ref,
+        'changes', r.changes,
+        'snapshot', r.snapshot,
+        'createdAt', r.created_at
+    )
+$$;
+
+-- Records the expense's current state as a new revision, diffed against the
+-- latest one. Returns NULL when no tracked field changed. The row lock keeps
+-- concurrent writers from numbering or diffing against the same revision.
+CREATE OR REPLACE FUNCTION record_expense_revision(
+    p_user_id    INT,
+    p_expense_id INT,
+    p_source     TEXT,
+    p_source_ref TEXT
+)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row      transactions;
+    v_state    JSONB;
+    v_prev     expense_revisions;
+    v_changes  JSONB;
+    v_revision expense_revisions;
+BEGIN
+    SELECT * INTO v_row
+    FROM transactions
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense'
+    FOR UPDATE;
+
+    IF v_row.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    v_state := expense_revision_state(v_row);
+
+    SELECT * INTO v_prev
+    FROM expense_revisions
+    WHERE transaction_id = v_row.id
+    ORDER BY revision DESC
+    LIMIT 1;
+
+    SELECT COALESCE(jsonb_object_agg(k, jsonb_build_object(
+               'before', COALESCE(v_prev.snapshot -> k, 'null'::jsonb),
+               'after', v_state -> k)), '{}'::jsonb)
+    INTO v_changes
+    FROM jsonb_object_keys(v_state) k
+    WHERE v_prev.snapshot -> k IS DISTINCT FROM v_state -> k;
+
+    IF v_changes = '{}'::jsonb THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO expense_revisions (transaction_id, revision, actor_id, source, source_ref, changes, snapshot)
+    VALUES (v_row.id, COALESCE(v_prev.revision, 0) + 1, p_user_id, p_source, p_source_ref, v_changes, v_state)
+    RETURNING * INTO v_revision;
+
+    RETURN expense_revision_to_jsonb(v_revision);
+END;
+$$;
+
+-- Existing expenses start from their current state so the first change has a
+-- "before" and can be reverted
+INSERT INTO expense_revisions (transaction_id, revision, actor_id, source, changes, snapshot, created_at)
+SELECT t.id, 1, NULL, 'baseline', '{}'::jsonb, expense_revision_state(t), t.updated_at
+FROM transactions t
+WHERE t.type = 'expense'
+  AND NOT EXISTS (SELECT 1 FROM expense_revisions r WHERE r.transaction_id = t.id);
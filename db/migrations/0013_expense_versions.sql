@@ -0,0 +1,91 @@
+-- ============================================
+-- OPTIMISTIC CONCURRENCY: TRANSACTION VERSIONS
+-- ============================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- Every write that touches updated_at is a new version. Statements of one
+-- database transaction share CURRENT_TIMESTAMP, so an update that also rewrites
+-- the tags bumps the version once.
+CREATE OR REPLACE FUNCTION bump_transaction_version()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF NEW.updated_at IS DISTINCT FROM OLD.updated_at THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_transactions_version ON transactions;
+CREATE TRIGGER trg_transactions_version
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION bump_transaction_version();
+
+-- Adds 'version'
+CREATE OR REPLACE FUNCTION transaction_to_jsonb(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', t.id,
+        'type', t.type,
+        'title', t.title,
+        'amount', t.amount,
+        'category', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = t.category_id
+        ),
+        'accountId', t.account_id,
+        'transferAccountId', t.transfer_account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'externalId', t.external_id,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb),
+        'attachments', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'id', a.id, 'url', a.url, 'storage', a.storage, 'filename', a.filename,
+                'contentType', a.content_type, 'size', a.size_bytes, 'checksum', a.checksum,
+                'createdAt', a.created_at
+            ) ORDER BY a.id)
+            FROM expense_attachments a
+            WHERE a.transaction_id = t.id
+        ), '[]'::jsonb),
+        'version', t.version,
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at
+    )
+$$;
+
+-- p_expected_version (from If-Match) must be the current version when given;
+-- otherwise nothing is deleted and versionMismatch is set
+DROP FUNCTION IF EXISTS delete_expense(INT, INT);
+CREATE FUNCTION delete_expense(p_user_id INT, p_expense_id INT, p_expected_version INT DEFAULT NULL)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_version INT;
+BEGIN
+    SELECT version INTO v_version
+    FROM transactions
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense' AND deleted_at IS NULL
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RETURN jsonb_build_object('isDeleted', FALSE, 'imageUrl', NULL, 'attachments', '[]'::jsonb);
+    END IF;
+
+    IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+        RETURN jsonb_build_object('isDeleted', FALSE, 'versionMismatch', TRUE,
+                                  'imageUrl', NULL, 'attachments', '[]'::jsonb);
+    END IF;
+
+    RETURN delete_transaction(p_user_id, p_expense_id);
+END;
+$$;
@@ -0,0 +1,30 @@
+-- ============================================
+-- EXPENSE UPDATES WITH EXPLICIT NULLS
+-- ============================================
+
+-- Like update_expense with positional arguments, but p_patch follows JSON merge
+-- patch: an absent key keeps the column, a null clears it. Keys follow
+-- UpdateExpenseRequest; the caller keeps title, amount and date non-null.
+CREATE OR REPLACE FUNCTION update_expense(p_user_id INT, p_expense_id INT, p_patch JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row transactions;
+BEGIN
+    UPDATE transactions SET
+        title       = CASE WHEN p_patch ? 'title' THEN p_patch->>'title' ELSE title END,
+        amount      = CASE WHEN p_patch ? 'amount' THEN (p_patch->>'amount')::NUMERIC ELSE amount END,
+        category_id = CASE WHEN p_patch ? 'categoryId' THEN (p_patch->>'categoryId')::INT ELSE category_id END,
+        date        = CASE WHEN p_patch ? 'date' THEN (p_patch->>'date')::DATE ELSE date END,
+        notes       = CASE WHEN p_patch ? 'notes' THEN p_patch->>'notes' ELSE notes END,
+        image_url   = CASE WHEN p_patch ? 'imageUrl' THEN p_patch->>'imageUrl' ELSE image_url END,
+        updated_at  = CURRENT_TIMESTAMP
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense' AND deleted_at IS NULL
+    RETURNING * INTO v_row;
+
+    IF v_row.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+    RETURN transaction_to_jsonb(v_row);
+END;
+$$;
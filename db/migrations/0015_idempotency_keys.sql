@@ -0,0 +1,23 @@
+-- ============================================
+-- IDEMPOTENCY KEYS
+-- ============================================
+
+-- A key is claimed before the request runs (status_code NULL) and completed with
+-- the response, which retries with the same key and fingerprint get replayed
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id            SERIAL PRIMARY KEY,
+    user_id       INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idem_key      VARCHAR(255) NOT NULL,
+    fingerprint   CHAR(64)     NOT NULL,
+    method        VARCHAR(10)  NOT NULL,
+    path          TEXT         NOT NULL,
+    status_code   INT,
+    content_type  VARCHAR(255),
+    response_body BYTEA,
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at  TIMESTAMP,
+    expires_at    TIMESTAMP    NOT NULL,
+    UNIQUE (user_id, idem_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys (expires_at);
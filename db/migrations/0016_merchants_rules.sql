@@ -0,0 +1,335 @@
+-- ============================================
+-- MERCHANTS
+-- ============================================
+
+-- Per-user merchants; names keep the casing first used but are unique
+-- regardless of case
+CREATE TABLE IF NOT EXISTS merchants (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_user_name
+    ON merchants (user_id, (lower(name)));
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS merchant_id INT REFERENCES merchants (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_merchant
+    ON transactions (merchant_id) WHERE merchant_id IS NOT NULL;
+
+-- Adds 'merchant'
+CREATE OR REPLACE FUNCTION transaction_to_jsonb(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', t.id,
+        'type', t.type,
+        'title', t.title,
+        'amount', t.amount,
+        'category', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = t.category_id
+        ),
+        'merchant', (
+            SELECT jsonb_build_object('id', m.id, 'name', m.name)
+            FROM merchants m WHERE m.id = t.merchant_id
+        ),
+        'accountId', t.account_id,
+        'transferAccountId', t.transfer_account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'externalId', t.external_id,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb),
+        'attachments', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'id', a.id, 'url', a.url, 'storage', a.storage, 'filename', a.filename,
+                'contentType', a.content_type, 'size', a.size_bytes, 'checksum', a.checksum,
+                'createdAt', a.created_at
+            ) ORDER BY a.id)
+            FROM expense_attachments a
+            WHERE a.transaction_id = t.id
+        ), '[]'::jsonb),
+        'version', t.version,
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at
+    )
+$$;
+
+-- Adds 'merchantId'
+CREATE OR REPLACE FUNCTION update_expense(p_user_id INT, p_expense_id INT, p_patch JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row transactions;
+BEGIN
+    UPDATE transactions SET
+        title       = CASE WHEN p_patch ? 'title' THEN p_patch->>'title' ELSE title END,
+        amount      = CASE WHEN p_patch ? 'amount' THEN (p_patch->>'amount')::NUMERIC ELSE amount END,
+        category_id = CASE WHEN p_patch ? 'categoryId' THEN (p_patch->>'categoryId')::INT ELSE category_id END,
+        merchant_id = CASE WHEN p_patch ? 'merchantId' THEN (p_patch->>'merchantId')::INT ELSE merchant_id END,
+        date        = CASE WHEN p_patch ? 'date' THEN (p_patch->>'date')::DATE ELSE date END,
+        notes       = CASE WHEN p_patch ? 'notes' THEN p_patch->>'notes' ELSE notes END,
+        image_url   = CASE WHEN p_patch ? 'imageUrl' THEN p_patch->>'imageUrl' ELSE image_url END,
+        updated_at  = CURRENT_TIMESTAMP
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense' AND deleted_at IS NULL
+    RETURNING * INTO v_row;
+
+    IF v_row.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+    RETURN transaction_to_jsonb(v_row);
+END;
+$$;
+
+-- ============================================
+-- MERCHANTS IN EXPENSE HISTORY
+-- ============================================
+
+-- Adds 'merchantId'
+CREATE OR REPLACE FUNCTION expense_revision_state(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'title', t.title,
+        'amount', t.amount,
+        'categoryId', t.category_id,
+        'merchantId', t.merchant_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb)
+    )
+$$;
+
+-- Snapshots taken before a field was tracked lack its key; it counts as null
+-- so adding a field does not show up as a change of every expense
+CREATE OR REPLACE FUNCTION record_expense_revision(
+    p_user_id    INT,
+    p_expense_id INT,
+    p_source     TEXT,
+    p_source_ref TEXT
+)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row      transactions;
+    v_state    JSONB;
+    v_prev     expense_revisions;
+    v_changes  JSONB;
+    v_revision expense_revisions;
+BEGIN
+    SELECT * INTO v_row
+    FROM transactions
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense'
+    FOR UPDATE;
+
+    IF v_row.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    v_state := expense_revision_state(v_row);
+
+    SELECT * INTO v_prev
+    FROM expense_revisions
+    WHERE transaction_id = v_row.id
+    ORDER BY revision DESC
+    LIMIT 1;
+
+    SELECT COALESCE(jsonb_object_agg(k, jsonb_build_object(
+               'before', COALESCE(v_prev.snapshot -> k, 'null'::jsonb),
+               'after', v_state -> k)), '{}'::jsonb)
+    INTO v_changes
+    FROM jsonb_object_keys(v_state) k
+    WHERE v_prev.id IS NULL
+       OR COALESCE(v_prev.snapshot -> k, 'null'::jsonb) IS DISTINCT FROM v_state -> k;
+
+    IF v_changes = '{}'::jsonb THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO expense_revisions (transaction_id, revision, actor_id, source, source_ref, changes, snapshot)
+    VALUES (v_row.id, COALESCE(v_prev.revision, 0) + 1, p_user_id, p_source, p_source_ref, v_changes, v_state)
+    RETURNING * INTO v_revision;
+
+    RETURN expense_revision_to_jsonb(v_revision);
+END;
+$$;
+
+-- Changes made by re-running the categorization rules
+ALTER TABLE expense_revisions DROP CONSTRAINT IF EXISTS expense_revisions_source_check;
+ALTER TABLE expense_revisions ADD CONSTRAINT expense_revisions_source_check
+    CHECK (source IN ('baseline', 'api', 'batch', 'import', 'recurring', 'revert', 'rules'));
+
+-- ============================================
+-- CATEGORIZATION RULES
+-- ============================================
+
+-- A rule matches an expense's title or merchant name (contains: case-insensitive
+-- substring, regex: case-insensitive POSIX regex) or only its amount
+-- (amount_range). min_amount and max_amount narrow any match type. A matching
+-- rule sets the category and notes and adds its tags.
+CREATE TABLE IF NOT EXISTS categorization_rules (
+    id              SERIAL PRIMARY KEY,
+    user_id         INT            NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name            VARCHAR(100)   NOT NULL,
+    priority        INT            NOT NULL DEFAULT 100,
+    match_field     VARCHAR(20)    NOT NULL DEFAULT 'title'
+                    CHECK (match_field IN ('title', 'merchant')),
+    match_type      VARCHAR(20)    NOT NULL
+                    CHECK (match_type IN ('contains', 'regex', 'amount_range')),
+    pattern         VARCHAR(255),
+    min_amount      NUMERIC(12, 2),
+    max_amount      NUMERIC(12, 2),
+    set_category_id INT            REFERENCES expense_categories (id) ON DELETE SET NULL,
+    set_tags        JSONB          NOT NULL DEFAULT '[]'::jsonb,
+    set_notes       TEXT,
+    is_active       BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (match_type = 'amount_range' OR pattern IS NOT NULL),
+    CHECK (match_type <> 'amount_range' OR min_amount IS NOT NULL OR max_amount IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categorization_rules_user
+    ON categorization_rules (user_id, priority, id) WHERE is_active;
+
+CREATE OR REPLACE FUNCTION categorization_rule_to_jsonb(r categorization_rules)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', r.id,
+        'name', r.name,
+        'priority', r.priority,
+        'matchField', r.match_field,
+        'matchType', r.match_type,
+        'pattern', r.pattern,
+        'minAmount', r.min_amount,
+        'maxAmount', r.max_amount,
+        'setCategory', (
+            SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+            FROM expense_categories c WHERE c.id = r.set_category_id
+        ),
+        'setTags', r.set_tags,
+        'setNotes', r.set_notes,
+        'isActive', r.is_active,
+        'createdAt', r.created_at,
+        'updatedAt', r.updated_at
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION categorization_rule_matches(
+    r          categorization_rules,
+    p_title    TEXT,
+    p_merchant TEXT,
+    p_amount   NUMERIC
+)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(
+        (r.min_amount IS NULL OR p_amount >= r.min_amount)
+        AND (r.max_amount IS NULL OR p_amount <= r.max_amount)
+        AND CASE r.match_type
+                WHEN 'amount_range' THEN TRUE
+                WHEN 'contains' THEN strpos(lower(f.value), lower(r.pattern)) > 0
+                WHEN 'regex' THEN f.value ~* r.pattern
+            END,
+        FALSE)
+    FROM (SELECT CASE r.match_field WHEN 'merchant' THEN p_merchant ELSE p_title END AS value) f
+$$;
+
+-- Runs the user's active rules over an expense in priority order. The first
+-- matching rule with a category (or notes) decides it; tags of every matching
+-- rule are added. Without p_overwrite only an empty category or notes is
+-- filled in. Tags are never removed.
+CREATE OR REPLACE FUNCTION apply_categorization_rules(p_user_id INT, p_expense_id INT, p_overwrite BOOLEAN)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row         transactions;
+    v_merchant    TEXT;
+    v_rule        categorization_rules;
+    v_matched     INT := 0;
+    v_category_id INT;
+    v_notes       TEXT;
+    v_tags        JSONB := '[]'::jsonb;
+    v_tagged      INT;
+    v_changed     BOOLEAN := FALSE;
+BEGIN
+    SELECT * INTO v_row
+    FROM transactions
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense' AND deleted_at IS NULL
+    FOR UPDATE;
+
+    IF v_row.id IS NULL THEN
+        RETURN jsonb_build_object('matchedRules', 0, 'changed', FALSE);
+    END IF;
+
+    SELECT name INTO v_merchant FROM merchants WHERE id = v_row.merchant_id;
+
+    FOR v_rule IN
+        SELECT * FROM categorization_rules r
+        WHERE r.user_id = p_user_id AND r.is_active
+          AND categorization_rule_matches(r, v_row.title, v_merchant, v_row.amount)
+        ORDER BY r.priority, r.id
+    LOOP
+        v_matched := v_matched + 1;
+        v_category_id := COALESCE(v_category_id, v_rule.set_category_id);
+        v_notes := COALESCE(v_notes, v_rule.set_notes);
+        v_tags := v_tags || v_rule.set_tags;
+    END LOOP;
+
+    IF v_matched = 0 THEN
+        RETURN jsonb_build_object('matchedRules', 0, 'changed', FALSE);
+    END IF;
+
+    IF v_category_id IS NULL OR (v_row.category_id IS NOT NULL AND NOT p_overwrite) THEN
+        v_category_id := v_row.category_id;
+    END IF;
+    IF v_notes IS NULL OR (COALESCE(v_row.notes, '') <> '' AND NOT p_overwrite) THEN
+        v_notes := v_row.notes;
+    END IF;
+
+    IF jsonb_array_length(v_tags) > 0 THEN
+        INSERT INTO tags (user_id, name)
+        SELECT p_user_id, x FROM jsonb_array_elements_text(v_tags) x
+        ON CONFLICT (user_id, name) DO NOTHING;
+
+        INSERT INTO transaction_tags (transaction_id, tag_id)
+        SELECT v_row.id, id FROM tags
+        WHERE user_id = p_user_id AND name IN (SELECT jsonb_array_elements_text(v_tags))
+        ON CONFLICT DO NOTHING;
+
+        GET DIAGNOSTICS v_tagged = ROW_COUNT;
+        v_changed := v_tagged > 0;
+    END IF;
+
+    IF v_category_id IS DISTINCT FROM v_row.category_id OR v_notes IS DISTINCT FROM v_row.notes THEN
+        v_changed := TRUE;
+    END IF;
+
+    IF v_changed THEN
+        UPDATE transactions SET
+            category_id = v_category_id,
+            notes       = v_notes,
+            updated_at  = CURRENT_TIMESTAMP
+        WHERE id = v_row.id;
+    END IF;
+
+    RETURN jsonb_build_object('matchedRules', v_matched, 'changed', v_changed);
+END;
+$$;
@@ -0,0 +1,365 @@
+-- ============================================
+-- PAYMENT ACCOUNTS
+-- ============================================
+
+-- Accounts created for transfers before accounts had a type become 'other'.
+-- Amounts booked on an account are in its currency.
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'other'
+        CHECK (type IN ('cash', 'debit_card', 'credit_card', 'e_wallet', 'other')),
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'PHP',
+    ADD COLUMN IF NOT EXISTS opening_balance NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account_date
+    ON transactions (account_id, date, id)
+    WHERE account_id IS NOT NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_account_date
+    ON transactions (transfer_account_id, date, id)
+    WHERE transfer_account_id IS NOT NULL AND deleted_at IS NULL;
+
+-- The effect of a transaction on an account's balance: income adds, expenses
+-- subtract, transfers move money from account_id to transfer_account_id.
+-- A credit card carries a negative balance while money is owed on it.
+CREATE OR REPLACE FUNCTION account_entry_amount(t transactions, p_account_id INT)
+RETURNS NUMERIC
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE
+        WHEN t.type = 'income' AND t.account_id = p_account_id THEN t.amount
+        WHEN t.type = 'expense' AND t.account_id = p_account_id THEN -t.amount
+        WHEN t.type = 'transfer' AND t.account_id = p_account_id THEN -t.amount
+        WHEN t.type = 'transfer' AND t.transfer_account_id = p_account_id THEN t.amount
+        ELSE 0
+    END
+$$;
+
+-- Balance of an account after every transaction dated on or before p_date
+CREATE OR REPLACE FUNCTION account_balance_at(p_account_id INT, p_date DATE)
+RETURNS NUMERIC
+LANGUAGE sql STABLE AS $$
+    SELECT a.opening_balance + COALESCE((
+        SELECT SUM(account_entry_amount(t, a.id))
+        FROM transactions t
+        WHERE (t.account_id = a.id OR t.transfer_account_id = a.id)
+          AND t.deleted_at IS NULL
+          AND (p_date IS NULL OR t.date <= p_date)
+    ), 0)
+    FROM accounts a
+    WHERE a.id = p_account_id
+$$;
+
+-- ============================================
+-- RECONCILIATION
+-- ============================================
+
+-- A reconciliation matches an account against a bank or card statement: the
+-- transactions found on the statement are marked as cleared, and the balance
+-- of all cleared transactions is compared with the statement's closing balance.
+CREATE TABLE IF NOT EXISTS account_reconciliations (
+    id                SERIAL PRIMARY KEY,
+    account_id        INT            NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    statement_date    DATE           NOT NULL,
+    statement_balance NUMERIC(12, 2) NOT NULL,
+    cleared_balance   NUMERIC(12, 2) NOT NULL,
+    created_at        TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_reconciliations_account
+    ON account_reconciliations (account_id, statement_date);
+
+-- Keyed by account as well, so each side of a transfer is cleared on its own
+CREATE TABLE IF NOT EXISTS account_reconciliation_entries (
+    reconciliation_id INT NOT NULL REFERENCES account_reconciliations (id) ON DELETE CASCADE,
+    transaction_id    INT NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    account_id        INT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_reconciliation_entries_reconciliation
+    ON account_reconciliation_entries (reconciliation_id);
+
+-- Balance of the cleared transactions of an account
+CREATE OR REPLACE FUNCTION account_cleared_balance(p_account_id INT)
+RETURNS NUMERIC
+LANGUAGE sql STABLE AS $$
+    SELECT a.opening_balance + COALESCE((
+        SELECT SUM(account_entry_amount(t, a.id))
+        FROM account_reconciliation_entries e
+        JOIN transactions t ON t.id = e.transaction_id AND t.deleted_at IS NULL
+        WHERE e.account_id = a.id
+    ), 0)
+    FROM accounts a
+    WHERE a.id = p_account_id
+$$;
+
+CREATE OR REPLACE FUNCTION account_to_jsonb(a accounts)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', a.id,
+        'name', a.name,
+        'type', a.type,
+        'currency', a.currency,
+        'openingBalance', a.opening_balance,
+        'balance', account_balance_at(a.id, NULL),
+        'clearedBalance', account_cleared_balance(a.id),
+        'lastReconciledDate', (
+            SELECT TO_CHAR(MAX(r.statement_date), 'YYYY-MM-DD')
+            FROM account_reconciliations r WHERE r.account_id = a.id
+        ),
+        'createdAt', a.created_at,
+        'updatedAt', a.updated_at
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION account_reconciliation_to_jsonb(r account_reconciliations)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', r.id,
+        'accountId', r.account_id,
+        'statementDate', TO_CHAR(r.statement_date, 'YYYY-MM-DD'),
+        'statementBalance', r.statement_balance,
+        'clearedBalance', r.cleared_balance,
+        'difference', r.statement_balance - r.cleared_balance,
+        'transactionIds', COALESCE((
+            SELECT jsonb_agg(e.transaction_id ORDER BY e.transaction_id)
+            FROM account_reconciliation_entries e
+            WHERE e.reconciliation_id = r.id
+        ), '[]'::jsonb),
+        'createdAt', r.created_at
+    )
+$$;
+
+-- Marks transactions of an account as cleared against a statement. Every ID
+-- must be a live transaction of the account, dated on or before the statement
+-- and not cleared yet; otherwise nothing is marked and the offending IDs are
+-- returned as invalidTransactionIds.
+CREATE OR REPLACE FUNCTION reconcile_account(
+    p_user_id           INT,
+    p_account_id        INT,
+    p_statement_date    DATE,
+    p_statement_balance NUMERIC,
+    p_transaction_ids   JSONB
+)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_invalid JSONB;
+    v_row     account_reconciliations;
+BEGIN
+    -- Serialise reconciliations of the account so cleared balances add up
+    PERFORM 1 FROM accounts
+    WHERE id = p_account_id AND user_id = p_user_id AND deleted_at IS NULL
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+
+    SELECT COALESCE(jsonb_agg(x.id ORDER BY x.id), '[]'::jsonb)
+    INTO v_invalid
+    FROM (SELECT DISTINCT value::INT AS id FROM jsonb_array_elements_text(p_transaction_ids)) x
+    WHERE NOT EXISTS (
+        SELECT 1 FROM transactions t
+        WHERE t.id = x.id AND t.user_id = p_user_id AND t.deleted_at IS NULL
+          AND (t.account_id = p_account_id OR t.transfer_account_id = p_account_id)
+          AND t.date <= p_statement_date
+    ) OR EXISTS (
+        SELECT 1 FROM account_reconciliation_entries e
+        WHERE e.transaction_id = x.id AND e.account_id = p_account_id
+    );
+
+    IF jsonb_array_length(v_invalid) > 0 THEN
+        RETURN jsonb_build_object('invalidTransactionIds', v_invalid);
+    END IF;
+
+    INSERT INTO account_reconciliations (account_id, statement_date, statement_balance, cleared_balance)
+    VALUES (p_account_id, p_statement_date, p_statement_balance, 0)
+    RETURNING * INTO v_row;
+
+    INSERT INTO account_reconciliation_entries (reconciliation_id, transaction_id, account_id)
+    SELECT DISTINCT v_row.id, value::INT, p_account_id
+    FROM jsonb_array_elements_text(p_transaction_ids);
+
+    UPDATE account_reconciliations
+    SET cleared_balance = account_cleared_balance(p_account_id)
+    WHERE id = v_row.id
+    RETURNING * INTO v_row;
+
+    RETURN account_reconciliation_to_jsonb(v_row);
+END;
+$$;
+
+-- ============================================
+-- LEDGER AND BALANCE HISTORY
+-- ============================================
+
+-- Transactions of an account oldest first, each with the balance after it.
+-- Filters: startDate, endDate, reconciled (true/false), limit, offset.
+-- openingBalance is the balance before startDate, closingBalance the balance
+-- after endDate (or the latest transaction).
+CREATE OR REPLACE FUNCTION get_account_ledger(p_user_id INT, p_account_id INT, p_filters JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_account accounts;
+    v_limit   INT  := COALESCE((p_filters->>'limit')::INT, 50);
+    v_offset  INT  := COALESCE((p_filters->>'offset')::INT, 0);
+    v_start   DATE := (p_filters->>'startDate')::DATE;
+    v_end     DATE := (p_filters->>'endDate')::DATE;
+    v_result  JSONB;
+BEGIN
+    SELECT * INTO v_account
+    FROM accounts
+    WHERE id = p_account_id AND user_id = p_user_id AND deleted_at IS NULL;
+
+    IF v_account.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    WITH ledger AS (
+        -- The running balance covers every transaction; filters apply afterwards
+        SELECT t.id, t.type, t.title, t.date, t.category_id,
+               account_entry_amount(t, p_account_id) AS amount,
+               v_account.opening_balance
+                   + SUM(account_entry_amount(t, p_account_id)) OVER (ORDER BY t.date, t.id) AS balance,
+               CASE WHEN t.type = 'transfer' THEN
+                   CASE WHEN t.account_id = p_account_id THEN t.transfer_account_id ELSE t.account_id END
+               END AS counterpart_id,
+               e.reconciliation_id
+        FROM transactions t
+        LEFT JOIN account_reconciliation_entries e
+               ON e.transaction_id = t.id AND e.account_id = p_account_id
+        WHERE t.user_id = p_user_id AND t.deleted_at IS NULL
+          AND (t.account_id = p_account_id OR t.transfer_account_id = p_account_id)
+    ),
+    filtered AS (
+        SELECT * FROM ledger l
+        WHERE (v_start IS NULL OR l.date >= v_start)
+          AND (v_end IS NULL OR l.date <= v_end)
+          AND (p_filters->>'reconciled' IS NULL
+               OR (l.reconciliation_id IS NOT NULL) = (p_filters->>'reconciled')::BOOLEAN)
+    ),
+    page AS (
+        SELECT * FROM filtered
+        ORDER BY date, id
+        LIMIT v_limit OFFSET v_offset
+    )
+    SELECT jsonb_build_object(
+        'account', account_to_jsonb(v_account),
+        'openingBalance', CASE WHEN v_start IS NULL THEN v_account.opening_balance
+                               ELSE account_balance_at(p_account_id, v_start - 1) END,
+        'closingBalance', account_balance_at(p_account_id, v_end),
+        'inflow', (SELECT COALESCE(SUM(amount), 0) FROM filtered WHERE amount > 0),
+        'outflow', (SELECT COALESCE(-SUM(amount), 0) FROM filtered WHERE amount < 0),
+        'entries', (
+            SELECT COALESCE(jsonb_agg(jsonb_build_object(
+                       'transactionId', p.id,
+                       'type', p.type,
+                       'title', p.title,
+                       'date', TO_CHAR(p.date, 'YYYY-MM-DD'),
+                       'amount', p.amount,
+                       'balance', p.balance,
+                       'counterpartAccountId', p.counterpart_id,
+                       'category', (
+                           SELECT jsonb_build_object('id', c.id, 'name', c.name, 'description', c.description, 'kind', c.kind)
+                           FROM expense_categories c WHERE c.id = p.category_id
+                       ),
+                       'reconciled', p.reconciliation_id IS NOT NULL,
+                       'reconciliationId', p.reconciliation_id
+                   ) ORDER BY p.date, p.id), '[]'::jsonb)
+            FROM page p
+        ),
+        'pagination', jsonb_build_object(
+            'total', (SELECT COUNT(*) FROM filtered),
+            'limit', v_limit,
+            'offset', v_offset
+        )
+    )
+    INTO v_result;
+
+    RETURN v_result;
+END;
+$$;
+
+-- Balance at the end of each day, week or month between two dates
+CREATE OR REPLACE FUNCTION get_account_balance_history(
+    p_user_id    INT,
+    p_account_id INT,
+    p_start      DATE,
+    p_end        DATE,
+    p_interval   TEXT
+)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    WITH periods AS (
+        SELECT gs::DATE AS period_start,
+               LEAST((gs + ('1 ' || p_interval)::INTERVAL - INTERVAL '1 day')::DATE, p_end) AS period_end
+        FROM generate_series(date_trunc(p_interval, p_start::TIMESTAMP), p_end::TIMESTAMP,
+                             ('1 ' || p_interval)::INTERVAL) gs
+    )
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'periodStart', TO_CHAR(GREATEST(p.period_start, p_start), 'YYYY-MM-DD'),
+               'date', TO_CHAR(p.period_end, 'YYYY-MM-DD'),
+               'balance', account_balance_at(p_account_id, p.period_end)
+           ) ORDER BY p.period_start), '[]'::jsonb)
+    FROM periods p
+    WHERE EXISTS (
+        SELECT 1 FROM accounts a
+        WHERE a.id = p_account_id AND a.user_id = p_user_id AND a.deleted_at IS NULL
+    )
+$$;
+
+-- ============================================
+-- EXPENSE ACCOUNTS
+-- ============================================
+
+-- Adds 'accountId'
+CREATE OR REPLACE FUNCTION update_expense(p_user_id INT, p_expense_id INT, p_patch JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_row transactions;
+BEGIN
+    UPDATE transactions SET
+        title       = CASE WHEN p_patch ? 'title' THEN p_patch->>'title' ELSE title END,
+        amount      = CASE WHEN p_patch ? 'amount' THEN (p_patch->>'amount')::NUMERIC ELSE amount END,
+        category_id = CASE WHEN p_patch ? 'categoryId' THEN (p_patch->>'categoryId')::INT ELSE category_id END,
+        merchant_id = CASE WHEN p_patch ? 'merchantId' THEN (p_patch->>'merchantId')::INT ELSE merchant_id END,
+        account_id  = CASE WHEN p_patch ? 'accountId' THEN (p_patch->>'accountId')::INT ELSE account_id END,
+        date        = CASE WHEN p_patch ? 'date' THEN (p_patch->>'date')::DATE ELSE date END,
+        notes       = CASE WHEN p_patch ? 'notes' THEN p_patch->>'notes' ELSE notes END,
+        image_url   = CASE WHEN p_patch ? 'imageUrl' THEN p_patch->>'imageUrl' ELSE image_url END,
+        updated_at  = CURRENT_TIMESTAMP
+    WHERE id = p_expense_id AND user_id = p_user_id AND type = 'expense' AND deleted_at IS NULL
+    RETURNING * INTO v_row;
+
+    IF v_row.id IS NULL THEN
+        RETURN NULL;
+    END IF;
+    RETURN transaction_to_jsonb(v_row);
+END;
+$$;
+
+-- Adds 'accountId'
+CREATE OR REPLACE FUNCTION expense_revision_state(t transactions)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'title', t.title,
+        'amount', t.amount,
+        'categoryId', t.category_id,
+        'merchantId', t.merchant_id,
+        'accountId', t.account_id,
+        'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+        'notes', t.notes,
+        'imageUrl', t.image_url,
+        'tags', COALESCE((
+            SELECT jsonb_agg(tg.name ORDER BY tg.name)
+            FROM transaction_tags tt
+            JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id
+        ), '[]'::jsonb)
+    )
+$$;
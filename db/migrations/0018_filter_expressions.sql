@@ -0,0 +1,60 @@
+-- ============================================
+-- FILTER EXPRESSIONS
+-- ============================================
+
+-- The field filters of filter_transactions, unchanged since 0005
+CREATE OR REPLACE FUNCTION filter_transactions_by_fields(p_user_id INT, p_filters JSONB)
+RETURNS SETOF transactions
+LANGUAGE sql STABLE AS $$
+    WITH wanted AS (
+        SELECT DISTINCT lower(trim(x)) AS name
+        FROM jsonb_array_elements_text(
+            CASE WHEN jsonb_typeof(p_filters->'tags') = 'array' THEN p_filters->'tags' ELSE '[]'::jsonb END
+        ) x
+    )
+    SELECT t.*
+    FROM transactions t
+    WHERE t.user_id = p_user_id
+      AND t.deleted_at IS NULL
+      AND (p_filters->>'type' IS NULL OR t.type = p_filters->>'type')
+      AND (p_filters->>'title' IS NULL OR t.title ILIKE '%' || (p_filters->>'title') || '%')
+      AND (p_filters->>'searchQuery' IS NULL
+           OR t.search_vector @@ to_tsquery('simple', p_filters->>'searchQuery'))
+      AND (p_filters->>'minAmount' IS NULL OR t.amount >= (p_filters->>'minAmount')::NUMERIC)
+      AND (p_filters->>'maxAmount' IS NULL OR t.amount <= (p_filters->>'maxAmount')::NUMERIC)
+      AND (p_filters->>'categoryId' IS NULL OR t.category_id = (p_filters->>'categoryId')::INT)
+      AND (p_filters->>'accountId' IS NULL
+           OR t.account_id = (p_filters->>'accountId')::INT
+           OR t.transfer_account_id = (p_filters->>'accountId')::INT)
+      AND (p_filters->>'startDate' IS NULL OR t.date >= (p_filters->>'startDate')::DATE)
+      AND (p_filters->>'endDate' IS NULL OR t.date <= (p_filters->>'endDate')::DATE)
+      AND (
+          NOT EXISTS (SELECT 1 FROM wanted)
+          OR (
+              SELECT COUNT(*)
+              FROM transaction_tags tt
+              JOIN tags tg ON tg.id = tt.tag_id
+              WHERE tt.transaction_id = t.id AND tg.name IN (SELECT name FROM wanted)
+          ) >= CASE WHEN p_filters->>'tagMatch' = 'all' THEN (SELECT COUNT(*) FROM wanted) ELSE 1 END
+      )
+$$;
+
+-- Adds the filter expression of the ?filter= parameter on top of the field filters:
+--   filterSql:    condition on t compiled by the API from the parsed expression. It
+--                 only names fixed columns; every value is read from filterParams.
+--   filterParams: array of the expression's values as text, bound as $3
+-- Callers never build filterSql from user input directly.
+CREATE OR REPLACE FUNCTION filter_transactions(p_user_id INT, p_filters JSONB)
+RETURNS SETOF transactions
+LANGUAGE plpgsql STABLE AS $$
+BEGIN
+    IF p_filters->>'filterSql' IS NULL THEN
+        RETURN QUERY SELECT * FROM filter_transactions_by_fields(p_user_id, p_filters);
+        RETURN;
+    END IF;
+
+    RETURN QUERY EXECUTE
+        'SELECT t.* FROM filter_transactions_by_fields($1, $2) t WHERE ' || (p_filters->>'filterSql')
+    USING p_user_id, p_filters, COALESCE(p_filters->'filterParams', '[]'::jsonb);
+END;
+$$;
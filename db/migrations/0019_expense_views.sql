@@ -0,0 +1,40 @@
+-- ============================================
+-- SAVED EXPENSE VIEWS
+-- ============================================
+
+-- A saved view is a named set of expense list filters and sort. filters holds
+-- the API's filter fields as sent by the client (title, q, minAmount, maxAmount,
+-- categoryId, startDate, endDate, tags, tagMatch, filter, sortBy, order); the
+-- filter expression is kept as text and compiled again each time it is used.
+CREATE TABLE IF NOT EXISTS expense_views (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(100) NOT NULL,
+    filters    JSONB        NOT NULL DEFAULT '{}'::jsonb,
+    is_pinned  BOOLEAN      NOT NULL DEFAULT FALSE,
+    is_default BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_views_user_name
+    ON expense_views (user_id, lower(name));
+
+-- At most one default view per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_views_user_default
+    ON expense_views (user_id)
+    WHERE is_default;
+
+CREATE OR REPLACE FUNCTION expense_view_to_jsonb(v expense_views)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', v.id,
+        'name', v.name,
+        'filters', v.filters,
+        'isPinned', v.is_pinned,
+        'isDefault', v.is_default,
+        'createdAt', v.created_at,
+        'updatedAt', v.updated_at
+    )
+$$;
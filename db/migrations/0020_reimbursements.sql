@@ -0,0 +1,121 @@
+-- ============================================
+-- REIMBURSEMENT REPORTS
+-- ============================================
+
+-- A report claims a group of the owner's expenses back from an approver.
+-- Status moves draft -> submitted -> approved -> paid, or submitted -> rejected;
+-- the owner can recall a submitted report and reopen a rejected one as a draft.
+CREATE TABLE IF NOT EXISTS reimbursement_reports (
+    id           SERIAL PRIMARY KEY,
+    user_id      INT          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    approver_id  INT          REFERENCES users (id) ON DELETE SET NULL,
+    title        VARCHAR(200) NOT NULL,
+    description  TEXT,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'draft'
+                 CHECK (status IN ('draft', 'submitted', 'approved', 'rejected', 'paid')),
+    submitted_at TIMESTAMP,
+    decided_at   TIMESTAMP,
+    paid_at      TIMESTAMP,
+    created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (approver_id IS NULL OR approver_id <> user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reimbursement_reports_user ON reimbursement_reports (user_id, status);
+CREATE INDEX IF NOT EXISTS idx_reimbursement_reports_approver ON reimbursement_reports (approver_id, status);
+
+-- An expense belongs to at most one report
+CREATE TABLE IF NOT EXISTS reimbursement_report_expenses (
+    report_id      INT NOT NULL REFERENCES reimbursement_reports (id) ON DELETE CASCADE,
+    transaction_id INT NOT NULL UNIQUE REFERENCES transactions (id) ON DELETE CASCADE,
+    PRIMARY KEY (report_id, transaction_id)
+);
+
+-- Every status change with the comment left on it; from_status is NULL for creation
+CREATE TABLE IF NOT EXISTS reimbursement_report_events (
+    id          SERIAL PRIMARY KEY,
+    report_id   INT         NOT NULL REFERENCES reimbursement_reports (id) ON DELETE CASCADE,
+    actor_id    INT         REFERENCES users (id) ON DELETE SET NULL,
+    from_status VARCHAR(20),
+    to_status   VARCHAR(20) NOT NULL,
+    comment     TEXT,
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reimbursement_report_events_report
+    ON reimbursement_report_events (report_id, created_at);
+
+-- An expense is locked against edits and deletion while a report claims it:
+-- from submission until it is recalled or rejected, and for good once approved
+CREATE OR REPLACE FUNCTION expense_locked(p_expense_id INT)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM reimbursement_report_expenses e
+        JOIN reimbursement_reports r ON r.id = e.report_id
+        WHERE e.transaction_id = p_expense_id
+          AND r.status IN ('submitted', 'approved', 'paid')
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION reimbursement_user_to_jsonb(p_user_id INT)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object('id', u.id, 'name', u.name, 'email', u.email)
+    FROM users u
+    WHERE u.id = p_user_id
+$$;
+
+-- Summary of a report; deleted expenses drop out of the count and total
+CREATE OR REPLACE FUNCTION reimbursement_report_to_jsonb(r reimbursement_reports)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', r.id,
+        'title', r.title,
+        'description', r.description,
+        'status', r.status,
+        'owner', reimbursement_user_to_jsonb(r.user_id),
+        'approver', reimbursement_user_to_jsonb(r.approver_id),
+        'expenseCount', s.expense_count,
+        'total', s.total,
+        'submittedAt', r.submitted_at,
+        'decidedAt', r.decided_at,
+        'paidAt', r.paid_at,
+        'createdAt', r.created_at,
+        'updatedAt', r.updated_at
+    )
+    FROM (
+        SELECT COUNT(t.id) AS expense_count, COALESCE(SUM(t.amount), 0) AS total
+        FROM reimbursement_report_expenses e
+        JOIN transactions t ON t.id = e.transaction_id AND t.deleted_at IS NULL
+        WHERE e.report_id = r.id
+    ) s
+$$;
+
+-- A report with its expenses and status history
+CREATE OR REPLACE FUNCTION reimbursement_report_detail_to_jsonb(r reimbursement_reports)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT reimbursement_report_to_jsonb(r) || jsonb_build_object(
+        'expenses', (
+            SELECT COALESCE(jsonb_agg(transaction_to_jsonb(t) ORDER BY t.date, t.id), '[]'::jsonb)
+            FROM reimbursement_report_expenses e
+            JOIN transactions t ON t.id = e.transaction_id AND t.deleted_at IS NULL
+            WHERE e.report_id = r.id
+        ),
+        'history', (
+            SELECT COALESCE(jsonb_agg(jsonb_build_object(
+                'id', ev.id,
+                'actor', reimbursement_user_to_jsonb(ev.actor_id),
+                'fromStatus', ev.from_status,
+                'toStatus', ev.to_status,
+                'comment', ev.comment,
+                'createdAt', ev.created_at
+            ) ORDER BY ev.created_at, ev.id), '[]'::jsonb)
+            FROM reimbursement_report_events ev
+            WHERE ev.report_id = r.id
+        )
+    )
+$$;
@@ -0,0 +1,99 @@
+-- ============================================
+-- DUPLICATE EXPENSE DETECTION
+-- ============================================
+
+-- Two expenses look like the same spending when they have the same amount, dates
+-- a few days apart and similar titles. Title similarity works like pg_trgm without
+-- needing the extension: titles are normalised, each word is padded and split into
+-- three-letter groups, and similarity is shared groups over all groups (0 to 1).
+
+CREATE INDEX IF NOT EXISTS idx_transactions_duplicate_lookup
+    ON transactions (user_id, amount, date)
+    WHERE type = 'expense' AND deleted_at IS NULL;
+
+-- Lower case, punctuation as spaces, single spaces
+CREATE OR REPLACE FUNCTION normalize_expense_title(p_title TEXT)
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT trim(regexp_replace(regexp_replace(lower(COALESCE(p_title, '')), '[^[:alnum:]]+', ' ', 'g'), '\s+', ' ', 'g'))
+$$;
+
+CREATE OR REPLACE FUNCTION expense_title_trigrams(p_title TEXT)
+RETURNS TEXT[]
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT COALESCE(array_agg(DISTINCT substr(p.word, i, 3)), '{}')
+    FROM regexp_split_to_table(normalize_expense_title(p_title), ' ') AS w(word)
+    CROSS JOIN LATERAL (SELECT '  ' || w.word || ' ' AS word) p
+    CROSS JOIN LATERAL generate_series(1, length(p.word) - 2) AS i
+    WHERE w.word <> ''
+$$;
+
+CREATE OR REPLACE FUNCTION expense_title_similarity(p_a TEXT, p_b TEXT)
+RETURNS REAL
+LANGUAGE sql IMMUTABLE AS $$
+    WITH a AS (SELECT unnest(expense_title_trigrams(p_a)) AS g),
+         b AS (SELECT unnest(expense_title_trigrams(p_b)) AS g)
+    SELECT CASE WHEN u.n = 0 THEN 0 ELSE i.n::REAL / u.n END
+    FROM (SELECT COUNT(*) AS n FROM (SELECT g FROM a UNION SELECT g FROM b) x) u,
+         (SELECT COUNT(*) AS n FROM (SELECT g FROM a INTERSECT SELECT g FROM b) x) i
+$$;
+
+-- The user's expenses that look like a new one, most similar first. A statement
+-- line is not compared with the expense already imported from the same bank ID.
+CREATE OR REPLACE FUNCTION find_duplicate_expenses(
+    p_user_id     INT,
+    p_amount      NUMERIC,
+    p_date        DATE,
+    p_title       TEXT,
+    p_external_id TEXT,
+    p_days        INT,
+    p_threshold   REAL
+)
+RETURNS TABLE (id INT, title TEXT, amount NUMERIC, date DATE, similarity REAL)
+LANGUAGE sql STABLE AS $$
+    SELECT x.id, x.title, x.amount, x.date, x.similarity
+    FROM (
+        SELECT t.id, t.title::TEXT AS title, t.amount, t.date, expense_title_similarity(t.title, p_title) AS similarity
+        FROM transactions t
+        WHERE t.user_id = p_user_id
+          AND t.type = 'expense'
+          AND t.deleted_at IS NULL
+          AND t.amount = p_amount
+          AND t.date BETWEEN p_date - p_days AND p_date + p_days
+          AND (p_external_id IS NULL OR t.external_id IS DISTINCT FROM p_external_id)
+    ) x
+    WHERE x.similarity >= p_threshold
+    ORDER BY x.similarity DESC, x.id
+$$;
+
+-- Every pair of the user's expenses that look like the same spending, optionally
+-- limited to expenses dated within a range
+CREATE OR REPLACE FUNCTION duplicate_expense_pairs(
+    p_user_id    INT,
+    p_start_date DATE,
+    p_end_date   DATE,
+    p_days       INT,
+    p_threshold  REAL
+)
+RETURNS TABLE (expense_id INT, duplicate_id INT, similarity REAL)
+LANGUAGE sql STABLE AS $$
+    SELECT x.expense_id, x.duplicate_id, x.similarity
+    FROM (
+        SELECT a.id AS expense_id, b.id AS duplicate_id,
+               expense_title_similarity(a.title, b.title) AS similarity
+        FROM transactions a
+        JOIN transactions b
+          ON b.user_id = a.user_id
+         AND b.id > a.id
+         AND b.type = 'expense'
+         AND b.deleted_at IS NULL
+         AND b.amount = a.amount
+         AND b.date BETWEEN a.date - p_days AND a.date + p_days
+        WHERE a.user_id = p_user_id
+          AND a.type = 'expense'
+          AND a.deleted_at IS NULL
+          AND (p_start_date IS NULL OR a.date >= p_start_date)
+          AND (p_end_date IS NULL OR a.date <= p_end_date)
+    ) x
+    WHERE x.similarity >= p_threshold
+$$;
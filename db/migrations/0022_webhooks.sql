@@ -0,0 +1,91 @@
+-- ============================================
+-- OUTGOING WEBHOOKS
+-- ============================================
+
+-- A webhook endpoint receives the user's events as signed POST requests. events
+-- lists the subscribed event names. failure_count counts failed attempts in a row;
+-- the endpoint is disabled once it reaches the limit and a success resets it.
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id              SERIAL PRIMARY KEY,
+    user_id         INT           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url             VARCHAR(2048) NOT NULL,
+    description     VARCHAR(200),
+    secret          VARCHAR(100)  NOT NULL,
+    events          JSONB         NOT NULL DEFAULT '[]'::jsonb,
+    is_active       BOOLEAN       NOT NULL DEFAULT TRUE,
+    failure_count   INT           NOT NULL DEFAULT 0,
+    disabled_at     TIMESTAMP,
+    last_success_at TIMESTAMP,
+    last_failure_at TIMESTAMP,
+    created_at      TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user
+    ON webhook_endpoints (user_id);
+
+-- The delivery queue and log: one row per event and endpoint. A pending row is
+-- sent once next_attempt_at has passed; sending pushes next_attempt_at forward
+-- first, so a worker that dies mid-send leaves the row to be retried later.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               BIGSERIAL PRIMARY KEY,
+    endpoint_id      INT         NOT NULL REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    event            VARCHAR(50) NOT NULL,
+    payload          JSONB       NOT NULL,
+    status           VARCHAR(20) NOT NULL DEFAULT 'pending'
+                     CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts         INT         NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at  TIMESTAMP,
+    last_status_code INT,
+    last_error       TEXT,
+    duration_ms      INT,
+    delivered_at     TIMESTAMP,
+    created_at       TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint
+    ON webhook_deliveries (endpoint_id, created_at DESC);
+
+-- The secret is left out; it is only returned when the endpoint is created
+CREATE OR REPLACE FUNCTION webhook_endpoint_to_jsonb(w webhook_endpoints)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', w.id,
+        'url', w.url,
+        'description', w.description,
+        'events', w.events,
+        'isActive', w.is_active,
+        'failureCount', w.failure_count,
+        'disabledAt', w.disabled_at,
+        'lastSuccessAt', w.last_success_at,
+        'lastFailureAt', w.last_failure_at,
+        'createdAt', w.created_at,
+        'updatedAt', w.updated_at
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION webhook_delivery_to_jsonb(d webhook_deliveries)
+RETURNS JSONB
+LANGUAGE sql STABLE AS $$
+    SELECT jsonb_build_object(
+        'id', d.id,
+        'endpointId', d.endpoint_id,
+        'event', d.event,
+        'payload', d.payload,
+        'status', d.status,
+        'attempts', d.attempts,
+        'nextAttemptAt', CASE WHEN d.status = 'pending' THEN d.next_attempt_at END,
+        'lastAttemptAt', d.last_attempt_at,
+        'lastStatusCode', d.last_status_code,
+        'lastError', d.last_error,
+        'durationMs', d.duration_ms,
+        'deliveredAt', d.delivered_at,
+        'createdAt', d.created_at
+    )
+$$;
@@ -0,0 +1,194 @@
+-- ============================================
+-- DELTA SYNC
+-- ============================================
+
+-- Offline clients name the expenses they create with their own UUIDs, so a
+-- retried push finds the expense it already created
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS client_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_user_client_id
+    ON transactions (user_id, client_id)
+    WHERE client_id IS NOT NULL;
+
+-- Pushed changes are recorded in the expense history as 'sync'
+ALTER TABLE expense_revisions DROP CONSTRAINT IF EXISTS expense_revisions_source_check;
+ALTER TABLE expense_revisions ADD CONSTRAINT expense_revisions_source_check
+    CHECK (source IN ('baseline', 'api', 'batch', 'import', 'recurring', 'revert', 'rules', 'sync'));
+
+-- ============================================
+-- CHANGE TRACKING
+-- ============================================
+
+-- The latest change of every synced record: expenses, tags and categories.
+-- Each change takes the next seq, so a client that has seen everything up to
+-- a seq asks for the rows above it. Deleted records stay as tombstones.
+-- Categories are shared by all users and have no user_id.
+CREATE SEQUENCE IF NOT EXISTS sync_change_seq;
+
+CREATE TABLE IF NOT EXISTS sync_changes (
+    entity     VARCHAR(20) NOT NULL CHECK (entity IN ('expense', 'tag', 'category')),
+    entity_id  INT         NOT NULL,
+    user_id    INT         REFERENCES users (id) ON DELETE CASCADE,
+    client_id  UUID,
+    operation  VARCHAR(10) NOT NULL CHECK (operation IN ('upsert', 'delete')),
+    seq        BIGINT      NOT NULL,
+    changed_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_changes_user_seq
+    ON sync_changes (user_id, seq);
+
+-- A seq is taken when a row is written but becomes visible at commit, so a
+-- reader could see seq 11 while seq 10 is still uncommitted and skip it for
+-- good. Writers therefore hold a shared lock on the user's sync key until they
+-- commit, and readers take it exclusively (see sync_changes_lock), which waits
+-- for those writers to finish.
+CREATE OR REPLACE FUNCTION record_sync_change(
+    p_entity    TEXT,
+    p_entity_id INT,
+    p_user_id   INT,
+    p_client_id UUID,
+    p_operation TEXT
+)
+RETURNS VOID
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock_shared(hashtext('sync_changes'), COALESCE(p_user_id, 0));
+
+    INSERT INTO sync_changes (entity, entity_id, user_id, client_id, operation, seq, changed_at)
+    VALUES (p_entity, p_entity_id, p_user_id, p_client_id, p_operation, nextval('sync_change_seq'), CURRENT_TIMESTAMP)
+    ON CONFLICT (entity, entity_id) DO UPDATE SET
+        user_id    = EXCLUDED.user_id,
+        client_id  = EXCLUDED.client_id,
+        operation  = EXCLUDED.operation,
+        seq        = EXCLUDED.seq,
+        changed_at = EXCLUDED.changed_at;
+END;
+$$;
+
+-- Taken by a reader before it reads the user's changes, together with the
+-- shared key of the categories (0)
+CREATE OR REPLACE FUNCTION sync_changes_lock(p_user_id INT)
+RETURNS VOID
+LANGUAGE sql AS $$
+    SELECT pg_advisory_xact_lock(hashtext('sync_changes'), 0),
+           pg_advisory_xact_lock(hashtext('sync_changes'), p_user_id)
+$$;
+
+-- An expense is upserted while it is a live expense and becomes a tombstone
+-- when it is trashed, purged or turned into another type of transaction
+CREATE OR REPLACE FUNCTION track_transaction_sync()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.type = 'expense' THEN
+            PERFORM record_sync_change('expense', OLD.id, OLD.user_id, OLD.client_id, 'delete');
+        END IF;
+        RETURN OLD;
+    END IF;
+
+    IF NEW.type = 'expense' AND NEW.deleted_at IS NULL THEN
+        PERFORM record_sync_change('expense', NEW.id, NEW.user_id, NEW.client_id, 'upsert');
+    ELSIF TG_OP = 'UPDATE' AND OLD.type = 'expense' AND OLD.deleted_at IS NULL THEN
+        PERFORM record_sync_change('expense', NEW.id, NEW.user_id, NEW.client_id, 'delete');
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_transactions_sync ON transactions;
+CREATE TRIGGER trg_transactions_sync
+    AFTER INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION track_transaction_sync();
+
+-- Expenses carry their tag names, so linking, unlinking and renaming tags
+-- changes the expenses too. Links removed with a purged expense need nothing.
+CREATE OR REPLACE FUNCTION track_transaction_tag_sync()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_transaction_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        v_transaction_id := OLD.transaction_id;
+    ELSE
+        v_transaction_id := NEW.transaction_id;
+    END IF;
+
+    PERFORM record_sync_change('expense', t.id, t.user_id, t.client_id, 'upsert')
+    FROM transactions t
+    WHERE t.id = v_transaction_id AND t.type = 'expense' AND t.deleted_at IS NULL;
+
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_transaction_tags_sync ON transaction_tags;
+CREATE TRIGGER trg_transaction_tags_sync
+    AFTER INSERT OR DELETE ON transaction_tags
+    FOR EACH ROW EXECUTE FUNCTION track_transaction_tag_sync();
+
+CREATE OR REPLACE FUNCTION track_tag_sync()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM record_sync_change('tag', OLD.id, OLD.user_id, NULL, 'delete');
+        RETURN OLD;
+    END IF;
+
+    PERFORM record_sync_change('tag', NEW.id, NEW.user_id, NULL, 'upsert');
+
+    IF TG_OP = 'UPDATE' AND NEW.name IS DISTINCT FROM OLD.name THEN
+        PERFORM record_sync_change('expense', t.id, t.user_id, t.client_id, 'upsert')
+        FROM transaction_tags tt
+        JOIN transactions t ON t.id = tt.transaction_id
+        WHERE tt.tag_id = NEW.id AND t.type = 'expense' AND t.deleted_at IS NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_tags_sync ON tags;
+CREATE TRIGGER trg_tags_sync
+    AFTER INSERT OR UPDATE OR DELETE ON tags
+    FOR EACH ROW EXECUTE FUNCTION track_tag_sync();
+
+CREATE OR REPLACE FUNCTION track_category_sync()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM record_sync_change('category', OLD.id, NULL, NULL, 'delete');
+        RETURN OLD;
+    END IF;
+
+    PERFORM record_sync_change('category', NEW.id, NULL, NULL, 'upsert');
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_expense_categories_sync ON expense_categories;
+CREATE TRIGGER trg_expense_categories_sync
+    AFTER INSERT OR UPDATE OR DELETE ON expense_categories
+    FOR EACH ROW EXECUTE FUNCTION track_category_sync();
+
+-- Records written before tracking started count as changed now, so a first
+-- sync (no token) returns everything
+INSERT INTO sync_changes (entity, entity_id, user_id, operation, seq)
+SELECT 'category', c.id, NULL, 'upsert', nextval('sync_change_seq')
+FROM expense_categories c
+ON CONFLICT (entity, entity_id) DO NOTHING;
+
+INSERT INTO sync_changes (entity, entity_id, user_id, operation, seq)
+SELECT 'tag', tg.id, tg.user_id, 'upsert', nextval('sync_change_seq')
+FROM tags tg
+ON CONFLICT (entity, entity_id) DO NOTHING;
+
+INSERT INTO sync_changes (entity, entity_id, user_id, client_id, operation, seq)
+SELECT 'expense', t.id, t.user_id, t.client_id, 'upsert', nextval('sync_change_seq')
+FROM transactions t
+WHERE t.type = 'expense' AND t.deleted_at IS NULL
+ON CONFLICT (entity, entity_id) DO NOTHING;
@@ -0,0 +1,65 @@
+-- ============================================
+-- EXPENSE LOCKS ENFORCED BY THE DATABASE
+-- ============================================
+
+-- The API checks expense_locked before writing, but a report can be submitted
+-- between that check and the write. These triggers close the gap: a locked
+-- expense keeps the fields a user edits, stays out of the trash and keeps its
+-- attachments. Other columns may still change, so deleting a merchant can clear
+-- merchant_id (ON DELETE SET NULL) even on a reimbursed expense.
+-- Violations raise SQLSTATE EXLCK. Deleting a merchant and reverting an expense
+-- turn it into a 409; other writes answer 409 from their expense_locked check.
+CREATE OR REPLACE FUNCTION enforce_transaction_lock()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF (NEW.title, NEW.amount, NEW.category_id, NEW.date, NEW.notes,
+        NEW.account_id, NEW.image_url, NEW.deleted_at)
+           IS DISTINCT FROM
+       (OLD.title, OLD.amount, OLD.category_id, OLD.date, OLD.notes,
+        OLD.account_id, OLD.image_url, OLD.deleted_at)
+       OR (NEW.merchant_id IS NOT NULL AND NEW.merchant_id IS DISTINCT FROM OLD.merchant_id) THEN
+        IF expense_locked(OLD.id) THEN
+            RAISE EXCEPTION 'expense % is locked by a submitted reimbursement report', OLD.id
+                USING ERRCODE = 'EXLCK';
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_transactions_lock ON transactions;
+CREATE TRIGGER trg_transactions_lock
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION enforce_transaction_lock();
+
+CREATE OR REPLACE FUNCTION enforce_attachment_lock()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_transaction_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        v_transaction_id := OLD.transaction_id;
+    ELSE
+        v_transaction_id := NEW.transaction_id;
+    END IF;
+
+    -- Purging a trashed expense removes its attachments with it
+    IF EXISTS (SELECT 1 FROM transactions WHERE id = v_transaction_id AND deleted_at IS NULL)
+       AND expense_locked(v_transaction_id) THEN
+        RAISE EXCEPTION 'expense % is locked by a submitted reimbursement report', v_transaction_id
+            USING ERRCODE = 'EXLCK';
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_expense_attachments_lock ON expense_attachments;
+CREATE TRIGGER trg_expense_attachments_lock
+    BEFORE INSERT OR DELETE ON expense_attachments
+    FOR EACH ROW EXECUTE FUNCTION enforce_attachment_lock();
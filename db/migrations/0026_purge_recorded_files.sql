@@ -0,0 +1,48 @@
+-- ============================================
+-- TRASH PURGE: ONLY FILES THE SERVER RECORDED
+-- ============================================
+
+CREATE INDEX IF NOT EXISTS idx_expense_attachments_url ON expense_attachments (url);
+
+-- As in 0011, but image_url is client-writable, so it no longer names files to
+-- delete: only expense_attachments rows, which the server writes for every file
+-- it stores (uploaded images included; 0010 recorded the older ones). A file is
+-- kept while any attachment outside the batch, of any user, still points at it.
+CREATE OR REPLACE FUNCTION purge_deleted_transactions(p_deleted_before TIMESTAMP, p_limit INT)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_ids   INT[];
+    v_files JSONB;
+BEGIN
+    SELECT array_agg(x.id) INTO v_ids
+    FROM (
+        SELECT id FROM transactions
+        WHERE deleted_at IS NOT NULL AND deleted_at < p_deleted_before
+        ORDER BY deleted_at
+        LIMIT p_limit
+        FOR UPDATE SKIP LOCKED
+    ) x;
+
+    IF v_ids IS NULL THEN
+        RETURN jsonb_build_object('purged', 0, 'files', '[]'::jsonb);
+    END IF;
+
+    SELECT COALESCE(jsonb_agg(jsonb_build_object('url', f.url, 'storage', f.storage)), '[]'::jsonb)
+    INTO v_files
+    FROM (
+        SELECT DISTINCT a.url, a.storage
+        FROM expense_attachments a
+        WHERE a.transaction_id = ANY (v_ids)
+          AND NOT EXISTS (
+              SELECT 1 FROM expense_attachments o
+              WHERE o.url = a.url AND NOT (o.transaction_id = ANY (v_ids))
+          )
+    ) f;
+
+    UPDATE recurring_expense_occurrences SET expense_id = NULL WHERE expense_id = ANY (v_ids);
+    DELETE FROM transactions WHERE id = ANY (v_ids);
+
+    RETURN jsonb_build_object('purged', cardinality(v_ids), 'files', v_files);
+END;
+$$;
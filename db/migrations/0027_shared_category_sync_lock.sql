@@ -0,0 +1,42 @@
+-- ============================================
+-- DELTA SYNC: PULLS SHARE THE CATEGORY LOCK
+-- ============================================
+
+-- Every pull took the categories' sync key (0) exclusively, so pulls of all
+-- users queued behind each other. The modes on that key are now swapped:
+-- category writers, which are rare, hold it exclusively until they commit and
+-- pulls take it shared, which still waits for those writers but not for other
+-- pulls. A user's own key keeps shared writers and an exclusive reader.
+CREATE OR REPLACE FUNCTION record_sync_change(
+    p_entity    TEXT,
+    p_entity_id INT,
+    p_user_id   INT,
+    p_client_id UUID,
+    p_operation TEXT
+)
+RETURNS VOID
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF p_user_id IS NULL THEN
+        PERFORM pg_advisory_xact_lock(hashtext('sync_changes'), 0);
+    ELSE
+        PERFORM pg_advisory_xact_lock_shared(hashtext('sync_changes'), p_user_id);
+    END IF;
+
+    INSERT INTO sync_changes (entity, entity_id, user_id, client_id, operation, seq, changed_at)
+    VALUES (p_entity, p_entity_id, p_user_id, p_client_id, p_operation, nextval('sync_change_seq'), CURRENT_TIMESTAMP)
+    ON CONFLICT (entity, entity_id) DO UPDATE SET
+        user_id    = EXCLUDED.user_id,
+        client_id  = EXCLUDED.client_id,
+        operation  = EXCLUDED.operation,
+        seq        = EXCLUDED.seq,
+        changed_at = EXCLUDED.changed_at;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION sync_changes_lock(p_user_id INT)
+RETURNS VOID
+LANGUAGE sql AS $$
+    SELECT pg_advisory_xact_lock_shared(hashtext('sync_changes'), 0),
+           pg_advisory_xact_lock(hashtext('sync_changes'), p_user_id)
+$$;
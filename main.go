@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
 	"go_template_v3/routers"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/FDSAP-Git-Org/hephaestus/apilogs"
 	utils_v1 "github.com/FDSAP-Git-Org/hephaestus/utils/v1"
@@ -37,6 +40,13 @@ func init() {
 
 	// Connect to DB
 	config.PostgreSQLConnect()
+
+	// Redis is optional; caches fall back to process memory without it
+	if address := utils_v1.GetEnv("REDIS_ADDRESS"); address != "" {
+		if !config.RedisConnect(address, utils_v1.GetEnv("REDIS_PASSWORD")) {
+			config.RedisClient = nil
+		}
+	}
 }
 
 func main() {
@@ -50,9 +60,10 @@ func main() {
 
 	// CORS configuration
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{"GET,POST,PUT,DELETE"},
-		AllowHeaders: []string{"Origin, Content-Type, Accept, Authorization"},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{"GET,POST,PUT,PATCH,DELETE"},
+		AllowHeaders:  []string{"Origin, Content-Type, Accept, Authorization, If-Match, Idempotency-Key"},
+		ExposeHeaders: []string{"ETag, Idempotent-Replayed"},
 	}))
 
 	app.Use(logger.New())
@@ -61,6 +72,13 @@ func main() {
 	// Initialize API Endpoints
 	routers.APIRoute(app)
 
+	// Background schedulers
+	go scpFeatureOne.StartRecurringExpenseScheduler(utils.GetEnvDuration("RECURRING_EXPENSE_SCHEDULER_INTERVAL", time.Hour))
+	go scpFeatureOne.StartTrashPurgeScheduler(utils.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour))
+	go scpFeatureOne.StartWebhookDeliveryScheduler(utils.GetEnvDuration("WEBHOOK_DELIVERY_INTERVAL", 30*time.Second))
+	go utils.StartIdempotencyKeyCleanup(utils.GetEnvDuration("IDEMPOTENCY_KEY_CLEANUP_INTERVAL", time.Hour))
+	go utils.StartUserEventRelay()
+
 	// TLS Configuration
 	if strings.ToUpper(utils_v1.GetEnv("SSL_MODE")) == "ENABLED" {
 		fmt.Println("SSL_MODE: ENABLED")
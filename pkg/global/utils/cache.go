@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"go_template_v3/pkg/config"
+	"log"
+	"sync"
+	"time"
+)
+
+// Values are cached in Redis when it is connected, otherwise in process memory
+
+type memoryCacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+var (
+	memoryCache   = map[string]memoryCacheEntry{}
+	memoryCacheMu sync.Mutex
+)
+
+// CacheGet returns a cached value and whether it was found
+func CacheGet(key string) (string, bool) {
+	if config.RedisClient != nil {
+		value, err := config.RedisClient.Get(context.Background(), key).Result()
+		if err != nil {
+			return "", false
+		}
+		return value, true
+	}
+
+	memoryCacheMu.Lock()
+	defer memoryCacheMu.Unlock()
+
+	entry, ok := memoryCache[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(memoryCache, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// CacheTake returns a cached value and removes it in one step, so concurrent
+// callers cannot both get it
+func CacheTake(key string) (string, bool) {
+	if config.RedisClient != nil {
+		value, err := config.RedisClient.GetDel(context.Background(), key).Result()
+		if err != nil {
+			return "", false
+		}
+		return value, true
+	}
+
+	memoryCacheMu.Lock()
+	defer memoryCacheMu.Unlock()
+
+	entry, ok := memoryCache[key]
+	if !ok {
+		return "", false
+	}
+	delete(memoryCache, key)
+	if time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.value, true
+}
+
+// CacheSet stores a value for ttl; failures are only logged since the cache is best-effort
+func CacheSet(key, value string, ttl time.Duration) {
+	if config.RedisClient != nil {
+		if err := config.RedisClient.Set(context.Background(), key, value, ttl).Err(); err != nil {
+			log.Printf("[CacheSet] Error storing %s: %v", key, err)
+		}
+		return
+	}
+
+	memoryCacheMu.Lock()
+	defer memoryCacheMu.Unlock()
+
+	now := time.Now()
+	for k, entry := range memoryCache {
+		if now.After(entry.expiresAt) {
+			delete(memoryCache, k)
+		}
+	}
+	memoryCache[key] = memoryCacheEntry{value: value, expiresAt: now.Add(ttl)}
+}
+
+// CacheDelete removes a cached value
+func CacheDelete(key string) {
+	if config.RedisClient != nil {
+		if err := config.RedisClient.Del(context.Background(), key).Err(); err != nil {
+			log.Printf("[CacheDelete] Error deleting %s: %v", key, err)
+		}
+		return
+	}
+
+	memoryCacheMu.Lock()
+	defer memoryCacheMu.Unlock()
+
+	delete(memoryCache, key)
+}
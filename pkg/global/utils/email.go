@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"net/smtp"
+
+	utils_v1 "github.com/FDSAP-Git-Org/hephaestus/utils/v1"
+)
+
+// SendEmail sends an HTML email through the SMTP server configured in the environment
+func SendEmail(to, subject, htmlContent string) error {
+	smtpHost := utils_v1.GetEnv("SMTP_HOST")
+	smtpPort := utils_v1.GetEnv("SMTP_PORT")
+	smtpUser := utils_v1.GetEnv("SMTP_USER")
+	smtpPass := utils_v1.GetEnv("SMTP_PASS")
+	from := utils_v1.GetEnv("EMAIL_FROM")
+
+	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
+
+	msg := []byte("To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/html; charset=UTF-8\r\n" +
+		"\r\n" + htmlContent)
+
+	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, from, []string{to}, msg)
+	return err
+}
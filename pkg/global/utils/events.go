@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go_template_v3/pkg/config"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Live events are pushed to every stream a user has open. With Redis connected
+// they are published on one channel that every instance relays to its own
+// streams; without it they only reach streams on this instance.
+
+const (
+	userEventChannel = "user_events"
+
+	// MaxUserEventStreams caps the streams one user can hold open per instance
+	MaxUserEventStreams = 10
+	userEventBuffer     = 64
+
+	// EventStreamTicketTTL is how long a stream ticket can be used
+	EventStreamTicketTTL = time.Minute
+	eventStreamTicketKey = "event_stream_ticket:"
+)
+
+// UserEvent is one live event for a user's connected clients
+type UserEvent struct {
+	UserID    int             `json:"userId"`
+	Event     string          `json:"event"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+var (
+	userEventStreams   = map[int]map[chan UserEvent]struct{}{}
+	userEventStreamsMu sync.Mutex
+)
+
+// SubscribeUserEvents opens a stream of the user's events. The returned function
+// closes it and must be called once the client is gone.
+func SubscribeUserEvents(userID int) (<-chan UserEvent, func(), error) {
+	userEventStreamsMu.Lock()
+	defer userEventStreamsMu.Unlock()
+
+	if len(userEventStreams[userID]) >= MaxUserEventStreams {
+		return nil, nil, fmt.Errorf("at most %d event streams can be open at once", MaxUserEventStreams)
+	}
+
+	stream := make(chan UserEvent, userEventBuffer)
+	if userEventStreams[userID] == nil {
+		userEventStreams[userID] = map[chan UserEvent]struct{}{}
+	}
+	userEventStreams[userID][stream] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			userEventStreamsMu.Lock()
+			defer userEventStreamsMu.Unlock()
+
+			delete(userEventStreams[userID], stream)
+			if len(userEventStreams[userID]) == 0 {
+				delete(userEventStreams, userID)
+			}
+		})
+	}
+	return stream, unsubscribe, nil
+}
+
+// PublishUserEvent pushes an event to every stream the user has open on any
+// instance. Live events are best-effort, so failures are only logged.
+func PublishUserEvent(userID int, event string, data interface{}) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[PublishUserEvent] Error marshaling %s for user %d: %v", event, userID, err)
+		return
+	}
+
+	message := UserEvent{
+		UserID:    userID,
+		Event:     event,
+		Data:      dataJSON,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	if config.RedisClient != nil {
+		payload, err := json.Marshal(message)
+		if err == nil {
+			err = config.RedisClient.Publish(context.Background(), userEventChannel, payload).Err()
+		}
+		if err == nil {
+			return
+		}
+		// Reach this instance's streams at least
+		log.Printf("[PublishUserEvent] Error publishing %s for user %d: %v", event, userID, err)
+	}
+
+	dispatchUserEvent(message)
+}
+
+// StartUserEventRelay passes events published by any instance on to the streams
+// open on this one. It does nothing without Redis.
+func StartUserEventRelay() {
+	if config.RedisClient == nil {
+		return
+	}
+
+	// The subscription reconnects by itself if Redis goes away
+	pubsub := config.RedisClient.Subscribe(context.Background(), userEventChannel)
+	defer pubsub.Close()
+	log.Printf("[UserEventRelay] Started - Channel: %s", userEventChannel)
+
+	for msg := range pubsub.Channel() {
+		var message UserEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+			log.Printf("[UserEventRelay] Invalid message: %v", err)
+			continue
+		}
+		dispatchUserEvent(message)
+	}
+}
+
+// dispatchUserEvent hands an event to the user's streams on this instance. A
+// stream that has fallen a full buffer behind misses the event rather than
+// holding up the others.
+func dispatchUserEvent(message UserEvent) {
+	userEventStreamsMu.Lock()
+	defer userEventStreamsMu.Unlock()
+
+	for stream := range userEventStreams[message.UserID] {
+		select {
+		case stream <- message:
+		default:
+			log.Printf("[dispatchUserEvent] Stream full, dropped %s for user %d", message.Event, message.UserID)
+		}
+	}
+}
+
+// IssueEventStreamTicket returns a single-use ticket that opens the user's event
+// stream, for clients such as browser EventSource that cannot send a bearer token
+func IssueEventStreamTicket(userID int) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	ticket := hex.EncodeToString(buf)
+
+	CacheSet(eventStreamTicketKey+ticket, strconv.Itoa(userID), EventStreamTicketTTL)
+	return ticket, nil
+}
+
+// RedeemEventStreamTicket returns the user a ticket was issued to and uses it
+// up, or 0 when the ticket is unknown or expired
+func RedeemEventStreamTicket(ticket string) int {
+	value, ok := CacheTake(eventStreamTicketKey + ticket)
+	if !ok {
+		return 0
+	}
+
+	userID, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return userID
+}
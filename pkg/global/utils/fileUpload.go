@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	utils_v1 "github.com/FDSAP-Git-Org/hephaestus/utils/v1"
@@ -29,6 +30,15 @@ func DefaultFileUploadConfig() FileUploadConfig {
 	}
 }
 
+// AttachmentUploadConfig returns the configuration for expense attachments (images and PDFs)
+func AttachmentUploadConfig() FileUploadConfig {
+	return FileUploadConfig{
+		MaxSize:      10 * 1024 * 1024, // 10MB
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"},
+		UploadPath:   "./assets/uploads/attachments",
+	}
+}
+
 // UploadFile handles file upload and returns the file path/URL
 func UploadFile(c fiber.Ctx, fileHeader *multipart.FileHeader, config FileUploadConfig) (string, error) {
 	// Validate file size
@@ -90,8 +100,8 @@ func UploadFile(c fiber.Ctx, fileHeader *multipart.FileHeader, config FileUpload
 		return "", err
 	}
 
-	// Return relative path or full URL
-	return fmt.Sprintf("/assets/images/uploads/expenses/%s", filename), nil
+	// Return the path it is served under, e.g. /assets/images/uploads/expenses/<filename>
+	return "/" + filepath.ToSlash(filepath.Join(strings.TrimPrefix(config.UploadPath, "./"), filename)), nil
 }
 
 // DeleteUploadedFile deletes an uploaded file from the filesystem
@@ -117,6 +127,33 @@ func DeleteUploadedFile(filePath string) error {
 	return nil
 }
 
+// DeleteAssetFile deletes an uploaded file given the URL or path it is served
+// under. Only files in the upload directories below ./assets are removed.
+func DeleteAssetFile(fileURL string) error {
+	idx := strings.Index(fileURL, "/assets/")
+	if idx < 0 {
+		return nil
+	}
+
+	rel := filepath.Clean(filepath.FromSlash(fileURL[idx+len("/assets/"):]))
+	if !strings.HasPrefix(rel, "uploads"+string(filepath.Separator)) &&
+		!strings.HasPrefix(rel, filepath.Join("images", "uploads")+string(filepath.Separator)) {
+		return fmt.Errorf("refusing to delete %s outside the upload directories", fileURL)
+	}
+	fullPath := filepath.Join("./assets", rel)
+
+	// Check if file exists before attempting to delete
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.Remove(fullPath); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", rel, err)
+	}
+
+	return nil
+}
+
 // ExtractFilenameFromURL extracts the filename from a URL
 func ExtractFilenameFromURL(url string) string {
 	if url == "" {
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"go_template_v3/pkg/config"
+	"log"
+	"time"
+)
+
+// IdempotencyRecord is a stored Idempotency-Key; StatusCode is nil until the
+// request that claimed it has finished
+type IdempotencyRecord struct {
+	Fingerprint  string
+	StatusCode   *int
+	ContentType  *string
+	ResponseBody []byte
+}
+
+// IdempotencyKeyTTL is how long a key is remembered (IDEMPOTENCY_KEY_TTL, default 24h)
+func IdempotencyKeyTTL() time.Duration {
+	return GetEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+}
+
+// IdempotencyProcessingLease is how long a claimed key waits for its request to
+// finish (IDEMPOTENCY_PROCESSING_LEASE, default 1m). A claim left unfinished
+// longer, such as by a process that was killed, can be taken by a retry.
+func IdempotencyProcessingLease() time.Duration {
+	return GetEnvDuration("IDEMPOTENCY_PROCESSING_LEASE", time.Minute)
+}
+
+// ClaimIdempotencyKey reserves a key for the user's request and returns the ID of
+// the claim, or 0 when the key is already taken. An expired key, or a claim whose
+// request has not finished within the processing lease, is free again.
+func ClaimIdempotencyKey(userID int, key, fingerprint, method, path string) (int, error) {
+	db := config.DBConnList[0]
+
+	err := db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE user_id = ? AND idem_key = ?
+		  AND (expires_at <= CURRENT_TIMESTAMP
+		       OR (status_code IS NULL AND created_at <= CURRENT_TIMESTAMP - make_interval(secs => ?)))
+	`, userID, key, IdempotencyProcessingLease().Seconds()).Error
+	if err != nil {
+		log.Printf("[ClaimIdempotencyKey] Error expiring key for user %d: %v", userID, err)
+		return 0, err
+	}
+
+	var id int
+	result := db.Raw(`
+		INSERT INTO idempotency_keys (user_id, idem_key, fingerprint, method, path, expires_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP + make_interval(secs => ?))
+		ON CONFLICT (user_id, idem_key) DO NOTHING
+		RETURNING id
+	`, userID, key, fingerprint, method, path, IdempotencyKeyTTL().Seconds()).Scan(&id)
+	if result.Error != nil {
+		log.Printf("[ClaimIdempotencyKey] Error for user %d: %v", userID, result.Error)
+		return 0, result.Error
+	}
+
+	return id, nil
+}
+
+// GetIdempotencyKey retrieves a live key of the user, or nil when there is none
+func GetIdempotencyKey(userID int, key string) (*IdempotencyRecord, error) {
+	var record IdempotencyRecord
+
+	result := config.DBConnList[0].Raw(`
+		SELECT fingerprint, status_code, content_type, response_body
+		FROM idempotency_keys
+		WHERE user_id = ? AND idem_key = ? AND expires_at > CURRENT_TIMESTAMP
+	`, userID, key).Scan(&record)
+	if result.Error != nil {
+		log.Printf("[GetIdempotencyKey] Error for user %d: %v", userID, result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that made the claim.
+// A claim taken over by a retry after its lease ran out is left to that retry.
+func CompleteIdempotencyKey(claimID, statusCode int, contentType string, body []byte) error {
+	err := config.DBConnList[0].Exec(`
+		UPDATE idempotency_keys
+		SET status_code = ?, content_type = ?, response_body = ?, completed_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status_code IS NULL
+	`, statusCode, contentType, body, claimID).Error
+
+	if err != nil {
+		log.Printf("[CompleteIdempotencyKey] Error for claim %d: %v", claimID, err)
+		return err
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey forgets a claim whose request failed, so a retry runs again
+func ReleaseIdempotencyKey(claimID int) {
+	err := config.DBConnList[0].Exec(
+		`DELETE FROM idempotency_keys WHERE id = ? AND status_code IS NULL`, claimID,
+	).Error
+
+	if err != nil {
+		log.Printf("[ReleaseIdempotencyKey] Error for claim %d: %v", claimID, err)
+	}
+}
+
+// StartIdempotencyKeyCleanup deletes expired keys on a fixed interval
+func StartIdempotencyKeyCleanup(interval time.Duration) {
+	log.Printf("[IdempotencyKeyCleanup] Started - Interval: %s, TTL: %s", interval, IdempotencyKeyTTL())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result := config.DBConnList[0].Exec(`DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP`)
+		if result.Error != nil {
+			log.Printf("[IdempotencyKeyCleanup] Error: %v", result.Error)
+		} else if result.RowsAffected > 0 {
+			log.Printf("[IdempotencyKeyCleanup] Deleted %d expired keys", result.RowsAffected)
+		}
+		<-ticker.C
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
 	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	utils_v1 "github.com/FDSAP-Git-Org/hephaestus/utils/v1"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -104,6 +105,20 @@ func Ptr[T comparable](val T) *T {
 	return &val
 }
 
+// GetEnvDuration reads a duration (e.g. "30m", "1h") from the environment, falling back to defaultVal.
+func GetEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val := utils_v1.GetEnv(key)
+	if val == "" {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("[GetEnvDuration] Invalid value for %s: %q, using %s", key, val, defaultVal)
+		return defaultVal
+	}
+	return d
+}
+
 func GetUserId(c fiber.Ctx) int {
 	userIdInterface := c.Locals("userId")
 
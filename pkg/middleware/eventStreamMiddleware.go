@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+)
+
+// EventStreamAuthMiddleware authenticates an event stream by a ?ticket= from
+// POST /events/tickets, which browser EventSource can send, or else by the
+// usual bearer token
+func EventStreamAuthMiddleware(c fiber.Ctx) error {
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		return AuthMiddleware(c)
+	}
+
+	userID := utils.RedeemEventStreamTicket(ticket)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Invalid or expired ticket", nil, http.StatusUnauthorized)
+	}
+
+	c.Locals("userId", userID)
+	return c.Next()
+}
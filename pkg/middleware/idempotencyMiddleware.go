@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware makes a creating request safe to retry with an Idempotency-Key header.
+// The first request with a key runs and its response is stored for the user; a
+// retry with the same payload gets that response replayed, a reused key with a
+// different payload is rejected, and a retry while the first request is still
+// running gets 409; once the processing lease has run out, as when the process
+// died mid-request, a retry runs again. Server errors release the key. Runs
+// after AuthMiddleware.
+func IdempotencyMiddleware(c fiber.Ctx) error {
+	key := strings.TrimSpace(c.Get("Idempotency-Key"))
+	if key == "" {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength),
+			nil, http.StatusBadRequest)
+	}
+
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	fingerprint, err := requestFingerprint(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	claimID, err := utils.ClaimIdempotencyKey(userID, key, fingerprint, c.Method(), c.Path())
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to check Idempotency-Key", err, http.StatusInternalServerError)
+	}
+	if claimID == 0 {
+		return replayIdempotentResponse(c, userID, key, fingerprint)
+	}
+
+	if err := c.Next(); err != nil {
+		utils.ReleaseIdempotencyKey(claimID)
+		return err
+	}
+
+	status := c.Response().StatusCode()
+	if status >= http.StatusInternalServerError {
+		utils.ReleaseIdempotencyKey(claimID)
+		return nil
+	}
+
+	// fasthttp reuses the buffer, so store a copy
+	body := append([]byte(nil), c.Response().Body()...)
+	contentType := string(c.Response().Header.ContentType())
+	if err := utils.CompleteIdempotencyKey(claimID, status, contentType, body); err != nil {
+		log.Printf("[IdempotencyMiddleware] Response for key of user %d not stored: %v", userID, err)
+	}
+	return nil
+}
+
+// replayIdempotentResponse answers a request whose key was already claimed
+func replayIdempotentResponse(c fiber.Ctx, userID int, key, fingerprint string) error {
+	record, err := utils.GetIdempotencyKey(userID, key)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to check Idempotency-Key", err, http.StatusInternalServerError)
+	}
+
+	// Released or expired since the claim failed
+	if record == nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Request with this Idempotency-Key failed, retry it", nil, http.StatusConflict)
+	}
+
+	if record.Fingerprint != fingerprint {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Idempotency-Key was already used for a different request", nil, http.StatusConflict)
+	}
+
+	if record.StatusCode == nil {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(utils.IdempotencyProcessingLease().Seconds())))
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Request with this Idempotency-Key is still being processed", nil, http.StatusConflict)
+	}
+
+	c.Set("Idempotent-Replayed", "true")
+	if record.ContentType != nil {
+		c.Set(fiber.HeaderContentType, *record.ContentType)
+	}
+	return c.Status(*record.StatusCode).Send(record.ResponseBody)
+}
+
+// requestFingerprint hashes what makes two requests the same: method, path, query
+// and payload. Multipart bodies are hashed by field and file content because the
+// boundary changes between retries.
+func requestFingerprint(c fiber.Ctx) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", c.Method(), c.Path(), c.Request().URI().QueryString())
+
+	if !strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), fiber.MIMEMultipartForm) {
+		h.Write(c.Body())
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range sortedKeys(form.Value) {
+		fmt.Fprintf(h, "value %q %q\n", name, form.Value[name])
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, fileHeader := range form.File[name] {
+			fmt.Fprintf(h, "file %q %q %d\n", name, fileHeader.Filename, fileHeader.Size)
+			if err := hashFormFile(h, fileHeader); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFormFile(h hash.Hash, fileHeader *multipart.FileHeader) error {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(h, file)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
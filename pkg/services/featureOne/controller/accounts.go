@@ -0,0 +1,360 @@
+package ctrFeatureOne
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// ACCOUNT ENDPOINTS
+// ============================================
+
+// CreateAccount creates a payment account (cash, card or e-wallet) that expenses
+// and transfers are booked on
+func CreateAccount(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CreateAccountRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateAccount(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	account, err := scpFeatureOne.CreateAccount(userID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create account", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Account created successfully", account, http.StatusCreated)
+}
+
+// GetAccounts retrieves the user's accounts with their current balances
+func GetAccounts(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accounts, err := scpFeatureOne.GetAccounts(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve accounts", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Accounts retrieved successfully", accounts, http.StatusOK)
+}
+
+// GetAccountByID retrieves a single account with its current balance
+func GetAccountByID(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	account, err := scpFeatureOne.GetAccountByID(userID, accountID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve account", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Account retrieved successfully", account, http.StatusOK)
+}
+
+// UpdateAccount replaces an account's name, type, currency and opening balance
+func UpdateAccount(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.CreateAccountRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateAccount(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	account, err := scpFeatureOne.UpdateAccount(userID, accountID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update account", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Account updated successfully", account, http.StatusOK)
+}
+
+// GetAccountLedger lists an account's transactions oldest first with the running
+// balance after each. Supports ?startDate=, ?endDate=, ?reconciled=true|false,
+// ?limit= and ?offset=.
+func GetAccountLedger(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	filters := &mdlFeatureOne.AccountLedgerFilters{
+		StartDate: getQueryString(c, "startDate"),
+		EndDate:   getQueryString(c, "endDate"),
+		Limit:     getQueryIntDefault(c, "limit", 50),
+		Offset:    getQueryIntDefault(c, "offset", 0),
+	}
+	if reconciled := c.Query("reconciled"); reconciled != "" {
+		value, err := strconv.ParseBool(reconciled)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Reconciled must be true or false", err, http.StatusBadRequest)
+		}
+		filters.Reconciled = &value
+	}
+
+	// Validate limit
+	if filters.Limit < 1 || filters.Limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+	if filters.Offset < 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Offset must not be negative", nil, http.StatusBadRequest)
+	}
+
+	if err := hlpFeatureOne.ValidateAccountLedgerFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	ledger, err := scpFeatureOne.GetAccountLedger(userID, accountID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve ledger", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Ledger retrieved successfully", ledger, http.StatusOK)
+}
+
+// GetAccountBalances returns an account's balance at the end of each ?interval=
+// day, week, month or year (default day) between ?startDate= and ?endDate=,
+// which default to the last 30 days
+func GetAccountBalances(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	interval := strings.ToLower(c.Query("interval", "day"))
+	startDate, endDate, err := hlpFeatureOne.ValidateAccountBalanceHistory(
+		getQueryString(c, "startDate"), getQueryString(c, "endDate"), interval, time.Now())
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	balances, err := scpFeatureOne.GetAccountBalanceHistory(userID, accountID, startDate, endDate, interval)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve balances", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Balances retrieved successfully", balances, http.StatusOK)
+}
+
+// ============================================
+// RECONCILIATION ENDPOINTS
+// ============================================
+
+// ReconcileAccount marks the transactions found on a bank or card statement as
+// cleared and compares the cleared balance with the statement balance
+func ReconcileAccount(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.ReconcileAccountRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateReconcileAccount(&req, time.Now()); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	reconciliation, invalidIDs, err := scpFeatureOne.ReconcileAccount(userID, accountID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to reconcile account", err, http.StatusInternalServerError)
+	}
+	if len(invalidIDs) > 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			fmt.Sprintf("Transactions %v are not on this account, are dated after the statement or are already reconciled",
+				invalidIDs), nil, http.StatusBadRequest)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Account reconciled successfully", reconciliation, http.StatusCreated)
+}
+
+// GetAccountReconciliations retrieves an account's reconciliations, newest first
+func GetAccountReconciliations(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	reconciliations, err := scpFeatureOne.GetAccountReconciliations(accountID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve reconciliations", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Reconciliations retrieved successfully", reconciliations, http.StatusOK)
+}
+
+// DeleteAccountReconciliation undoes a reconciliation, unmarking its transactions
+func DeleteAccountReconciliation(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	accountID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid account ID", err, http.StatusBadRequest)
+	}
+
+	reconciliationID, err := strconv.Atoi(c.Params("reconciliationId"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid reconciliation ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.AccountExists(userID, accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Account not found", nil, http.StatusNotFound)
+	}
+
+	if !scpFeatureOne.AccountReconciliationExists(accountID, reconciliationID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Reconciliation not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteAccountReconciliation(accountID, reconciliationID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete reconciliation", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Reconciliation deleted successfully", nil, http.StatusOK)
+}
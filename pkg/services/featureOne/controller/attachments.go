@@ -0,0 +1,249 @@
+package ctrFeatureOne
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	utils_v1 "github.com/FDSAP-Git-Org/hephaestus/utils/v1"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// ATTACHMENT ENDPOINTS
+// ============================================
+
+// UploadExpenseAttachments attaches one or more images or PDFs (form fields
+// "files" or "file") to an expense. ?storage=local|cloudinary picks the backend,
+// defaulting to ATTACHMENT_STORAGE or local.
+func UploadExpenseAttachments(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	storage := strings.ToLower(c.Query("storage", defaultAttachmentStorage()))
+	if !hlpFeatureOne.ValidAttachmentStorage(storage) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Storage must be local or cloudinary", nil, http.StatusBadRequest)
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid form data", err, http.StatusBadRequest)
+	}
+	files := append(form.File["files"], form.File["file"]...)
+	if len(files) == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"At least one file is required", nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	count, err := scpFeatureOne.CountExpenseAttachments(expenseID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve attachments", err, http.StatusInternalServerError)
+	}
+	if count+len(files) > hlpFeatureOne.MaxAttachmentsPerExpense {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			fmt.Sprintf("An expense can have at most %d attachments (it has %d)",
+				hlpFeatureOne.MaxAttachmentsPerExpense, count), nil, http.StatusBadRequest)
+	}
+
+	// Validate every file before storing any of them
+	attachments := make([]mdlFeatureOne.NewAttachment, len(files))
+	seen := make(map[string]bool, len(files))
+	for i, fileHeader := range files {
+		attachment, err := hlpFeatureOne.InspectAttachment(fileHeader)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				err.Error(), nil, http.StatusBadRequest)
+		}
+		if seen[attachment.Checksum] || scpFeatureOne.AttachmentChecksumExists(expenseID, attachment.Checksum) {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+				fmt.Sprintf("%s is already attached to this expense", attachment.Filename), nil, http.StatusConflict)
+		}
+		seen[attachment.Checksum] = true
+		attachment.Storage = storage
+		attachments[i] = *attachment
+	}
+
+	for i, fileHeader := range files {
+		url, err := storeAttachment(c, fileHeader, storage)
+		if err != nil {
+			removeStoredAttachments(attachments[:i])
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				fmt.Sprintf("Failed to upload %s", attachments[i].Filename), err, http.StatusBadRequest)
+		}
+		attachments[i].URL = url
+	}
+
+	created, err := scpFeatureOne.AddExpenseAttachments(userID, expenseID, attachments)
+	if err != nil {
+		removeStoredAttachments(attachments)
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to save attachments", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Attachments uploaded successfully", created, http.StatusCreated)
+}
+
+// GetExpenseAttachments lists the files attached to an expense
+func GetExpenseAttachments(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	attachments, err := scpFeatureOne.GetExpenseAttachments(userID, expenseID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve attachments", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Attachments retrieved successfully", attachments, http.StatusOK)
+}
+
+// DeleteExpenseAttachment removes an attachment and its stored file
+func DeleteExpenseAttachment(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	attachmentID, err := strconv.Atoi(c.Params("attachmentId"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid attachment ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	if !scpFeatureOne.AttachmentExists(userID, expenseID, attachmentID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Attachment not found", nil, http.StatusNotFound)
+	}
+
+	file, err := scpFeatureOne.DeleteExpenseAttachment(userID, expenseID, attachmentID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete attachment", err, http.StatusInternalServerError)
+	}
+	scpFeatureOne.RemoveAttachmentFile(*file)
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Attachment deleted successfully", nil, http.StatusOK)
+}
+
+// ============================================
+// ATTACHMENT HELPERS
+// ============================================
+
+func defaultAttachmentStorage() string {
+	if storage := utils_v1.GetEnv("ATTACHMENT_STORAGE"); storage != "" {
+		return strings.ToLower(storage)
+	}
+	return "local"
+}
+
+// storeAttachment uploads a file to the storage backend and returns its URL
+func storeAttachment(c fiber.Ctx, fileHeader *multipart.FileHeader, storage string) (string, error) {
+	if storage == "cloudinary" {
+		return config.UploadToCloudinary(fileHeader, config.LoadCloudinaryConfig())
+	}
+
+	uploadedPath, err := utils.UploadFile(c, fileHeader, utils.AttachmentUploadConfig())
+	if err != nil {
+		return "", err
+	}
+	return utils_v1.GetEnv("BASE_URL") + uploadedPath, nil
+}
+
+// attachUploadedImage records the image uploaded with a new expense as its first
+// attachment and returns the expense re-read with it; failures only log
+func attachUploadedImage(userID int, expense *mdlFeatureOne.ExpenseResponse, fileHeader *multipart.FileHeader, storage string) *mdlFeatureOne.ExpenseResponse {
+	if expense.ImageURL == nil {
+		return expense
+	}
+
+	attachment, err := hlpFeatureOne.InspectAttachment(fileHeader)
+	if err != nil {
+		fmt.Printf("Warning: Failed to inspect image of expense %d: %v\n", expense.ID, err)
+		return expense
+	}
+	attachment.URL = *expense.ImageURL
+	attachment.Storage = storage
+
+	if _, err := scpFeatureOne.AddExpenseAttachments(userID, expense.ID, []mdlFeatureOne.NewAttachment{*attachment}); err != nil {
+		return expense
+	}
+	if updated, err := scpFeatureOne.GetExpenseByID(userID, expense.ID); err == nil {
+		return updated
+	}
+	return expense
+}
+
+// removeStoredAttachments deletes files that were uploaded but never recorded
+func removeStoredAttachments(attachments []mdlFeatureOne.NewAttachment) {
+	for _, attachment := range attachments {
+		if attachment.URL != "" {
+			scpFeatureOne.RemoveAttachmentFile(mdlFeatureOne.AttachmentFile{URL: attachment.URL, Storage: attachment.Storage})
+		}
+	}
+}
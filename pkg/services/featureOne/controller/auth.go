@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/smtp"
 	"strings"
 	"time"
 
@@ -447,24 +446,5 @@ func sendPasswordResetEmail(email, name, token string) error {
 	fmt.Printf("=======================\n")
 
 	// Send via SMTP
-	return sendWithSMTP(email, subject, htmlBody)
-}
-
-func sendWithSMTP(to, subject, htmlContent string) error {
-	smtpHost := utils_v1.GetEnv("SMTP_HOST")
-	smtpPort := utils_v1.GetEnv("SMTP_PORT")
-	smtpUser := utils_v1.GetEnv("SMTP_USER")
-	smtpPass := utils_v1.GetEnv("SMTP_PASS")
-	from := utils_v1.GetEnv("EMAIL_FROM")
-
-	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
-
-	msg := []byte("To: " + to + "\r\n" +
-		"Subject: " + subject + "\r\n" +
-		"MIME-Version: 1.0\r\n" +
-		"Content-Type: text/html; charset=UTF-8\r\n" +
-		"\r\n" + htmlContent)
-
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, from, []string{to}, msg)
-	return err
+	return utils.SendEmail(email, subject, htmlBody)
 }
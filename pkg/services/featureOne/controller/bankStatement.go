@@ -0,0 +1,130 @@
+package ctrFeatureOne
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// maxStatementSize caps uploaded statement files at 10 MB
+const maxStatementSize = 10 << 20
+
+// ============================================
+// BANK STATEMENT ENDPOINTS
+// ============================================
+
+// ImportBankStatement imports the debits of an OFX/QFX, QIF or CAMT.053 statement
+// as expenses through the batch upload job. ?format= overrides detection,
+// ?dateOrder=dmy reads QIF dates as day/month/year and ?accountId= books the
+// expenses on one of the user's accounts. Lines that look like existing expenses
+// are imported and marked as duplicates in the job results unless ?force=true.
+func ImportBankStatement(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	force, err := parseForce(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"force must be true or false", err, http.StatusBadRequest)
+	}
+
+	accountID := getQueryInt(c, "accountId")
+	if accountID != nil && !scpFeatureOne.AccountExists(userID, *accountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Account not found", nil, http.StatusBadRequest)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Statement file is required", err, http.StatusBadRequest)
+	}
+	if file.Size > maxStatementSize {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Statement file is too large (max 10 MB)", nil, http.StatusBadRequest)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to open statement file", err, http.StatusInternalServerError)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, maxStatementSize))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to read statement file", err, http.StatusInternalServerError)
+	}
+
+	// Resolve the format
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		detected, ok := hlpFeatureOne.DetectStatementFormat(file.Filename, content)
+		if !ok {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Could not detect the statement format; pass format=ofx, qfx, qif or camt053", nil, http.StatusBadRequest)
+		}
+		format = detected
+	}
+	if !hlpFeatureOne.ValidStatementFormat(format) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Format must be one of ofx, qfx, qif or camt053", nil, http.StatusBadRequest)
+	}
+
+	statement, err := hlpFeatureOne.ParseBankStatement(format, content, strings.ToLower(c.Query("dateOrder")))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			fmt.Sprintf("Invalid %s statement: %s", format, err.Error()), nil, http.StatusBadRequest)
+	}
+
+	expenses, skippedCredits, skippedDuplicates := hlpFeatureOne.StatementToExpenseRows(statement)
+	if len(expenses) == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Statement contains no debits to import", nil, http.StatusBadRequest)
+	}
+	if len(expenses) > 1000 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Statement too large (max 1000 debits)", nil, http.StatusBadRequest)
+	}
+	for i := range expenses {
+		expenses[i].AccountID = accountID
+	}
+
+	// Create batch job
+	jobID, err := scpFeatureOne.CreateBatchJob(userID, "expense_statement_import", len(expenses))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create batch job", err, http.StatusInternalServerError)
+	}
+
+	// Process in background; lines already imported are skipped there
+	go scpFeatureOne.ProcessBatchUpload(jobID, userID, expenses, force)
+
+	response := mdlFeatureOne.StatementImportResponse{
+		JobID:             jobID,
+		TotalItems:        len(expenses),
+		Status:            "pending",
+		Format:            format,
+		AccountID:         statement.AccountID,
+		Transactions:      len(statement.Transactions),
+		SkippedCredits:    skippedCredits,
+		SkippedDuplicates: skippedDuplicates,
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_202,
+		"Statement import job created successfully", response, http.StatusAccepted)
+}
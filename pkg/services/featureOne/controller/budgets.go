@@ -0,0 +1,241 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// BUDGET ENDPOINTS
+// ============================================
+
+// CreateBudget creates a new budget
+func CreateBudget(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CreateBudgetRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if req.StartDate == "" && req.Period != "custom" {
+		req.StartDate = time.Now().Format("2006-01-02")
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateCreateBudget(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+	req.Thresholds = hlpFeatureOne.NormalizeThresholds(req.Thresholds)
+
+	budget, err := scpFeatureOne.CreateBudget(userID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create budget", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Budget created successfully", budget, http.StatusCreated)
+}
+
+// GetBudgets retrieves the user's budgets
+func GetBudgets(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	budgets, err := scpFeatureOne.GetBudgets(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve budgets", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Budgets retrieved successfully", budgets, http.StatusOK)
+}
+
+// GetBudgetStatus computes spent vs. remaining for every budget active on ?date= (default today)
+func GetBudgetStatus(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	asOf := time.Now()
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Invalid date format (expected YYYY-MM-DD)", err, http.StatusBadRequest)
+		}
+		asOf = parsed
+	}
+
+	status, err := scpFeatureOne.GetBudgetStatuses(userID, asOf)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to compute budget status", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Budget status retrieved successfully", status, http.StatusOK)
+}
+
+// GetBudget retrieves a single budget
+func GetBudget(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	budgetID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid budget ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.BudgetExists(userID, budgetID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Budget not found", nil, http.StatusNotFound)
+	}
+
+	budget, err := scpFeatureOne.GetBudgetByID(userID, budgetID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve budget", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Budget retrieved successfully", budget, http.StatusOK)
+}
+
+// UpdateBudget edits a budget
+func UpdateBudget(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	budgetID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid budget ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.UpdateBudgetRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.BudgetExists(userID, budgetID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Budget not found", nil, http.StatusNotFound)
+	}
+
+	budget, err := scpFeatureOne.GetBudgetByID(userID, budgetID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve budget", err, http.StatusInternalServerError)
+	}
+
+	// Merge provided fields into the stored budget
+	if req.Name != nil {
+		budget.Name = *req.Name
+	}
+	if req.CategoryID != nil {
+		budget.CategoryID = req.CategoryID
+	}
+	if req.Amount != nil {
+		budget.Amount = *req.Amount
+	}
+	if req.Period != nil {
+		budget.Period = *req.Period
+	}
+	if req.StartDate != nil {
+		budget.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		budget.EndDate = req.EndDate
+	}
+	if req.Rollover != nil {
+		budget.Rollover = *req.Rollover
+	}
+	if req.Thresholds != nil {
+		budget.Thresholds = req.Thresholds
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateCreateBudget(&mdlFeatureOne.CreateBudgetRequest{
+		Name:       budget.Name,
+		CategoryID: budget.CategoryID,
+		Amount:     budget.Amount,
+		Period:     budget.Period,
+		StartDate:  budget.StartDate,
+		EndDate:    budget.EndDate,
+		Rollover:   budget.Rollover,
+		Thresholds: budget.Thresholds,
+	}); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+	budget.Thresholds = hlpFeatureOne.NormalizeThresholds(budget.Thresholds)
+
+	updated, err := scpFeatureOne.UpdateBudget(userID, budgetID, budget)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update budget", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Budget updated successfully", updated, http.StatusOK)
+}
+
+// DeleteBudget deletes a budget
+func DeleteBudget(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	budgetID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid budget ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.BudgetExists(userID, budgetID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Budget not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteBudget(userID, budgetID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete budget", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Budget deleted successfully", nil, http.StatusOK)
+}
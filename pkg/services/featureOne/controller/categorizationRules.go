@@ -0,0 +1,203 @@
+package ctrFeatureOne
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// CATEGORIZATION RULE ENDPOINTS
+// ============================================
+
+// CreateCategorizationRule creates a rule that fills in new expenses
+func CreateCategorizationRule(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CategorizationRuleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if err := validateCategorizationRule(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	rule, err := scpFeatureOne.CreateCategorizationRule(userID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create rule", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Rule created successfully", rule, http.StatusCreated)
+}
+
+// GetCategorizationRules retrieves the user's rules in the order they run
+func GetCategorizationRules(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	rules, err := scpFeatureOne.GetCategorizationRules(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve rules", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Rules retrieved successfully", rules, http.StatusOK)
+}
+
+// UpdateCategorizationRule replaces a rule
+func UpdateCategorizationRule(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid rule ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.CategorizationRuleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if err := validateCategorizationRule(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.CategorizationRuleExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Rule not found", nil, http.StatusNotFound)
+	}
+
+	rule, err := scpFeatureOne.UpdateCategorizationRule(userID, ruleID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update rule", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Rule updated successfully", rule, http.StatusOK)
+}
+
+// DeleteCategorizationRule deletes a rule
+func DeleteCategorizationRule(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid rule ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.CategorizationRuleExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Rule not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteCategorizationRule(userID, ruleID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete rule", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Rule deleted successfully", nil, http.StatusOK)
+}
+
+// ApplyCategorizationRules re-runs the rules over existing expenses as a batch job;
+// its progress is read from the batch job status endpoint
+func ApplyCategorizationRules(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.ApplyRulesRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Invalid request body", err, http.StatusBadRequest)
+		}
+	}
+
+	if err := hlpFeatureOne.ValidateApplyRules(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	expenseIDs, err := scpFeatureOne.GetRuleApplicationTargets(userID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve expenses", err, http.StatusInternalServerError)
+	}
+	if len(expenseIDs) == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"No expenses to apply rules to", nil, http.StatusBadRequest)
+	}
+
+	// Create batch job
+	jobID, err := scpFeatureOne.CreateBatchJob(userID, "expense_rules_apply", len(expenseIDs))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create batch job", err, http.StatusInternalServerError)
+	}
+
+	// Process in background
+	go scpFeatureOne.ProcessRuleApplication(jobID, userID, expenseIDs, req.Overwrite)
+
+	// Return job info immediately
+	response := mdlFeatureOne.BatchJobCreatedResponse{
+		JobID:      jobID,
+		TotalItems: len(expenseIDs),
+		Status:     "pending",
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_202,
+		"Rules job created successfully", response, http.StatusAccepted)
+}
+
+// validateCategorizationRule validates a rule request, including the checks that
+// need the database: the category exists and a regex compiles
+func validateCategorizationRule(req *mdlFeatureOne.CategorizationRuleRequest) error {
+	if err := hlpFeatureOne.ValidateCategorizationRule(req); err != nil {
+		return err
+	}
+	if req.SetCategoryID != nil && !scpFeatureOne.ExpenseCategoryExists(*req.SetCategoryID) {
+		return fmt.Errorf("category not found")
+	}
+	if req.MatchType == "regex" && !scpFeatureOne.RegexPatternValid(*req.Pattern) {
+		return fmt.Errorf("pattern is not a valid regular expression")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// DUPLICATE DETECTION ENDPOINTS
+// ============================================
+
+// GetDuplicateExpenses lists clusters of expenses that look like the same spending.
+// ?days= (default 3) and ?similarity= (default 0.5) loosen or tighten the match;
+// ?startDate= and ?endDate= limit the scan.
+func GetDuplicateExpenses(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters := &mdlFeatureOne.DuplicateFilters{
+		WindowDays: getQueryIntDefault(c, "days", hlpFeatureOne.DefaultDuplicateWindowDays),
+		Similarity: hlpFeatureOne.DefaultDuplicateSimilarity,
+		StartDate:  getQueryString(c, "startDate"),
+		EndDate:    getQueryString(c, "endDate"),
+	}
+	if similarity := getQueryFloat(c, "similarity"); similarity != nil {
+		filters.Similarity = *similarity
+	}
+
+	if err := hlpFeatureOne.ValidateDuplicateFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	clusters, err := scpFeatureOne.GetDuplicateClusters(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to find duplicate expenses", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Duplicate expenses retrieved successfully", clusters, http.StatusOK)
+}
+
+// ============================================
+// HELPER FUNCTIONS
+// ============================================
+
+// parseForce reads the ?force= flag that skips the duplicate check
+func parseForce(c fiber.Ctx) (bool, error) {
+	return parseQueryBool(c, "force")
+}
+
+// parseQueryBool reads an optional true/false query flag
+func parseQueryBool(c fiber.Ctx, key string) (bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// checkDuplicateExpense finds the existing expenses a new one looks like, which
+// are returned with the created expense as a warning. ?rejectDuplicates=true
+// answers 409 with them instead of creating it; ?force=true skips the check.
+// When ok is false the request has already been answered with resp.
+func checkDuplicateExpense(c fiber.Ctx, userID int, req *mdlFeatureOne.CreateExpenseRequest) (duplicates []mdlFeatureOne.DuplicateCandidate, ok bool, resp error) {
+	force, err := parseForce(c)
+	if err != nil {
+		return nil, false, v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"force must be true or false", err, http.StatusBadRequest)
+	}
+	reject, err := parseQueryBool(c, "rejectDuplicates")
+	if err != nil {
+		return nil, false, v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"rejectDuplicates must be true or false", err, http.StatusBadRequest)
+	}
+	if force {
+		return nil, true, nil
+	}
+
+	duplicates, err = scpFeatureOne.FindDuplicateExpenses(userID, req, nil)
+	if err != nil {
+		if reject {
+			return nil, false, v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+				"Failed to check for duplicate expenses", err, http.StatusInternalServerError)
+		}
+		// Only a warning is lost, so the expense is still created
+		return nil, true, nil
+	}
+	if len(duplicates) == 0 || !reject {
+		return duplicates, true, nil
+	}
+
+	return nil, false, v1.JSONResponseWithData(c, respcode.ERR_CODE_409,
+		"Expense looks like a duplicate; send it again with force=true to create it anyway",
+		mdlFeatureOne.DuplicateWarningResponse{Duplicates: duplicates}, http.StatusConflict)
+}
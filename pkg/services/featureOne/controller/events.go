@@ -0,0 +1,99 @@
+package ctrFeatureOne
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+)
+
+const (
+	// Comments sent while idle keep proxies from closing the stream
+	eventStreamHeartbeat = 20 * time.Second
+	eventStreamRetryMs   = 5000
+)
+
+// ============================================
+// LIVE EVENT ENDPOINTS
+// ============================================
+
+// CreateEventStreamTicket issues a short-lived, single-use ticket for opening the
+// event stream with ?ticket=, since browser EventSource cannot send a bearer token
+func CreateEventStreamTicket(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ticket, err := utils.IssueEventStreamTicket(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create event stream ticket", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Event stream ticket created successfully", mdlFeatureOne.EventStreamTicketResponse{
+			Ticket:    ticket,
+			ExpiresIn: int(utils.EventStreamTicketTTL.Seconds()),
+		}, http.StatusCreated)
+}
+
+// StreamEvents pushes the user's expense changes and batch job progress as
+// Server-Sent Events to every client they have connected. Events sent while a
+// client is reconnecting are not replayed, so clients refresh on reconnect.
+func StreamEvents(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	events, unsubscribe, err := utils.SubscribeUserEvents(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			err.Error(), nil, http.StatusConflict)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+
+	// The writer runs after the handler has returned, so it only uses values captured here
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		log.Printf("[StreamEvents] Connected - UserID: %d", userID)
+
+		fmt.Fprintf(w, "retry: %d\n\nevent: ready\ndata: {}\n\n", eventStreamRetryMs)
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event := <-events:
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, event.Data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// Writing to a client that has gone away fails, which ends the stream
+			if err := w.Flush(); err != nil {
+				log.Printf("[StreamEvents] Disconnected - UserID: %d", userID)
+				return
+			}
+		}
+	})
+}
@@ -29,6 +29,15 @@ func CreateCategory(c fiber.Ctx) error {
 			"Name is required", nil, http.StatusBadRequest)
 	}
 
+	// Validate kind (expense categories by default)
+	if req.Kind == "" {
+		req.Kind = "expense"
+	}
+	if req.Kind != "expense" && req.Kind != "income" {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Kind must be expense or income", nil, http.StatusBadRequest)
+	}
+
 	// Create category
 	category, err := scpFeatureOne.CreateCategory(&req)
 	if err != nil {
@@ -45,6 +54,7 @@ func CreateCategory(c fiber.Ctx) error {
 		ID:          category.ID,
 		Name:        category.Name,
 		Description: category.Description,
+		Kind:        category.Kind,
 		CreatedAt:   category.CreatedAt,
 		UpdatedAt:   category.UpdatedAt,
 	}
@@ -57,6 +67,7 @@ func CreateCategory(c fiber.Ctx) error {
 func GetCategories(c fiber.Ctx) error {
 	// Parse query parameters
 	filters := &mdlFeatureOne.CategoryFilters{
+		Kind:   getQueryString(c, "kind"),
 		Limit:  getQueryIntDefault(c, "limit", 50),
 		Offset: getQueryIntDefault(c, "offset", 0),
 	}
@@ -67,6 +78,12 @@ func GetCategories(c fiber.Ctx) error {
 			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
 	}
 
+	// Validate kind if provided
+	if filters.Kind != nil && *filters.Kind != "expense" && *filters.Kind != "income" {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Kind must be expense or income", nil, http.StatusBadRequest)
+	}
+
 	// Get categories
 	result, err := scpFeatureOne.GetCategories(filters)
 	if err != nil {
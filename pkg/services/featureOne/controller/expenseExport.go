@@ -0,0 +1,96 @@
+package ctrFeatureOne
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// exportFlushEvery is how many rows are buffered before they are pushed to the client
+const exportFlushEvery = 200
+
+// ============================================
+// EXPORT ENDPOINTS
+// ============================================
+
+// ExportExpenses streams every expense matching the GetExpenses filters (including
+// ?viewId=) as ?format= csv (default, re-importable via batch upload), xlsx, json or pdf
+func ExportExpenses(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	format := strings.ToLower(c.Query("format", "csv"))
+	contentType, extension, ok := hlpFeatureOne.ExportFormat(format)
+	if !ok {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Format must be one of csv, xlsx, json or pdf", nil, http.StatusBadRequest)
+	}
+
+	filters := parseExpenseFilters(c)
+	if ok, resp := applyRequestedView(c, userID, filters); !ok {
+		return resp
+	}
+	if err := hlpFeatureOne.ValidateExpenseFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	now := time.Now()
+	meta := mdlFeatureOne.ExportMeta{
+		StartDate:   filters.StartDate,
+		EndDate:     filters.EndDate,
+		GeneratedAt: now,
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="expenses-%s.%s"`, now.Format("20060102-150405"), extension))
+
+	// Once streaming starts the status is already sent, so failures can only be logged
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		writer, err := hlpFeatureOne.NewExpenseExportWriter(format, w, meta)
+		if err != nil {
+			log.Printf("[ExportExpenses] Error starting %s export for user %d: %v", format, userID, err)
+			return
+		}
+
+		count := 0
+		err = scpFeatureOne.StreamExpensesForExport(userID, filters, func(row *mdlFeatureOne.ExportExpenseRow) error {
+			if err := writer.WriteRow(row); err != nil {
+				return err
+			}
+			count++
+			if count%exportFlushEvery == 0 {
+				return w.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("[ExportExpenses] Export for user %d stopped after %d rows: %v", userID, count, err)
+			return
+		}
+
+		if err := writer.Close(); err != nil {
+			log.Printf("[ExportExpenses] Error finishing export for user %d: %v", userID, err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("[ExportExpenses] Error flushing export for user %d: %v", userID, err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package ctrFeatureOne
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// apiRevisionSource attributes changes made through the expense endpoints
+var apiRevisionSource = mdlFeatureOne.RevisionSource{Type: mdlFeatureOne.RevisionSourceAPI}
+
+// ============================================
+// EXPENSE HISTORY ENDPOINTS
+// ============================================
+
+// GetExpenseHistory lists the revisions of an expense, newest first
+func GetExpenseHistory(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	limit := getQueryIntDefault(c, "limit", 50)
+	offset := getQueryIntDefault(c, "offset", 0)
+
+	// Validate limit
+	if limit < 1 || limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+	if offset < 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Offset must not be negative", nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	history, err := scpFeatureOne.GetExpenseHistory(userID, expenseID, limit, offset)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve expense history", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Expense history retrieved successfully", history, http.StatusOK)
+}
+
+// RevertExpense restores an expense to its state after the given revision
+func RevertExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	revision, err := strconv.Atoi(c.Params("revision"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid revision", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	if !scpFeatureOne.ExpenseRevisionExists(userID, expenseID, revision) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Revision not found", nil, http.StatusNotFound)
+	}
+
+	expense, err := scpFeatureOne.RevertExpense(userID, expenseID, revision)
+	if err != nil {
+		if errors.Is(err, scpFeatureOne.ErrExpenseLocked) {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+				"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+		}
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to revert expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Expense reverted successfully", expense, http.StatusOK)
+}
@@ -0,0 +1,218 @@
+package ctrFeatureOne
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// SAVED VIEW ENDPOINTS
+// ============================================
+
+// CreateExpenseView saves a named set of expense filters and sort
+func CreateExpenseView(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.ExpenseViewRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate view
+	if err := hlpFeatureOne.ValidateExpenseView(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	count, err := scpFeatureOne.CountExpenseViews(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create view", err, http.StatusInternalServerError)
+	}
+	if count >= hlpFeatureOne.MaxViewsPerUser {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			fmt.Sprintf("A user can have at most %d saved views", hlpFeatureOne.MaxViewsPerUser), nil, http.StatusBadRequest)
+	}
+
+	if scpFeatureOne.ExpenseViewNameTaken(userID, req.Name, 0) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"A view with this name already exists", nil, http.StatusConflict)
+	}
+
+	view, err := scpFeatureOne.CreateExpenseView(userID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create view", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"View created successfully", view, http.StatusCreated)
+}
+
+// GetExpenseViews retrieves the user's saved views, default and pinned first
+func GetExpenseViews(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	views, err := scpFeatureOne.GetExpenseViews(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve views", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Views retrieved successfully", views, http.StatusOK)
+}
+
+// GetExpenseViewByID retrieves a single saved view
+func GetExpenseViewByID(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	viewID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid view ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseViewExists(userID, viewID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"View not found", nil, http.StatusNotFound)
+	}
+
+	view, err := scpFeatureOne.GetExpenseViewByID(userID, viewID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve view", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"View retrieved successfully", view, http.StatusOK)
+}
+
+// UpdateExpenseView replaces a saved view's name, filters and flags
+func UpdateExpenseView(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	viewID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid view ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.ExpenseViewRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate view
+	if err := hlpFeatureOne.ValidateExpenseView(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseViewExists(userID, viewID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"View not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseViewNameTaken(userID, req.Name, viewID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"A view with this name already exists", nil, http.StatusConflict)
+	}
+
+	view, err := scpFeatureOne.UpdateExpenseView(userID, viewID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update view", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"View updated successfully", view, http.StatusOK)
+}
+
+// DeleteExpenseView deletes a saved view
+func DeleteExpenseView(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	viewID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid view ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseViewExists(userID, viewID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"View not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteExpenseView(userID, viewID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete view", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"View deleted successfully", nil, http.StatusOK)
+}
+
+// ============================================
+// HELPER FUNCTIONS
+// ============================================
+
+// applyRequestedView merges the saved view named by ?viewId= into filters. When it
+// returns false the request has already been answered with the returned error.
+func applyRequestedView(c fiber.Ctx, userID int, filters *mdlFeatureOne.ExpenseFilters) (bool, error) {
+	if c.Query("viewId") == "" {
+		return true, nil
+	}
+
+	viewID, err := strconv.Atoi(c.Query("viewId"))
+	if err != nil {
+		return false, v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid view ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseViewExists(userID, viewID) {
+		return false, v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"View not found", nil, http.StatusNotFound)
+	}
+
+	view, err := scpFeatureOne.GetExpenseViewByID(userID, viewID)
+	if err != nil {
+		return false, v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve view", err, http.StatusInternalServerError)
+	}
+
+	hlpFeatureOne.ApplySavedView(filters, &view.Filters)
+	return true, nil
+}
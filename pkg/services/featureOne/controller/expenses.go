@@ -1,7 +1,8 @@
 package ctrFeatureOne
 
 import (
-	"fmt"
+	"errors"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
@@ -23,7 +24,10 @@ import (
 // EXPENSE ENDPOINTS
 // ============================================
 
-// CreateExpense creates a new expense
+// CreateExpense creates a new expense. When it has the same amount, a date a few
+// days away and a similar title as existing expenses, those are returned in
+// possibleDuplicates. ?rejectDuplicates=true answers 409 with them instead of
+// creating it; with an Idempotency-Key the forced retry then needs a new key.
 func CreateExpense(c fiber.Ctx) error {
 	userID := utils.GetUserId(c)
 	if userID == 0 {
@@ -42,13 +46,24 @@ func CreateExpense(c fiber.Ctx) error {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			err.Error(), nil, http.StatusBadRequest)
 	}
+	if req.AccountID != nil && !scpFeatureOne.AccountExists(userID, *req.AccountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Account not found", nil, http.StatusBadRequest)
+	}
+
+	// Likely duplicates are reported with the created expense
+	duplicates, ok, resp := checkDuplicateExpense(c, userID, &req)
+	if !ok {
+		return resp
+	}
 
 	// Create expense
-	expense, err := scpFeatureOne.CreateExpense(userID, &req)
+	expense, err := scpFeatureOne.CreateExpense(userID, &req, apiRevisionSource)
 	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to create expense", err, http.StatusInternalServerError)
 	}
+	expense.PossibleDuplicates = duplicates
 
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
 		"Expense created successfully", expense, http.StatusCreated)
@@ -81,10 +96,21 @@ func CreateExpenseV2(c fiber.Ctx) error {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			err.Error(), nil, http.StatusBadRequest)
 	}
+	if req.AccountID != nil && !scpFeatureOne.AccountExists(userID, *req.AccountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Account not found", nil, http.StatusBadRequest)
+	}
+
+	// Likely duplicates are reported with the created expense
+	duplicates, ok, resp := checkDuplicateExpense(c, userID, &req)
+	if !ok {
+		return resp
+	}
 
 	// Handle file upload
+	var fileHeader *multipart.FileHeader
 	if files, ok := form.File["image"]; ok && len(files) > 0 {
-		fileHeader := files[0]
+		fileHeader = files[0]
 		config := utils.DefaultFileUploadConfig()
 
 		uploadedPath, err := utils.UploadFile(c, fileHeader, config)
@@ -98,11 +124,15 @@ func CreateExpenseV2(c fiber.Ctx) error {
 	}
 
 	// Create expense
-	expense, err := scpFeatureOne.CreateExpense(userID, &req)
+	expense, err := scpFeatureOne.CreateExpense(userID, &req, apiRevisionSource)
 	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to create expense", err, http.StatusInternalServerError)
 	}
+	if fileHeader != nil {
+		expense = attachUploadedImage(userID, expense, fileHeader, "local")
+	}
+	expense.PossibleDuplicates = duplicates
 
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
 		"Expense created successfully", expense, http.StatusCreated)
@@ -134,10 +164,21 @@ func CreateExpenseWithCloudinary(c fiber.Ctx) error {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			err.Error(), nil, http.StatusBadRequest)
 	}
+	if req.AccountID != nil && !scpFeatureOne.AccountExists(userID, *req.AccountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Account not found", nil, http.StatusBadRequest)
+	}
+
+	// Likely duplicates are reported with the created expense
+	duplicates, ok, resp := checkDuplicateExpense(c, userID, &req)
+	if !ok {
+		return resp
+	}
 
 	// Handle file upload
+	var fileHeader *multipart.FileHeader
 	if files, ok := form.File["image"]; ok && len(files) > 0 {
-		fileHeader := files[0]
+		fileHeader = files[0]
 		cnf := config.LoadCloudinaryConfig()
 
 		uploadedPath, err := config.UploadToCloudinary(fileHeader, cnf)
@@ -150,17 +191,23 @@ func CreateExpenseWithCloudinary(c fiber.Ctx) error {
 	}
 
 	// Create expense
-	expense, err := scpFeatureOne.CreateExpense(userID, &req)
+	expense, err := scpFeatureOne.CreateExpense(userID, &req, apiRevisionSource)
 	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to create expense", err, http.StatusInternalServerError)
 	}
+	if fileHeader != nil {
+		expense = attachUploadedImage(userID, expense, fileHeader, "cloudinary")
+	}
+	expense.PossibleDuplicates = duplicates
 
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
 		"Expense created successfully", expense, http.StatusCreated)
 }
 
-// GetExpenses retrieves expenses with filters
+// GetExpenses retrieves expenses with filters. Besides the field filters, ?filter=
+// takes an expression such as amount > 500 and (category in (1,3) or tag = "travel"),
+// and ?viewId= applies a saved view.
 func GetExpenses(c fiber.Ctx) error {
 	userID := utils.GetUserId(c)
 	if userID == 0 {
@@ -168,16 +215,10 @@ func GetExpenses(c fiber.Ctx) error {
 			"Unauthorized", nil, http.StatusUnauthorized)
 	}
 
-	// Parse query parameters
-	filters := &mdlFeatureOne.ExpenseFilters{
-		Title:      getQueryString(c, "title"),
-		MinAmount:  getQueryFloat(c, "minAmount"),
-		MaxAmount:  getQueryFloat(c, "maxAmount"),
-		CategoryID: getQueryInt(c, "categoryId"),
-		StartDate:  getQueryString(c, "startDate"),
-		EndDate:    getQueryString(c, "endDate"),
-		Limit:      getQueryIntDefault(c, "limit", 50),
-		Offset:     getQueryIntDefault(c, "offset", 0),
+	// Parse query parameters; a saved view fills in those not given
+	filters := parseExpenseFilters(c)
+	if ok, resp := applyRequestedView(c, userID, filters); !ok {
+		return resp
 	}
 
 	// Validate limit
@@ -186,6 +227,12 @@ func GetExpenses(c fiber.Ctx) error {
 			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
 	}
 
+	// Validate filters
+	if err := hlpFeatureOne.ValidateExpenseFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
 	// Get expenses
 	result, err := scpFeatureOne.GetExpenses(userID, filters)
 	if err != nil {
@@ -224,6 +271,7 @@ func GetExpense(c fiber.Ctx) error {
 			"Failed to retrieve expense", err, http.StatusInternalServerError)
 	}
 
+	c.Set(fiber.HeaderETag, hlpFeatureOne.VersionETag(expense.Version))
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
 		"Expense retrieved successfully", expense, http.StatusOK)
 }
@@ -249,8 +297,8 @@ func UpdateExpense(c fiber.Ctx) error {
 	}
 
 	// Validate at least one field provided
-	if req.Title == nil && req.Amount == nil && req.CategoryID == nil &&
-		req.Date == nil && req.Notes == nil && req.ImageURL == nil {
+	if req.Title == nil && req.Amount == nil && req.CategoryID == nil && req.Merchant == nil &&
+		req.AccountID == nil && req.Date == nil && req.Notes == nil && req.ImageURL == nil && req.Tags == nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			"At least one field to update is required", nil, http.StatusBadRequest)
 	}
@@ -269,24 +317,156 @@ func UpdateExpense(c fiber.Ctx) error {
 		}
 	}
 
+	// Validate merchant if provided
+	if req.Merchant != nil {
+		if err := hlpFeatureOne.ValidateMerchantName(*req.Merchant); err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				err.Error(), nil, http.StatusBadRequest)
+		}
+	}
+
+	// Validate tags if provided
+	if err := hlpFeatureOne.ValidateTags(req.Tags); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	// Validate account if provided
+	if req.AccountID != nil && !scpFeatureOne.AccountExists(userID, *req.AccountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Account not found", nil, http.StatusBadRequest)
+	}
+
 	// Check if expense exists
 	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
 			"Expense not found", nil, http.StatusNotFound)
 	}
 
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	expectedVersion, err := hlpFeatureOne.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
 	// Update expense
-	expense, err := scpFeatureOne.UpdateExpense(userID, expenseID, &req)
+	expense, err := scpFeatureOne.UpdateExpense(userID, expenseID, &req, expectedVersion, apiRevisionSource)
 	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to update expense", err, http.StatusInternalServerError)
 	}
+	if expense == nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense was modified by another request", nil, http.StatusPreconditionFailed)
+	}
 
+	c.Set(fiber.HeaderETag, hlpFeatureOne.VersionETag(expense.Version))
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
 		"Expense updated successfully", expense, http.StatusOK)
 }
 
-// DeleteExpense soft deletes an expense
+// PatchExpense partially updates an expense. The body is an RFC 7396 merge patch
+// (application/merge-patch+json or application/json), where null clears notes,
+// category, merchant, account, image or tags, or an RFC 6902 JSON Patch (application/json-patch+json).
+func PatchExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	expectedVersion, err := hlpFeatureOne.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	var req *mdlFeatureOne.UpdateExpenseRequest
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+	switch contentType {
+	case "application/merge-patch+json", "application/json", "":
+		req, err = hlpFeatureOne.ParseExpenseMergePatch(c.Body())
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				err.Error(), nil, http.StatusBadRequest)
+		}
+
+	case "application/json-patch+json":
+		// Operations apply to the current state, so the update must land on that version
+		current, err := scpFeatureOne.GetExpenseByID(userID, expenseID)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+				"Failed to retrieve expense", err, http.StatusInternalServerError)
+		}
+		if expectedVersion != nil && *expectedVersion != current.Version {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+				"Expense was modified by another request", nil, http.StatusPreconditionFailed)
+		}
+		expectedVersion = &current.Version
+
+		req, err = hlpFeatureOne.ApplyExpenseJSONPatch(current, c.Body())
+		if errors.Is(err, hlpFeatureOne.ErrJSONPatchTestFailed) {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+				err.Error(), nil, http.StatusConflict)
+		}
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				err.Error(), nil, http.StatusBadRequest)
+		}
+
+	default:
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Content-Type must be application/merge-patch+json or application/json-patch+json",
+			nil, http.StatusUnsupportedMediaType)
+	}
+
+	if err := hlpFeatureOne.ValidateUpdateExpense(req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+	if req.AccountID != nil && !scpFeatureOne.AccountExists(userID, *req.AccountID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Account not found", nil, http.StatusBadRequest)
+	}
+
+	expense, err := scpFeatureOne.UpdateExpense(userID, expenseID, req, expectedVersion, apiRevisionSource)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update expense", err, http.StatusInternalServerError)
+	}
+	if expense == nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense was modified by another request", nil, http.StatusPreconditionFailed)
+	}
+
+	c.Set(fiber.HeaderETag, hlpFeatureOne.VersionETag(expense.Version))
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Expense updated successfully", expense, http.StatusOK)
+}
+
+// DeleteExpense moves an expense to the trash; its files are kept so it can be
+// restored and are removed by the trash purge job
 func DeleteExpense(c fiber.Ctx) error {
 	userID := utils.GetUserId(c)
 	if userID == 0 {
@@ -306,29 +486,40 @@ func DeleteExpense(c fiber.Ctx) error {
 			"Expense not found", nil, http.StatusNotFound)
 	}
 
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	expectedVersion, err := hlpFeatureOne.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
 	// Delete expense
-	result, err := scpFeatureOne.DeleteExpense(userID, expenseID)
+	result, err := scpFeatureOne.DeleteExpense(userID, expenseID, expectedVersion)
 	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to delete expense", err, http.StatusInternalServerError)
 	}
 
+	if result.VersionMismatch {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense was modified by another request", nil, http.StatusPreconditionFailed)
+	}
+
 	if !result.IsDeleted {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to delete expense", nil, http.StatusInternalServerError)
 	}
 
-	// Delete image file if exists
-	if result.ImageURL != nil && *result.ImageURL != "" {
-		if err := utils.DeleteUploadedFile(*result.ImageURL); err != nil {
-			fmt.Printf("Warning: Failed to delete image file %s: %v\n", *result.ImageURL, err)
-		}
-	}
-
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
 		"Expense deleted successfully", nil, http.StatusOK)
 }
 
+// DeleteExpenseWithCloudinary moves an expense with a Cloudinary image to the trash.
+// It behaves like DeleteExpense; the purge job removes files from either backend.
 func DeleteExpenseWithCloudinary(c fiber.Ctx) error {
 	userID := utils.GetUserId(c)
 	if userID == 0 {
@@ -347,28 +538,33 @@ func DeleteExpenseWithCloudinary(c fiber.Ctx) error {
 			"Expense not found", nil, http.StatusNotFound)
 	}
 
-	result, err := scpFeatureOne.DeleteExpense(userID, expenseID)
+	if scpFeatureOne.ExpenseLocked(expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	expectedVersion, err := hlpFeatureOne.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.DeleteExpense(userID, expenseID, expectedVersion)
 	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to delete expense", err, http.StatusInternalServerError)
 	}
 
+	if result.VersionMismatch {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Expense was modified by another request", nil, http.StatusPreconditionFailed)
+	}
+
 	if !result.IsDeleted {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
 			"Failed to delete expense", nil, http.StatusInternalServerError)
 	}
 
-	// Delete Cloudinary image if exists
-	if result.ImageURL != nil && *result.ImageURL != "" {
-		publicID := config.CloudinaryPublicIDFromURL(*result.ImageURL)
-		if publicID != "" {
-			cloudCfg := config.LoadCloudinaryConfig()
-			if err := config.DeleteCloudinaryImage(publicID, cloudCfg); err != nil {
-				fmt.Printf("Warning: Failed to delete Cloudinary image %s: %v\n", *result.ImageURL, err)
-			}
-		}
-	}
-
 	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
 		"Expense deleted successfully", nil, http.StatusOK)
 }
@@ -377,6 +573,35 @@ func DeleteExpenseWithCloudinary(c fiber.Ctx) error {
 // HELPER FUNCTIONS
 // ============================================
 
+// parseExpenseFilters reads the ExpenseFilters query parameters shared by list endpoints
+func parseExpenseFilters(c fiber.Ctx) *mdlFeatureOne.ExpenseFilters {
+	filters := &mdlFeatureOne.ExpenseFilters{
+		Title:       getQueryString(c, "title"),
+		Q:           getQueryString(c, "q"),
+		MinAmount:   getQueryFloat(c, "minAmount"),
+		MaxAmount:   getQueryFloat(c, "maxAmount"),
+		CategoryID:  getQueryInt(c, "categoryId"),
+		StartDate:   getQueryString(c, "startDate"),
+		EndDate:     getQueryString(c, "endDate"),
+		Tags:        hlpFeatureOne.NormalizeTags(hlpFeatureOne.SplitTagList(c.Query("tags"))),
+		TagMatch:    strings.ToLower(c.Query("tagMatch")),
+		SortBy:      c.Query("sortBy"),
+		Order:       strings.ToLower(c.Query("order")),
+		Limit:       getQueryIntDefault(c, "limit", 50),
+		Offset:      getQueryIntDefault(c, "offset", 0),
+		CursorToken: c.Query("cursor"),
+		Filter:      strings.TrimSpace(c.Query("filter")),
+	}
+
+	// Full-text search with prefix matching for search-as-you-type
+	if filters.Q != nil {
+		if query, ok := hlpFeatureOne.BuildSearchQuery(*filters.Q); ok {
+			filters.SearchQuery = &query
+		}
+	}
+	return filters
+}
+
 // getQueryString gets string query parameter
 func getQueryString(c fiber.Ctx, key string) *string {
 	val := c.Query(key)
@@ -13,6 +13,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
 	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
 	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
 )
@@ -30,8 +31,9 @@ func BatchUpdateExpenses(c fiber.Ctx) error {
 			"Unauthorized", nil, http.StatusUnauthorized)
 	}
 
-	var updates []mdlFeatureOne.BatchUpdateItem
-	if err := c.Bind().Body(&updates); err != nil {
+	// Items follow merge patch rules, so null clears a field
+	updates, err := hlpFeatureOne.ParseBatchUpdateItems(c.Body())
+	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			"Invalid request body", err, http.StatusBadRequest)
 	}
@@ -75,18 +77,65 @@ func BatchUpdateExpenses(c fiber.Ctx) error {
 			continue
 		}
 
-		// Build update request
+		if scpFeatureOne.ExpenseLocked(update.ExpenseID) {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Message:   "Expense is locked by a submitted reimbursement report",
+				Success:   false,
+			})
+			continue
+		}
+
+		if err := hlpFeatureOne.ValidateBatchUpdateItem(&update); err != nil {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Message:   err.Error(),
+				Success:   false,
+			})
+			continue
+		}
+
+		if update.AccountID != nil && !scpFeatureOne.AccountExists(userID, *update.AccountID) {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Message:   "Account not found",
+				Success:   false,
+			})
+			continue
+		}
+
+		// Build update request; rules fill in what the update leaves empty
 		req := &mdlFeatureOne.UpdateExpenseRequest{
 			Title:      update.Title,
 			Amount:     update.Amount,
 			CategoryID: update.CategoryID,
+			Merchant:   update.Merchant,
+			AccountID:  update.AccountID,
 			Date:       update.Date,
 			Notes:      update.Notes,
+			Tags:       update.Tags,
+			Clear:      update.Clear,
+			ApplyRules: true,
 		}
 
 		// Attempt update
-		_, err := scpFeatureOne.UpdateExpense(userID, update.ExpenseID, req)
-		if err != nil {
+		updated, err := scpFeatureOne.UpdateExpense(userID, update.ExpenseID, req, update.Version,
+			mdlFeatureOne.RevisionSource{Type: mdlFeatureOne.RevisionSourceBatch})
+		if err == nil && updated == nil {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Message:   "Expense was modified since the expected version",
+				Success:   false,
+			})
+		} else if err != nil {
 			failCount++
 			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
 				Index:     i,
@@ -124,8 +173,9 @@ func BatchUpdateExpensesAsync(c fiber.Ctx) error {
 			"Unauthorized", nil, http.StatusUnauthorized)
 	}
 
-	var updates []mdlFeatureOne.BatchUpdateItem
-	if err := c.Bind().Body(&updates); err != nil {
+	// Items follow merge patch rules, so null clears a field
+	updates, err := hlpFeatureOne.ParseBatchUpdateItems(c.Body())
+	if err != nil {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			"Invalid request body", err, http.StatusBadRequest)
 	}
@@ -165,7 +215,9 @@ func BatchUpdateExpensesAsync(c fiber.Ctx) error {
 // BATCH UPLOAD ENDPOINTS
 // ============================================
 
-// BatchUploadExpensesFromCSV uploads expenses from CSV file
+// BatchUploadExpensesFromCSV uploads expenses from CSV file. Rows that look like
+// existing expenses are marked as duplicates in the job results; ?force=true
+// skips the check.
 func BatchUploadExpensesFromCSV(c fiber.Ctx) error {
 	userID := utils.GetUserId(c)
 	if userID == 0 {
@@ -173,6 +225,12 @@ func BatchUploadExpensesFromCSV(c fiber.Ctx) error {
 			"Unauthorized", nil, http.StatusUnauthorized)
 	}
 
+	force, err := parseForce(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"force must be true or false", err, http.StatusBadRequest)
+	}
+
 	// Parse uploaded CSV file
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -200,17 +258,17 @@ func BatchUploadExpensesFromCSV(c fiber.Ctx) error {
 			"CSV must contain header and at least one row", nil, http.StatusBadRequest)
 	}
 
-	// Validate CSV headers
-	expectedHeaders := []string{"title", "amount", "categoryid", "date", "notes"}
+	// Validate CSV headers (the trailing "tags" and "merchant" columns are optional)
+	expectedHeaders := hlpFeatureOne.CSVImportHeaders
 	headers := records[0]
 
-	if len(headers) != len(expectedHeaders) {
+	if len(headers) < len(expectedHeaders)-2 || len(headers) > len(expectedHeaders) {
 		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 			fmt.Sprintf("Invalid CSV header count. Expected headers: %v", expectedHeaders),
 			nil, http.StatusBadRequest)
 	}
 
-	for i, expected := range expectedHeaders {
+	for i, expected := range expectedHeaders[:len(headers)] {
 		if strings.ToLower(strings.TrimSpace(headers[i])) != expected {
 			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
 				fmt.Sprintf("Invalid CSV header at column %d: expected '%s', got '%s'",
@@ -227,6 +285,13 @@ func BatchUploadExpensesFromCSV(c fiber.Ctx) error {
 				fmt.Sprintf("Row %d has mismatched columns", i+2), nil, http.StatusBadRequest)
 		}
 
+		// Exports quote formula-like text; take the quote off again
+		for _, col := range []int{0, 4, 5, 6} {
+			if col < len(row) {
+				row[col] = hlpFeatureOne.UnescapeCSVFormula(row[col])
+			}
+		}
+
 		// Parse amount
 		amount, err := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
 		if err != nil {
@@ -252,12 +317,35 @@ func BatchUploadExpensesFromCSV(c fiber.Ctx) error {
 			notes = &n
 		}
 
+		// Parse tags (optional, separated by commas or semicolons)
+		var tags []string
+		if len(row) > 5 {
+			tags = hlpFeatureOne.SplitTagList(row[5])
+			if err := hlpFeatureOne.ValidateTags(tags); err != nil {
+				return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+					fmt.Sprintf("Invalid tags at row %d: %s", i+2, err.Error()), nil, http.StatusBadRequest)
+			}
+		}
+
+		// Parse merchant (optional)
+		var merchant *string
+		if len(row) > 6 && strings.TrimSpace(row[6]) != "" {
+			if err := hlpFeatureOne.ValidateMerchantName(row[6]); err != nil {
+				return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+					fmt.Sprintf("Invalid merchant at row %d: %s", i+2, err.Error()), nil, http.StatusBadRequest)
+			}
+			m := hlpFeatureOne.NormalizeMerchantName(row[6])
+			merchant = &m
+		}
+
 		expense := mdlFeatureOne.CSVExpenseRow{
 			Title:      strings.TrimSpace(row[0]),
 			Amount:     amount,
 			CategoryID: categoryID,
+			Merchant:   merchant,
 			Date:       strings.TrimSpace(row[3]),
 			Notes:      notes,
+			Tags:       tags,
 		}
 		expenses = append(expenses, expense)
 	}
@@ -275,7 +363,7 @@ func BatchUploadExpensesFromCSV(c fiber.Ctx) error {
 	}
 
 	// Process in background
-	go scpFeatureOne.ProcessBatchUpload(jobID, userID, expenses)
+	go scpFeatureOne.ProcessBatchUpload(jobID, userID, expenses, force)
 
 	// Return job info immediately
 	response := mdlFeatureOne.BatchJobCreatedResponse{
@@ -0,0 +1,147 @@
+package ctrFeatureOne
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// MERCHANT ENDPOINTS
+// ============================================
+
+// CreateMerchant creates a merchant (or returns the existing merchant with the same name)
+func CreateMerchant(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.MerchantRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate name
+	if err := hlpFeatureOne.ValidateMerchantName(req.Name); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	merchant, err := scpFeatureOne.CreateMerchant(userID, req.Name)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create merchant", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Merchant created successfully", merchant, http.StatusCreated)
+}
+
+// GetMerchants retrieves the user's merchants with usage counts
+func GetMerchants(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	merchants, err := scpFeatureOne.GetMerchants(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve merchants", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Merchants retrieved successfully", merchants, http.StatusOK)
+}
+
+// RenameMerchant renames a merchant; the new name must not belong to another merchant
+func RenameMerchant(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	merchantID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid merchant ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.MerchantRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate name
+	if err := hlpFeatureOne.ValidateMerchantName(req.Name); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+	name := hlpFeatureOne.NormalizeMerchantName(req.Name)
+
+	if !scpFeatureOne.MerchantExists(userID, merchantID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Merchant not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.MerchantNameTaken(userID, name, merchantID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"A merchant with this name already exists", nil, http.StatusConflict)
+	}
+
+	merchant, err := scpFeatureOne.RenameMerchant(userID, merchantID, name)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to rename merchant", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Merchant renamed successfully", merchant, http.StatusOK)
+}
+
+// DeleteMerchant deletes a merchant; its expenses are kept without one
+func DeleteMerchant(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	merchantID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid merchant ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.MerchantExists(userID, merchantID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Merchant not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteMerchant(userID, merchantID); err != nil {
+		if errors.Is(err, scpFeatureOne.ErrExpenseLocked) {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+				"Merchant is used by an expense locked by a submitted reimbursement report", nil, http.StatusConflict)
+		}
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete merchant", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Merchant deleted successfully", nil, http.StatusOK)
+}
@@ -0,0 +1,101 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// NOTIFICATION ENDPOINTS
+// ============================================
+
+// GetNotifications retrieves the user's in-app notifications
+func GetNotifications(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters := &mdlFeatureOne.NotificationFilters{
+		Limit:  getQueryIntDefault(c, "limit", 50),
+		Offset: getQueryIntDefault(c, "offset", 0),
+	}
+	if unread := c.Query("unread"); unread != "" {
+		unreadOnly, err := strconv.ParseBool(unread)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"unread must be true or false", err, http.StatusBadRequest)
+		}
+		filters.UnreadOnly = unreadOnly
+	}
+
+	// Validate limit
+	if filters.Limit < 1 || filters.Limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetNotifications(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve notifications", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Notifications retrieved successfully", result, http.StatusOK)
+}
+
+// MarkNotificationRead marks a single notification as read
+func MarkNotificationRead(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	notificationID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid notification ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.NotificationExists(userID, notificationID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Notification not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.MarkNotificationRead(userID, notificationID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update notification", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Notification marked as read", nil, http.StatusOK)
+}
+
+// MarkAllNotificationsRead marks every unread notification as read
+func MarkAllNotificationsRead(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	updated, err := scpFeatureOne.MarkAllNotificationsRead(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update notifications", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Notifications marked as read", map[string]interface{}{"updated": updated}, http.StatusOK)
+}
@@ -0,0 +1,385 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// RECURRING EXPENSE ENDPOINTS
+// ============================================
+
+// CreateRecurringExpense creates a new recurring expense rule
+func CreateRecurringExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CreateRecurringExpenseRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if req.Interval == 0 {
+		req.Interval = 1
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateCreateRecurringExpense(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	recurrence, _ := hlpFeatureOne.BuildRecurrence(req.Frequency, req.Interval, req.DayOfMonth,
+		req.RRule, req.StartDate, req.EndDate)
+	nextRunDate := hlpFeatureOne.NextRunDate(recurrence, recurrence.Start)
+
+	// Create rule
+	rule, err := scpFeatureOne.CreateRecurringExpense(userID, &req, nextRunDate)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create recurring expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Recurring expense created successfully", rule, http.StatusCreated)
+}
+
+// GetRecurringExpenses retrieves the user's recurring expense rules
+func GetRecurringExpenses(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters := &mdlFeatureOne.RecurringExpenseFilters{
+		Limit:  getQueryIntDefault(c, "limit", 50),
+		Offset: getQueryIntDefault(c, "offset", 0),
+	}
+	if paused := c.Query("paused"); paused != "" {
+		isPaused, err := strconv.ParseBool(paused)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"paused must be true or false", err, http.StatusBadRequest)
+		}
+		filters.IsPaused = &isPaused
+	}
+
+	// Validate limit
+	if filters.Limit < 1 || filters.Limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetRecurringExpenses(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve recurring expenses", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Recurring expenses retrieved successfully", result, http.StatusOK)
+}
+
+// GetRecurringExpense retrieves a single recurring expense rule
+func GetRecurringExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid recurring expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.RecurringExpenseExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Recurring expense not found", nil, http.StatusNotFound)
+	}
+
+	rule, err := scpFeatureOne.GetRecurringExpenseByID(userID, ruleID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve recurring expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Recurring expense retrieved successfully", rule, http.StatusOK)
+}
+
+// UpdateRecurringExpense edits a recurring expense rule
+func UpdateRecurringExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid recurring expense ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.UpdateRecurringExpenseRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.RecurringExpenseExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Recurring expense not found", nil, http.StatusNotFound)
+	}
+
+	rule, err := scpFeatureOne.GetRecurringExpenseByID(userID, ruleID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve recurring expense", err, http.StatusInternalServerError)
+	}
+
+	// Merge provided fields into the stored rule
+	merged := mdlFeatureOne.CreateRecurringExpenseRequest{
+		Title:      rule.Title,
+		Amount:     rule.Amount,
+		CategoryID: rule.CategoryID,
+		Notes:      rule.Notes,
+		Frequency:  rule.Frequency,
+		Interval:   rule.Interval,
+		DayOfMonth: rule.DayOfMonth,
+		RRule:      rule.RRule,
+		StartDate:  rule.StartDate,
+		EndDate:    rule.EndDate,
+	}
+	if req.Title != nil {
+		merged.Title = *req.Title
+	}
+	if req.Amount != nil {
+		merged.Amount = *req.Amount
+	}
+	if req.CategoryID != nil {
+		merged.CategoryID = req.CategoryID
+	}
+	if req.Notes != nil {
+		merged.Notes = req.Notes
+	}
+	if req.Frequency != nil {
+		merged.Frequency = *req.Frequency
+	}
+	if req.Interval != nil {
+		merged.Interval = *req.Interval
+	}
+	if req.DayOfMonth != nil {
+		merged.DayOfMonth = req.DayOfMonth
+	}
+	if req.RRule != nil {
+		merged.RRule = req.RRule
+	}
+	if req.StartDate != nil {
+		merged.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		merged.EndDate = req.EndDate
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateCreateRecurringExpense(&merged); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	// Never reschedule dates that were already materialised
+	recurrence, _ := hlpFeatureOne.BuildRecurrence(merged.Frequency, merged.Interval, merged.DayOfMonth,
+		merged.RRule, merged.StartDate, merged.EndDate)
+	notBefore := recurrence.Start
+	if rule.LastRunDate != nil {
+		if lastRun, err := time.Parse("2006-01-02", *rule.LastRunDate); err == nil && !lastRun.Before(notBefore) {
+			notBefore = lastRun.AddDate(0, 0, 1)
+		}
+	}
+
+	rule.Title = merged.Title
+	rule.Amount = merged.Amount
+	rule.CategoryID = merged.CategoryID
+	rule.Notes = merged.Notes
+	rule.Frequency = merged.Frequency
+	rule.Interval = merged.Interval
+	rule.DayOfMonth = merged.DayOfMonth
+	rule.RRule = merged.RRule
+	rule.StartDate = merged.StartDate
+	rule.EndDate = merged.EndDate
+	rule.NextRunDate = hlpFeatureOne.NextRunDate(recurrence, notBefore)
+
+	updated, err := scpFeatureOne.UpdateRecurringExpense(userID, ruleID, rule)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update recurring expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Recurring expense updated successfully", updated, http.StatusOK)
+}
+
+// PauseRecurringExpense stops a rule from creating expenses until it is resumed
+func PauseRecurringExpense(c fiber.Ctx) error {
+	return setRecurringExpensePaused(c, true)
+}
+
+// ResumeRecurringExpense resumes a paused rule; occurrences missed while paused are skipped
+func ResumeRecurringExpense(c fiber.Ctx) error {
+	return setRecurringExpensePaused(c, false)
+}
+
+func setRecurringExpensePaused(c fiber.Ctx, paused bool) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid recurring expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.RecurringExpenseExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Recurring expense not found", nil, http.StatusNotFound)
+	}
+
+	rule, err := scpFeatureOne.GetRecurringExpenseByID(userID, ruleID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve recurring expense", err, http.StatusInternalServerError)
+	}
+
+	nextRunDate := rule.NextRunDate
+	if !paused && rule.IsPaused {
+		recurrence, err := hlpFeatureOne.BuildRecurrence(rule.Frequency, rule.Interval, rule.DayOfMonth,
+			rule.RRule, rule.StartDate, rule.EndDate)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+				"Stored recurrence is invalid", err, http.StatusInternalServerError)
+		}
+		today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+		nextRunDate = hlpFeatureOne.NextRunDate(recurrence, today)
+	}
+
+	updated, err := scpFeatureOne.SetRecurringExpensePaused(userID, ruleID, paused, nextRunDate)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update recurring expense", err, http.StatusInternalServerError)
+	}
+
+	message := "Recurring expense resumed successfully"
+	if paused {
+		message = "Recurring expense paused successfully"
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		message, updated, http.StatusOK)
+}
+
+// DeleteRecurringExpense soft deletes a recurring expense rule
+func DeleteRecurringExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid recurring expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.RecurringExpenseExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Recurring expense not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteRecurringExpense(userID, ruleID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete recurring expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Recurring expense deleted successfully", nil, http.StatusOK)
+}
+
+// PreviewRecurringExpense lists the upcoming occurrences of a rule
+func PreviewRecurringExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid recurring expense ID", err, http.StatusBadRequest)
+	}
+
+	count := getQueryIntDefault(c, "count", 10)
+	if count < 1 || count > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Count must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.RecurringExpenseExists(userID, ruleID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Recurring expense not found", nil, http.StatusNotFound)
+	}
+
+	rule, err := scpFeatureOne.GetRecurringExpenseByID(userID, ruleID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve recurring expense", err, http.StatusInternalServerError)
+	}
+
+	recurrence, err := hlpFeatureOne.BuildRecurrence(rule.Frequency, rule.Interval, rule.DayOfMonth,
+		rule.RRule, rule.StartDate, rule.EndDate)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Stored recurrence is invalid", err, http.StatusInternalServerError)
+	}
+
+	// Preview from the next pending run, or from today for paused rules
+	from, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	if rule.NextRunDate != nil && !rule.IsPaused {
+		if next, err := time.Parse("2006-01-02", *rule.NextRunDate); err == nil {
+			from = next
+		}
+	}
+
+	response := mdlFeatureOne.RecurringExpensePreviewResponse{
+		RuleID:      rule.ID,
+		Occurrences: []string{},
+	}
+	for _, d := range recurrence.Upcoming(from, count) {
+		response.Occurrences = append(response.Occurrences, d.Format("2006-01-02"))
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Recurring expense preview retrieved successfully", response, http.StatusOK)
+}
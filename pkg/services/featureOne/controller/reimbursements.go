@@ -0,0 +1,333 @@
+package ctrFeatureOne
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// REIMBURSEMENT REPORT ENDPOINTS
+// ============================================
+
+// CreateReimbursementReport creates a draft report claiming some of the user's expenses
+func CreateReimbursementReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.ReimbursementReportRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate report
+	if err := hlpFeatureOne.ValidateReimbursementReport(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	approverID, err := scpFeatureOne.ResolveReimbursementApprover(userID, req.ApproverID, req.ApproverEmail)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	report, invalidIDs, err := scpFeatureOne.CreateReimbursementReport(userID, &req, approverID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create reimbursement report", err, http.StatusInternalServerError)
+	}
+	if len(invalidIDs) > 0 {
+		return invalidReimbursementExpenses(c, invalidIDs)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Reimbursement report created successfully", report, http.StatusCreated)
+}
+
+// GetReimbursementReports retrieves the reports the user owns, or with ?role=approver
+// the submitted reports waiting on or decided by them; ?status= narrows either list
+func GetReimbursementReports(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters := &mdlFeatureOne.ReimbursementReportFilters{
+		Role:   c.Query("role"),
+		Status: getQueryString(c, "status"),
+	}
+	if err := hlpFeatureOne.ValidateReimbursementReportFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	reports, err := scpFeatureOne.GetReimbursementReports(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve reimbursement reports", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Reimbursement reports retrieved successfully", reports, http.StatusOK)
+}
+
+// GetReimbursementReport retrieves a report with its expenses and status history
+func GetReimbursementReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	reportID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid report ID", err, http.StatusBadRequest)
+	}
+
+	if scpFeatureOne.GetReimbursementReportRole(userID, reportID) == "" {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Reimbursement report not found", nil, http.StatusNotFound)
+	}
+
+	report, err := scpFeatureOne.GetReimbursementReportByID(userID, reportID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve reimbursement report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Reimbursement report retrieved successfully", report, http.StatusOK)
+}
+
+// UpdateReimbursementReport replaces a draft report; a rejected report is reopened
+// as a draft first through its transitions
+func UpdateReimbursementReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	reportID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid report ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.ReimbursementReportRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate report
+	if err := hlpFeatureOne.ValidateReimbursementReport(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	report, ok, resp := getOwnedReimbursementReport(c, userID, reportID)
+	if !ok {
+		return resp
+	}
+	if report.Status != mdlFeatureOne.ReimbursementDraft {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			fmt.Sprintf("Only draft reports can be edited; this report is %s", report.Status), nil, http.StatusConflict)
+	}
+
+	approverID, err := scpFeatureOne.ResolveReimbursementApprover(userID, req.ApproverID, req.ApproverEmail)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	updated, invalidIDs, err := scpFeatureOne.UpdateReimbursementReport(userID, reportID, &req, approverID)
+	if errors.Is(err, scpFeatureOne.ErrReimbursementStatusChanged) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Reimbursement report was changed by another request", nil, http.StatusConflict)
+	}
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update reimbursement report", err, http.StatusInternalServerError)
+	}
+	if len(invalidIDs) > 0 {
+		return invalidReimbursementExpenses(c, invalidIDs)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Reimbursement report updated successfully", updated, http.StatusOK)
+}
+
+// DeleteReimbursementReport deletes a draft or rejected report; its expenses are kept
+func DeleteReimbursementReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	reportID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid report ID", err, http.StatusBadRequest)
+	}
+
+	report, ok, resp := getOwnedReimbursementReport(c, userID, reportID)
+	if !ok {
+		return resp
+	}
+	if !hlpFeatureOne.ReimbursementReportDeletable(report.Status) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			fmt.Sprintf("Only draft or rejected reports can be deleted; this report is %s", report.Status), nil, http.StatusConflict)
+	}
+
+	err = scpFeatureOne.DeleteReimbursementReport(userID, reportID)
+	if errors.Is(err, scpFeatureOne.ErrReimbursementStatusChanged) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Reimbursement report was changed by another request", nil, http.StatusConflict)
+	}
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete reimbursement report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Reimbursement report deleted successfully", nil, http.StatusOK)
+}
+
+// TransitionReimbursementReport moves a report to a new status with an optional
+// comment. The owner submits, recalls a submitted report and reopens a rejected
+// one; the approver approves or rejects a submitted report and marks it paid.
+func TransitionReimbursementReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	reportID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid report ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.ReimbursementTransitionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate transition
+	if err := hlpFeatureOne.ValidateReimbursementTransition(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	role := scpFeatureOne.GetReimbursementReportRole(userID, reportID)
+	if role == "" {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Reimbursement report not found", nil, http.StatusNotFound)
+	}
+
+	report, err := scpFeatureOne.GetReimbursementReportByID(userID, reportID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve reimbursement report", err, http.StatusInternalServerError)
+	}
+
+	allowedRole, ok := hlpFeatureOne.ReimbursementTransitionRole(report.Status, req.Status)
+	if !ok {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			fmt.Sprintf("A %s report cannot be moved to %s", report.Status, req.Status), nil, http.StatusConflict)
+	}
+	if allowedRole != role {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_403,
+			fmt.Sprintf("Only the report's %s can move it from %s to %s", allowedRole, report.Status, req.Status),
+			nil, http.StatusForbidden)
+	}
+
+	if req.Status == mdlFeatureOne.ReimbursementSubmitted {
+		if report.Approver == nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Assign an approver before submitting the report", nil, http.StatusBadRequest)
+		}
+		if report.ExpenseCount == 0 {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Add at least one expense before submitting the report", nil, http.StatusBadRequest)
+		}
+	}
+
+	updated, err := scpFeatureOne.TransitionReimbursementReport(userID, reportID, report.Status, req.Status, req.Comment)
+	if errors.Is(err, scpFeatureOne.ErrReimbursementStatusChanged) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Reimbursement report was changed by another request", nil, http.StatusConflict)
+	}
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update reimbursement report status", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		fmt.Sprintf("Reimbursement report %s", reimbursementStatusVerb(req.Status, report.Status)), updated, http.StatusOK)
+}
+
+// ============================================
+// HELPER FUNCTIONS
+// ============================================
+
+// getOwnedReimbursementReport loads a report only its owner may change. When it
+// returns false the request has already been answered with the returned error.
+func getOwnedReimbursementReport(c fiber.Ctx, userID, reportID int) (*mdlFeatureOne.ReimbursementReportDetailResponse, bool, error) {
+	switch scpFeatureOne.GetReimbursementReportRole(userID, reportID) {
+	case "":
+		return nil, false, v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Reimbursement report not found", nil, http.StatusNotFound)
+	case mdlFeatureOne.ReimbursementRoleApprover:
+		return nil, false, v1.JSONResponseWithError(c, respcode.ERR_CODE_403,
+			"Only the report's owner can change it", nil, http.StatusForbidden)
+	}
+
+	report, err := scpFeatureOne.GetReimbursementReportByID(userID, reportID)
+	if err != nil {
+		return nil, false, v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve reimbursement report", err, http.StatusInternalServerError)
+	}
+	return report, true, nil
+}
+
+// invalidReimbursementExpenses answers a report whose expenses cannot all be claimed
+func invalidReimbursementExpenses(c fiber.Ctx, invalidIDs []int) error {
+	return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+		fmt.Sprintf("Expenses %v are not found, are deleted or are already in another report", invalidIDs),
+		nil, http.StatusBadRequest)
+}
+
+// reimbursementStatusVerb describes a transition for the response message
+func reimbursementStatusVerb(to, from string) string {
+	switch {
+	case to == mdlFeatureOne.ReimbursementDraft && from == mdlFeatureOne.ReimbursementSubmitted:
+		return "recalled"
+	case to == mdlFeatureOne.ReimbursementDraft:
+		return "reopened"
+	case to == mdlFeatureOne.ReimbursementPaid:
+		return "marked as paid"
+	}
+	return to
+}
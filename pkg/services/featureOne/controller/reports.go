@@ -0,0 +1,166 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// REPORT ENDPOINTS
+// ============================================
+
+// parseReportFilters reads the expense filters and resolves the report period
+func parseReportFilters(c fiber.Ctx) (*mdlFeatureOne.ExpenseFilters, *mdlFeatureOne.ReportPeriod, error) {
+	filters := parseExpenseFilters(c)
+	if err := hlpFeatureOne.ValidateExpenseFilters(filters); err != nil {
+		return nil, nil, err
+	}
+
+	period, err := hlpFeatureOne.ApplyReportPeriod(filters, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return filters, period, nil
+}
+
+// GetReportSummary returns totals, averages and a comparison with the previous period
+func GetReportSummary(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters, _, err := parseReportFilters(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetReportSummary(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to build report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Report retrieved successfully", result, http.StatusOK)
+}
+
+// GetCategoryReport returns spending totals per category
+func GetCategoryReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters, period, err := parseReportFilters(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetCategoryReport(userID, filters, period)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to build report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Report retrieved successfully", result, http.StatusOK)
+}
+
+// GetTimeReport returns spending totals per ?interval= day, week, month or year (default month)
+func GetTimeReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	interval := strings.ToLower(c.Query("interval", "month"))
+	if !hlpFeatureOne.ValidReportInterval(interval) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Interval must be one of day, week, month or year", nil, http.StatusBadRequest)
+	}
+
+	filters, period, err := parseReportFilters(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetTimeReport(userID, filters, period, interval)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to build report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Report retrieved successfully", result, http.StatusOK)
+}
+
+// GetTagReport returns spending totals per tag
+func GetTagReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters, period, err := parseReportFilters(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetTagReport(userID, filters, period)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to build report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Report retrieved successfully", result, http.StatusOK)
+}
+
+// GetTitleReport returns the ?limit= (default 10) titles with the highest spending
+func GetTitleReport(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	limit := getQueryIntDefault(c, "limit", 10)
+	if limit < 1 || limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+
+	filters, period, err := parseReportFilters(c)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetTitleReport(userID, filters, period, limit)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to build report", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Report retrieved successfully", result, http.StatusOK)
+}
@@ -0,0 +1,349 @@
+package ctrFeatureOne
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// SPLIT ENDPOINTS
+// ============================================
+
+// SetExpenseSplit splits an expense between its owner and other users, replacing any earlier split
+func SetExpenseSplit(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.SetSplitRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	expense, err := scpFeatureOne.GetExpenseByID(userID, expenseID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve expense", err, http.StatusInternalServerError)
+	}
+
+	// Validate split against the expense amount
+	if err := hlpFeatureOne.ValidateSetSplit(&req, expense.Amount); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	userIDs, err := scpFeatureOne.ResolveSplitUserIDs(req.Participants)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+	if err := hlpFeatureOne.ValidateSplitParticipants(userID, userIDs); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	split, err := scpFeatureOne.SetExpenseSplit(userID, expenseID, req.Method, userIDs, hlpFeatureOne.SplitWeights(&req))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to split expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Expense split successfully", split, http.StatusOK)
+}
+
+// GetExpenseSplit retrieves how an expense is split
+func GetExpenseSplit(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	split, err := scpFeatureOne.GetExpenseSplit(userID, expenseID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve split", err, http.StatusInternalServerError)
+	}
+	if split == nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense is not split", nil, http.StatusNotFound)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Split retrieved successfully", split, http.StatusOK)
+}
+
+// DeleteExpenseSplit makes an expense the owner's alone again
+func DeleteExpenseSplit(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.ExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found", nil, http.StatusNotFound)
+	}
+
+	deleted, err := scpFeatureOne.DeleteExpenseSplit(userID, expenseID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to remove split", err, http.StatusInternalServerError)
+	}
+	if !deleted {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense is not split", nil, http.StatusNotFound)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Split removed successfully", map[string]interface{}{"expenseId": expenseID}, http.StatusOK)
+}
+
+// ============================================
+// BALANCE ENDPOINTS
+// ============================================
+
+// GetBalances retrieves what the user owes and is owed by every other user
+func GetBalances(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	balances, err := scpFeatureOne.GetBalances(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve balances", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Balances retrieved successfully", balances, http.StatusOK)
+}
+
+// GetBalanceWithUser retrieves the balance with one user and the splits and settlements behind it
+func GetBalanceWithUser(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	otherID, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid user ID", err, http.StatusBadRequest)
+	}
+	if otherID == userID {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Cannot retrieve a balance with yourself", nil, http.StatusBadRequest)
+	}
+
+	limit := getQueryIntDefault(c, "limit", 50)
+	offset := getQueryIntDefault(c, "offset", 0)
+	if limit < 1 || limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+	if offset < 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Offset must not be negative", nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.UserExists(otherID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"User not found", nil, http.StatusNotFound)
+	}
+
+	balance, err := scpFeatureOne.GetBalanceWithUser(userID, otherID, limit, offset)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve balance", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Balance retrieved successfully", balance, http.StatusOK)
+}
+
+// ============================================
+// SETTLEMENT ENDPOINTS
+// ============================================
+
+// CreateSettlement records a payment that settles (part of) a balance
+func CreateSettlement(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CreateSettlementRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate settlement
+	if err := hlpFeatureOne.ValidateCreateSettlement(&req, userID, time.Now()); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	otherID := req.PayerID
+	if otherID == userID {
+		otherID = req.PayeeID
+	}
+	if !scpFeatureOne.UserExists(otherID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"User not found", nil, http.StatusNotFound)
+	}
+
+	// Without an amount, settle everything the payer owes the payee
+	var amount float64
+	if req.Amount != nil {
+		amount = math.Round(*req.Amount*100) / 100
+	} else {
+		owed, err := scpFeatureOne.GetPairBalance(req.PayeeID, req.PayerID)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+				"Failed to retrieve balance", err, http.StatusInternalServerError)
+		}
+		if owed <= 0 {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"The payer owes the payee nothing; pass an amount to record a payment anyway", nil, http.StatusBadRequest)
+		}
+		amount = owed
+	}
+
+	settlement, err := scpFeatureOne.CreateSettlement(userID, &req, amount)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to record settlement", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Settlement recorded successfully", settlement, http.StatusCreated)
+}
+
+// GetSettlements retrieves the settlement history, optionally with a single user (?userId=)
+func GetSettlements(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters := &mdlFeatureOne.SettlementFilters{
+		Limit:  getQueryIntDefault(c, "limit", 50),
+		Offset: getQueryIntDefault(c, "offset", 0),
+	}
+	if other := c.Query("userId"); other != "" {
+		otherID, err := strconv.Atoi(other)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Invalid user ID", err, http.StatusBadRequest)
+		}
+		filters.UserID = &otherID
+	}
+
+	// Validate limit
+	if filters.Limit < 1 || filters.Limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+	if filters.Offset < 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Offset must not be negative", nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetSettlements(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve settlements", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Settlements retrieved successfully", result, http.StatusOK)
+}
+
+// DeleteSettlement removes a settlement; only the user who recorded it may do so
+func DeleteSettlement(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	settlementID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid settlement ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.SettlementExists(userID, settlementID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Settlement not found", nil, http.StatusNotFound)
+	}
+
+	settlement, err := scpFeatureOne.GetSettlementByID(userID, settlementID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve settlement", err, http.StatusInternalServerError)
+	}
+	if settlement.CreatedBy != userID {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_403,
+			"Only the user who recorded a settlement can delete it", nil, http.StatusForbidden)
+	}
+
+	if err := scpFeatureOne.DeleteSettlement(userID, settlementID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete settlement", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Settlement deleted successfully", map[string]interface{}{"id": settlementID}, http.StatusOK)
+}
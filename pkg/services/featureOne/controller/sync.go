@@ -0,0 +1,80 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// SYNC ENDPOINTS
+// ============================================
+
+// GetSync returns the expenses, categories and tags changed since ?since=, with
+// tombstones for deleted ones. Without a token it returns everything; the
+// returned token is passed as ?since= on the next call.
+func GetSync(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	filters := &mdlFeatureOne.SyncFilters{
+		Limit: getQueryIntDefault(c, "limit", hlpFeatureOne.DefaultSyncLimit),
+	}
+	if since := c.Query("since"); since != "" {
+		seq, err := hlpFeatureOne.DecodeSyncToken(since)
+		if err != nil {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				err.Error(), nil, http.StatusBadRequest)
+		}
+		filters.Since = seq
+	}
+	if err := hlpFeatureOne.ValidateSyncFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	changes, err := scpFeatureOne.GetSyncChanges(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve changes", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Changes retrieved successfully", changes, http.StatusOK)
+}
+
+// PushSync applies a batch of changes made on a client. Each change reports
+// whether it was applied, conflicts with a newer server version or was rejected.
+func PushSync(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.SyncPushRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if err := hlpFeatureOne.ValidateSyncPush(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	result := scpFeatureOne.ApplySyncChanges(userID, req.Changes)
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Changes pushed successfully", result, http.StatusOK)
+}
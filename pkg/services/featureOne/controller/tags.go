@@ -0,0 +1,199 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// TAG ENDPOINTS
+// ============================================
+
+// CreateTag creates a tag (or returns the existing tag with the same name)
+func CreateTag(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CreateTagRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate name
+	if err := hlpFeatureOne.ValidateTagName(req.Name); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	tag, err := scpFeatureOne.CreateTag(userID, strings.ToLower(strings.TrimSpace(req.Name)))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create tag", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Tag created successfully", tag, http.StatusCreated)
+}
+
+// GetTags retrieves the user's tags with usage counts
+func GetTags(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	tags, err := scpFeatureOne.GetTags(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve tags", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Tags retrieved successfully", tags, http.StatusOK)
+}
+
+// RenameTag renames a tag; renaming onto an existing name must use merge instead
+func RenameTag(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid tag ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.RenameTagRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate name
+	if err := hlpFeatureOne.ValidateTagName(req.Name); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+
+	if !scpFeatureOne.TagExists(userID, tagID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Tag not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.TagNameTaken(userID, name, tagID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"A tag with this name already exists; merge the tags instead", nil, http.StatusConflict)
+	}
+
+	tag, err := scpFeatureOne.RenameTag(userID, tagID, name)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to rename tag", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Tag renamed successfully", tag, http.StatusOK)
+}
+
+// MergeTag moves every use of a tag onto the target tag and deletes it
+func MergeTag(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid tag ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.MergeTagRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if req.TargetTagID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"targetTagId is required", nil, http.StatusBadRequest)
+	}
+	if req.TargetTagID == tagID {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"A tag cannot be merged into itself", nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.TagExists(userID, tagID) || !scpFeatureOne.TagExists(userID, req.TargetTagID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Tag not found", nil, http.StatusNotFound)
+	}
+
+	moved, err := scpFeatureOne.MergeTag(userID, tagID, req.TargetTagID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to merge tags", err, http.StatusInternalServerError)
+	}
+
+	tag, err := scpFeatureOne.GetTagByID(userID, req.TargetTagID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve tag", err, http.StatusInternalServerError)
+	}
+
+	response := mdlFeatureOne.MergeTagResponse{
+		Tag:         *tag,
+		MovedLinks:  moved,
+		MergedTagID: tagID,
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Tags merged successfully", response, http.StatusOK)
+}
+
+// DeleteTag deletes a tag and removes it from every transaction
+func DeleteTag(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid tag ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.TagExists(userID, tagID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Tag not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteTag(userID, tagID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete tag", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Tag deleted successfully", nil, http.StatusOK)
+}
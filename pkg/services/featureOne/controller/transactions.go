@@ -0,0 +1,221 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// TRANSACTION ENDPOINTS
+// ============================================
+
+// CreateTransaction creates an expense, income or transfer
+func CreateTransaction(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.CreateTransactionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateCreateTransaction(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	transaction, err := scpFeatureOne.CreateTransaction(userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid transaction") {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Invalid category or account for this transaction", err, http.StatusBadRequest)
+		}
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create transaction", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Transaction created successfully", transaction, http.StatusCreated)
+}
+
+// GetTransactions retrieves transactions with the expense filters plus type and account
+func GetTransactions(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	// Parse query parameters
+	filters := &mdlFeatureOne.TransactionFilters{
+		ExpenseFilters: *parseExpenseFilters(c),
+		Type:           getQueryString(c, "type"),
+		AccountID:      getQueryInt(c, "accountId"),
+	}
+
+	// Validate limit
+	if filters.Limit < 1 || filters.Limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+
+	// Validate filters
+	if err := hlpFeatureOne.ValidateExpenseFilters(&filters.ExpenseFilters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	// Validate type if provided
+	if filters.Type != nil && !hlpFeatureOne.ValidTransactionType(*filters.Type) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Type must be one of expense, income or transfer", nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetTransactions(userID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve transactions", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Transactions retrieved successfully", result, http.StatusOK)
+}
+
+// GetTransaction retrieves a single transaction by ID
+func GetTransaction(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	transactionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid transaction ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.TransactionExists(userID, transactionID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Transaction not found", nil, http.StatusNotFound)
+	}
+
+	transaction, err := scpFeatureOne.GetTransactionByID(userID, transactionID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve transaction", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Transaction retrieved successfully", transaction, http.StatusOK)
+}
+
+// UpdateTransaction updates an existing transaction
+func UpdateTransaction(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	transactionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid transaction ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.UpdateTransactionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.TransactionExists(userID, transactionID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Transaction not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseLocked(transactionID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Transaction is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	current, err := scpFeatureOne.GetTransactionByID(userID, transactionID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve transaction", err, http.StatusInternalServerError)
+	}
+
+	// Validate
+	if err := hlpFeatureOne.ValidateUpdateTransaction(&req, current); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	transaction, err := scpFeatureOne.UpdateTransaction(userID, transactionID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid transaction") {
+			return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+				"Invalid category or account for this transaction", err, http.StatusBadRequest)
+		}
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update transaction", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Transaction updated successfully", transaction, http.StatusOK)
+}
+
+// DeleteTransaction soft deletes a transaction; its files are removed by the trash purge job
+func DeleteTransaction(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	transactionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid transaction ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.TransactionExists(userID, transactionID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Transaction not found", nil, http.StatusNotFound)
+	}
+
+	if scpFeatureOne.ExpenseLocked(transactionID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Transaction is locked by a submitted reimbursement report", nil, http.StatusConflict)
+	}
+
+	result, err := scpFeatureOne.DeleteTransaction(userID, transactionID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete transaction", err, http.StatusInternalServerError)
+	}
+
+	if !result.IsDeleted {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete transaction", nil, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Transaction deleted successfully", nil, http.StatusOK)
+}
@@ -0,0 +1,77 @@
+package ctrFeatureOne
+
+import (
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// TRASH ENDPOINTS
+// ============================================
+
+// GetExpenseTrash lists deleted expenses that can still be restored
+func GetExpenseTrash(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	limit := getQueryIntDefault(c, "limit", 50)
+	offset := getQueryIntDefault(c, "offset", 0)
+
+	// Validate limit
+	if limit < 1 || limit > 100 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Limit must be between 1 and 100", nil, http.StatusBadRequest)
+	}
+	if offset < 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Offset must not be negative", nil, http.StatusBadRequest)
+	}
+
+	result, err := scpFeatureOne.GetExpenseTrash(userID, limit, offset)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve trash", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Trash retrieved successfully", result, http.StatusOK)
+}
+
+// RestoreExpense moves a deleted expense out of the trash
+func RestoreExpense(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	expenseID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid expense ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.TrashedExpenseExists(userID, expenseID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Expense not found in trash", nil, http.StatusNotFound)
+	}
+
+	expense, err := scpFeatureOne.RestoreExpense(userID, expenseID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to restore expense", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Expense restored successfully", expense, http.StatusOK)
+}
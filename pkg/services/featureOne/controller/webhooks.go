@@ -0,0 +1,303 @@
+package ctrFeatureOne
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	v1 "github.com/FDSAP-Git-Org/hephaestus/helper/v1"
+	"github.com/FDSAP-Git-Org/hephaestus/respcode"
+	"github.com/gofiber/fiber/v3"
+
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	scpFeatureOne "go_template_v3/pkg/services/featureOne/script"
+)
+
+// ============================================
+// WEBHOOK ENDPOINTS
+// ============================================
+
+// CreateWebhook registers a URL to receive the user's events. The response
+// carries the signing secret, which is not shown again.
+func CreateWebhook(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	var req mdlFeatureOne.WebhookEndpointRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate webhook
+	if err := hlpFeatureOne.ValidateWebhookEndpoint(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	count, err := scpFeatureOne.CountWebhookEndpoints(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create webhook", err, http.StatusInternalServerError)
+	}
+	if count >= hlpFeatureOne.MaxWebhooksPerUser {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			fmt.Sprintf("A user can have at most %d webhooks", hlpFeatureOne.MaxWebhooksPerUser), nil, http.StatusBadRequest)
+	}
+
+	webhook, err := scpFeatureOne.CreateWebhookEndpoint(userID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to create webhook", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_201,
+		"Webhook created successfully; store the secret now, it is not shown again", webhook, http.StatusCreated)
+}
+
+// GetWebhooks retrieves the user's webhooks
+func GetWebhooks(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhooks, err := scpFeatureOne.GetWebhookEndpoints(userID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve webhooks", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhooks retrieved successfully", webhooks, http.StatusOK)
+}
+
+// GetWebhook retrieves a single webhook
+func GetWebhook(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhookID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid webhook ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.WebhookEndpointExists(userID, webhookID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Webhook not found", nil, http.StatusNotFound)
+	}
+
+	webhook, err := scpFeatureOne.GetWebhookEndpointByID(userID, webhookID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve webhook", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhook retrieved successfully", webhook, http.StatusOK)
+}
+
+// UpdateWebhook replaces a webhook's URL, description and events; isActive true
+// re-enables a webhook that was disabled after failed deliveries
+func UpdateWebhook(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhookID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid webhook ID", err, http.StatusBadRequest)
+	}
+
+	var req mdlFeatureOne.WebhookEndpointRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid request body", err, http.StatusBadRequest)
+	}
+
+	// Validate webhook
+	if err := hlpFeatureOne.ValidateWebhookEndpoint(&req); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.WebhookEndpointExists(userID, webhookID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Webhook not found", nil, http.StatusNotFound)
+	}
+
+	webhook, err := scpFeatureOne.UpdateWebhookEndpoint(userID, webhookID, &req)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to update webhook", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhook updated successfully", webhook, http.StatusOK)
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func DeleteWebhook(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhookID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid webhook ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.WebhookEndpointExists(userID, webhookID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Webhook not found", nil, http.StatusNotFound)
+	}
+
+	if err := scpFeatureOne.DeleteWebhookEndpoint(userID, webhookID); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to delete webhook", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhook deleted successfully", nil, http.StatusOK)
+}
+
+// ============================================
+// WEBHOOK DELIVERY LOG ENDPOINTS
+// ============================================
+
+// GetWebhookDeliveries retrieves a webhook's delivery log, newest first;
+// ?status= and ?event= narrow it down
+func GetWebhookDeliveries(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhookID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid webhook ID", err, http.StatusBadRequest)
+	}
+
+	filters := &mdlFeatureOne.WebhookDeliveryFilters{
+		Status: c.Query("status"),
+		Event:  c.Query("event"),
+		Limit:  getQueryIntDefault(c, "limit", 50),
+		Offset: getQueryIntDefault(c, "offset", 0),
+	}
+	if err := hlpFeatureOne.ValidateWebhookDeliveryFilters(filters); err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			err.Error(), nil, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.WebhookEndpointExists(userID, webhookID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Webhook not found", nil, http.StatusNotFound)
+	}
+
+	deliveries, err := scpFeatureOne.GetWebhookDeliveries(userID, webhookID, filters)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve webhook deliveries", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhook deliveries retrieved successfully", deliveries, http.StatusOK)
+}
+
+// GetWebhookDelivery retrieves a single delivery with its payload and last result
+func GetWebhookDelivery(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhookID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid webhook ID", err, http.StatusBadRequest)
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Params("deliveryId"), 10, 64)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid delivery ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.WebhookDeliveryExists(userID, webhookID, deliveryID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Webhook delivery not found", nil, http.StatusNotFound)
+	}
+
+	delivery, err := scpFeatureOne.GetWebhookDeliveryByID(userID, webhookID, deliveryID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to retrieve webhook delivery", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhook delivery retrieved successfully", delivery, http.StatusOK)
+}
+
+// RedeliverWebhook queues a delivery to be sent again with the same event ID
+func RedeliverWebhook(c fiber.Ctx) error {
+	userID := utils.GetUserId(c)
+	if userID == 0 {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_401,
+			"Unauthorized", nil, http.StatusUnauthorized)
+	}
+
+	webhookID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid webhook ID", err, http.StatusBadRequest)
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Params("deliveryId"), 10, 64)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_400,
+			"Invalid delivery ID", err, http.StatusBadRequest)
+	}
+
+	if !scpFeatureOne.WebhookDeliveryExists(userID, webhookID, deliveryID) {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_404,
+			"Webhook delivery not found", nil, http.StatusNotFound)
+	}
+
+	webhook, err := scpFeatureOne.GetWebhookEndpointByID(userID, webhookID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to redeliver webhook", err, http.StatusInternalServerError)
+	}
+	if !webhook.IsActive {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_409,
+			"Webhook is disabled; enable it before redelivering", nil, http.StatusConflict)
+	}
+
+	delivery, err := scpFeatureOne.RedeliverWebhook(userID, webhookID, deliveryID)
+	if err != nil {
+		return v1.JSONResponseWithError(c, respcode.ERR_CODE_500,
+			"Failed to redeliver webhook", err, http.StatusInternalServerError)
+	}
+
+	return v1.JSONResponseWithData(c, respcode.SUC_CODE_200,
+		"Webhook delivery queued for redelivery", delivery, http.StatusOK)
+}
@@ -0,0 +1,148 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxAccountName         = 100
+	defaultAccountType     = "other"
+	defaultAccountCurrency = "PHP"
+
+	// maxBalancePoints bounds a balance history so a day series covers about a year
+	maxBalancePoints = 366
+
+	maxReconcileTransactions = 500
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidAccountType reports whether accountType is a supported kind of account
+func ValidAccountType(accountType string) bool {
+	switch accountType {
+	case "cash", "debit_card", "credit_card", "e_wallet", "other":
+		return true
+	}
+	return false
+}
+
+// ValidateAccount checks an account and fills in its type and currency defaults.
+// The opening balance may be negative, e.g. for a credit card carrying a debt.
+func ValidateAccount(req *mdlFeatureOne.CreateAccountRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(req.Name) > maxAccountName {
+		return fmt.Errorf("name must be at most %d characters", maxAccountName)
+	}
+
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	if req.Type == "" {
+		req.Type = defaultAccountType
+	}
+	if !ValidAccountType(req.Type) {
+		return fmt.Errorf("type must be one of cash, debit_card, credit_card, e_wallet or other")
+	}
+
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = defaultAccountCurrency
+	}
+	if !currencyPattern.MatchString(req.Currency) {
+		return fmt.Errorf("currency must be a 3-letter ISO 4217 code")
+	}
+	return nil
+}
+
+// ValidateAccountLedgerFilters checks the date range of a ledger
+func ValidateAccountLedgerFilters(filters *mdlFeatureOne.AccountLedgerFilters) error {
+	_, _, err := parseDateRange(filters.StartDate, filters.EndDate)
+	return err
+}
+
+// ValidateAccountBalanceHistory defaults the range to the last 30 days up to today
+// and checks that the interval does not produce too many points
+func ValidateAccountBalanceHistory(startDate, endDate *string, interval string, today time.Time) (string, string, error) {
+	if !ValidReportInterval(interval) {
+		return "", "", fmt.Errorf("interval must be one of day, week, month or year")
+	}
+
+	start, end, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return "", "", err
+	}
+	if endDate == nil {
+		end = today
+	}
+	if startDate == nil {
+		start = end.AddDate(0, 0, -29)
+	}
+	if end.Before(start) {
+		return "", "", fmt.Errorf("endDate must not be before startDate")
+	}
+
+	var points int
+	switch interval {
+	case "day":
+		points = int(end.Sub(start).Hours()/24) + 1
+	case "week":
+		points = int(end.Sub(start).Hours()/24)/7 + 2
+	case "month":
+		points = (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	case "year":
+		points = end.Year() - start.Year() + 1
+	}
+	if points > maxBalancePoints {
+		return "", "", fmt.Errorf("range is too long for %s balances (at most %d points)", interval, maxBalancePoints)
+	}
+
+	return start.Format("2006-01-02"), end.Format("2006-01-02"), nil
+}
+
+// ValidateReconcileAccount checks a statement reconciliation; the transactions
+// themselves are checked against the account by the database
+func ValidateReconcileAccount(req *mdlFeatureOne.ReconcileAccountRequest, today time.Time) error {
+	date, err := time.Parse("2006-01-02", req.StatementDate)
+	if err != nil {
+		return fmt.Errorf("invalid statementDate format (expected YYYY-MM-DD)")
+	}
+	if date.After(today) {
+		return fmt.Errorf("statementDate must not be in the future")
+	}
+	if len(req.TransactionIDs) == 0 {
+		return fmt.Errorf("at least one transaction ID is required")
+	}
+	if len(req.TransactionIDs) > maxReconcileTransactions {
+		return fmt.Errorf("at most %d transactions can be reconciled at once", maxReconcileTransactions)
+	}
+	for _, id := range req.TransactionIDs {
+		if id <= 0 {
+			return fmt.Errorf("transaction IDs must be positive")
+		}
+	}
+	return nil
+}
+
+// parseDateRange parses an optional startDate and endDate; unset dates are zero
+func parseDateRange(startDate, endDate *string) (start, end time.Time, err error) {
+	if startDate != nil {
+		if start, err = time.Parse("2006-01-02", *startDate); err != nil {
+			return start, end, fmt.Errorf("invalid startDate format (expected YYYY-MM-DD)")
+		}
+	}
+	if endDate != nil {
+		if end, err = time.Parse("2006-01-02", *endDate); err != nil {
+			return start, end, fmt.Errorf("invalid endDate format (expected YYYY-MM-DD)")
+		}
+	}
+	if startDate != nil && endDate != nil && end.Before(start) {
+		return start, end, fmt.Errorf("endDate must not be before startDate")
+	}
+	return start, end, nil
+}
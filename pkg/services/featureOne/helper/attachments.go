@@ -0,0 +1,74 @@
+package hlpFeatureOne
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+)
+
+// MaxAttachmentsPerExpense caps how many files one expense can carry
+const MaxAttachmentsPerExpense = 20
+
+// ValidAttachmentStorage checks the storage backend name
+func ValidAttachmentStorage(storage string) bool {
+	return storage == "local" || storage == "cloudinary"
+}
+
+// InspectAttachment checks an uploaded file against the attachment limits and
+// returns its filename, detected content type, size and SHA-256 checksum
+func InspectAttachment(fileHeader *multipart.FileHeader) (*mdlFeatureOne.NewAttachment, error) {
+	cnf := utils.AttachmentUploadConfig()
+	if fileHeader.Size > cnf.MaxSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", fileHeader.Filename, cnf.MaxSize>>20)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Detect the type from the content, not the client's header
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	allowed := false
+	for _, allowedType := range cnf.AllowedTypes {
+		if contentType == allowedType {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%s has type %s; only JPEG, PNG, GIF, WebP images and PDFs are allowed",
+			fileHeader.Filename, contentType)
+	}
+
+	hash := sha256.New()
+	hash.Write(head[:n])
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Base(fileHeader.Filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = "attachment"
+	}
+
+	return &mdlFeatureOne.NewAttachment{
+		Filename:    truncateRunes(filename, 255),
+		ContentType: contentType,
+		Size:        size + int64(n),
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
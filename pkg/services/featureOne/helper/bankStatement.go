@@ -0,0 +1,162 @@
+package hlpFeatureOne
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxExternalIDLength = 255
+	maxImportTitle      = 255
+)
+
+// ValidStatementFormat reports whether format is a supported bank statement format
+func ValidStatementFormat(format string) bool {
+	switch format {
+	case "ofx", "qfx", "qif", "camt053":
+		return true
+	}
+	return false
+}
+
+// DetectStatementFormat guesses the format from the file name, then the content
+func DetectStatementFormat(filename string, content []byte) (string, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ofx":
+		return "ofx", true
+	case ".qfx":
+		return "qfx", true
+	case ".qif":
+		return "qif", true
+	}
+
+	head := content
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.ToUpper(head)
+	switch {
+	case bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")):
+		return "ofx", true
+	case bytes.Contains(head, []byte("CAMT.053")) || bytes.Contains(head, []byte("BKTOCSTMRSTMT")):
+		return "camt053", true
+	case bytes.HasPrefix(bytes.TrimSpace(head), []byte("!TYPE:")) || bytes.HasPrefix(bytes.TrimSpace(head), []byte("!ACCOUNT")):
+		return "qif", true
+	}
+	return "", false
+}
+
+// ParseBankStatement parses a statement; dateOrder (mdy or dmy) only matters for QIF,
+// the one format without an unambiguous date layout
+func ParseBankStatement(format string, content []byte, dateOrder string) (*mdlFeatureOne.BankStatement, error) {
+	switch format {
+	case "ofx", "qfx":
+		return ParseOFX(content)
+	case "qif":
+		return ParseQIF(content, dateOrder)
+	case "camt053":
+		return ParseCAMT053(content)
+	}
+	return nil, fmt.Errorf("unsupported statement format %s", format)
+}
+
+// StatementToExpenseRows maps debits to batch upload rows. Credits are not expenses
+// and are skipped, as are repeated transaction IDs within the file.
+func StatementToExpenseRows(statement *mdlFeatureOne.BankStatement) (rows []mdlFeatureOne.CSVExpenseRow, skippedCredits, skippedDuplicates int) {
+	seen := map[string]bool{}
+
+	for _, txn := range statement.Transactions {
+		if txn.Amount >= 0 {
+			skippedCredits++
+			continue
+		}
+
+		externalID := statementExternalID(statement, txn.ID)
+		if seen[externalID] {
+			skippedDuplicates++
+			continue
+		}
+		seen[externalID] = true
+
+		title := strings.TrimSpace(txn.Payee)
+		memo := strings.TrimSpace(txn.Memo)
+		if title == "" {
+			title = memo
+		}
+		if title == "" {
+			title = "Bank transaction"
+		}
+
+		row := mdlFeatureOne.CSVExpenseRow{
+			Title:      truncateRunes(title, maxImportTitle),
+			Amount:     math.Round(-txn.Amount*100) / 100,
+			Date:       txn.Date,
+			ExternalID: &externalID,
+		}
+		if memo != "" && memo != title {
+			row.Notes = &memo
+		}
+		// The payee is the merchant, which the categorization rules can match on
+		if payee := strings.TrimSpace(txn.Payee); payee != "" {
+			merchant := truncateRunes(payee, maxMerchantName)
+			row.Merchant = &merchant
+		}
+		rows = append(rows, row)
+	}
+	return rows, skippedCredits, skippedDuplicates
+}
+
+// statementExternalID scopes the bank's ID to the format family and account, since
+// IDs are only unique per account; overlong IDs are hashed to fit the column
+func statementExternalID(statement *mdlFeatureOne.BankStatement, id string) string {
+	family := statement.Format
+	if family == "qfx" {
+		family = "ofx"
+	}
+
+	externalID := fmt.Sprintf("%s:%s:%s", family, statement.AccountID, id)
+	if len(externalID) > maxExternalIDLength {
+		sum := sha256.Sum256([]byte(externalID))
+		externalID = family + ":sha256:" + hex.EncodeToString(sum[:])
+	}
+	return externalID
+}
+
+// contentHashID identifies a transaction by its content for formats without IDs;
+// occurrence separates identical lines within one statement
+func contentHashID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:12])
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// parseStatementAmount accepts "1234.56", "-1,234.56" and decimal commas ("-12,50")
+func parseStatementAmount(value string) (float64, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, " ", ""))
+	if strings.Contains(value, ",") {
+		if strings.Contains(value, ".") {
+			value = strings.ReplaceAll(value, ",", "")
+		} else {
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,207 @@
+package hlpFeatureOne
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"strconv"
+	"strings"
+)
+
+// ISO 20022 CAMT.053 bank-to-customer statement. Element names are matched without
+// namespaces so every camt.053.001.xx version is accepted.
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID       string      `xml:"Id"`
+	IBAN     string      `xml:"Acct>Id>IBAN"`
+	OtherID  string      `xml:"Acct>Id>Othr>Id"`
+	Currency string      `xml:"Acct>Ccy"`
+	Entries  []camtEntry `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus is a plain code before version 08 (<Sts>BOOK</Sts>), then nested (<Sts><Cd>BOOK</Cd></Sts>)
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtEntry struct {
+	EntryRef        string          `xml:"NtryRef"`
+	Amount          camtAmount      `xml:"Amt"`
+	CreditDebit     string          `xml:"CdtDbtInd"`
+	Status          camtStatus      `xml:"Sts"`
+	BookingDate     string          `xml:"BookgDt>Dt"`
+	BookingDateTime string          `xml:"BookgDt>DtTm"`
+	ValueDate       string          `xml:"ValDt>Dt"`
+	ServicerRef     string          `xml:"AcctSvcrRef"`
+	AdditionalInfo  string          `xml:"AddtlNtryInf"`
+	Details         []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTxDetails struct {
+	ServicerRef       string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID        string      `xml:"Refs>EndToEndId"`
+	TxID              string      `xml:"Refs>TxId"`
+	Amount            *camtAmount `xml:"Amt"`
+	AmountDetails     *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	CreditDebit       string      `xml:"CdtDbtInd"`
+	CreditorName      string      `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPartyName string      `xml:"RltdPties>Cdtr>Pty>Nm"`
+	DebtorName        string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPartyName   string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured      []string    `xml:"RmtInf>Ustrd"`
+	AdditionalInfo    string      `xml:"AddtlTxInf"`
+}
+
+// ParseCAMT053 parses a CAMT.053 statement. Pending entries are left out since
+// the bank may still change or drop them.
+func ParseCAMT053(content []byte) (*mdlFeatureOne.BankStatement, error) {
+	var doc camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid CAMT.053 XML: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("not a CAMT.053 file: no BkToCstmrStmt/Stmt element")
+	}
+
+	statement := &mdlFeatureOne.BankStatement{Format: "camt053"}
+	for s, stmt := range doc.Statements {
+		account := strings.TrimSpace(stmt.IBAN)
+		if account == "" {
+			account = strings.TrimSpace(stmt.OtherID)
+		}
+		if statement.AccountID == "" {
+			statement.AccountID = account
+			statement.Currency = stmt.Currency
+		} else if account != statement.AccountID {
+			return nil, fmt.Errorf("statements for several accounts in one file are not supported")
+		}
+
+		for e, entry := range stmt.Entries {
+			status := strings.ToUpper(firstNonEmpty(entry.Status.Code, entry.Status.Value))
+			if status == "PDNG" || status == "INFO" {
+				continue
+			}
+
+			txns, err := camtEntryTransactions(entry)
+			if err != nil {
+				return nil, fmt.Errorf("statement %d, entry %d: %w", s+1, e+1, err)
+			}
+			statement.Transactions = append(statement.Transactions, txns...)
+		}
+	}
+	return statement, nil
+}
+
+// camtEntryTransactions returns one transaction per entry, or one per detail for
+// batch entries whose details carry their own amounts
+func camtEntryTransactions(entry camtEntry) ([]mdlFeatureOne.BankTransaction, error) {
+	date := entry.BookingDate
+	if date == "" && len(entry.BookingDateTime) >= 10 {
+		date = entry.BookingDateTime[:10]
+	}
+	if date == "" {
+		date = entry.ValueDate
+	}
+	date = strings.TrimSpace(date)
+	if _, err := parseISODate(date); err != nil {
+		return nil, fmt.Errorf("invalid booking date %q", date)
+	}
+
+	entryID := firstNonEmpty(entry.ServicerRef, entry.EntryRef)
+
+	split := len(entry.Details) > 1
+	for _, detail := range entry.Details {
+		if detail.Amount == nil && detail.AmountDetails == nil {
+			split = false
+		}
+	}
+
+	if !split {
+		amount, err := camtSignedAmount(entry.Amount, entry.CreditDebit)
+		if err != nil {
+			return nil, err
+		}
+		txn := mdlFeatureOne.BankTransaction{Date: date, Amount: amount, Memo: strings.TrimSpace(entry.AdditionalInfo)}
+		if len(entry.Details) == 1 {
+			camtApplyDetail(&txn, entry.Details[0], amount < 0)
+			entryID = firstNonEmpty(entryID, entry.Details[0].ServicerRef, entry.Details[0].TxID, entry.Details[0].EndToEndID)
+		}
+		txn.ID = entryID
+		if txn.ID == "" {
+			txn.ID = contentHashID(date, entry.Amount.Value, entry.CreditDebit, txn.Payee, txn.Memo)
+		}
+		return []mdlFeatureOne.BankTransaction{txn}, nil
+	}
+
+	txns := make([]mdlFeatureOne.BankTransaction, 0, len(entry.Details))
+	for i, detail := range entry.Details {
+		amt := detail.Amount
+		if amt == nil {
+			amt = detail.AmountDetails
+		}
+		indicator := firstNonEmpty(detail.CreditDebit, entry.CreditDebit)
+		amount, err := camtSignedAmount(*amt, indicator)
+		if err != nil {
+			return nil, err
+		}
+
+		txn := mdlFeatureOne.BankTransaction{Date: date, Amount: amount}
+		camtApplyDetail(&txn, detail, amount < 0)
+		txn.ID = firstNonEmpty(detail.ServicerRef, detail.TxID, detail.EndToEndID)
+		if txn.ID == "" || txn.ID == "NOTPROVIDED" {
+			txn.ID = fmt.Sprintf("%s#%d", entryID, i+1)
+		}
+		txns = append(txns, txn)
+	}
+	return txns, nil
+}
+
+// camtApplyDetail takes the counterparty and remittance text from a detail; for a
+// debit the counterparty is the creditor, for a credit the debtor
+func camtApplyDetail(txn *mdlFeatureOne.BankTransaction, detail camtTxDetails, debit bool) {
+	if debit {
+		txn.Payee = firstNonEmpty(detail.CreditorName, detail.CreditorPartyName)
+	} else {
+		txn.Payee = firstNonEmpty(detail.DebtorName, detail.DebtorPartyName)
+	}
+	if memo := strings.TrimSpace(strings.Join(detail.Unstructured, " ")); memo != "" {
+		txn.Memo = memo
+	} else if txn.Memo == "" {
+		txn.Memo = strings.TrimSpace(detail.AdditionalInfo)
+	}
+}
+
+func camtSignedAmount(amount camtAmount, indicator string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount.Value)
+	}
+	switch strings.ToUpper(strings.TrimSpace(indicator)) {
+	case "DBIT":
+		return -value, nil
+	case "CRDT":
+		return value, nil
+	}
+	return 0, fmt.Errorf("invalid CdtDbtInd %q", indicator)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if v := strings.TrimSpace(value); v != "" {
+			return v
+		}
+	}
+	return ""
+}
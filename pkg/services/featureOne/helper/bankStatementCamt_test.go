@@ -0,0 +1,134 @@
+package hlpFeatureOne
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+)
+
+const camtSample = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt>
+<Stmt>
+  <Id>STMT-1</Id>
+  <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+  <Ntry>
+    <Amt Ccy="EUR">19.90</Amt>
+    <CdtDbtInd>DBIT</CdtDbtInd>
+    <Sts>BOOK</Sts>
+    <BookgDt><Dt>2026-10-05</Dt></BookgDt>
+    <AcctSvcrRef>REF-1</AcctSvcrRef>
+    <NtryDtls><TxDtls>
+      <RltdPties><Cdtr><Nm>Bookshop</Nm></Cdtr></RltdPties>
+      <RmtInf><Ustrd>Invoice</Ustrd><Ustrd>4711</Ustrd></RmtInf>
+    </TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">5.00</Amt>
+    <CdtDbtInd>DBIT</CdtDbtInd>
+    <Sts><Cd>PDNG</Cd></Sts>
+    <BookgDt><Dt>2026-10-06</Dt></BookgDt>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">30.00</Amt>
+    <CdtDbtInd>DBIT</CdtDbtInd>
+    <Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><DtTm>2026-10-07T09:30:00</DtTm></BookgDt>
+    <AcctSvcrRef>BATCH-9</AcctSvcrRef>
+    <NtryDtls>
+      <TxDtls>
+        <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+        <Amt Ccy="EUR">10.00</Amt>
+        <RltdPties><Cdtr><Nm>Gym</Nm></Cdtr></RltdPties>
+      </TxDtls>
+      <TxDtls>
+        <Refs><TxId>TX-2</TxId></Refs>
+        <AmtDtls><TxAmt><Amt Ccy="EUR">20.00</Amt></TxAmt></AmtDtls>
+        <RltdPties><Cdtr><Pty><Nm>Insurance</Nm></Pty></Cdtr></RltdPties>
+      </TxDtls>
+    </NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">100.00</Amt>
+    <CdtDbtInd>CRDT</CdtDbtInd>
+    <Sts>BOOK</Sts>
+    <ValDt><Dt>2026-10-08</Dt></ValDt>
+    <AddtlNtryInf>Refund</AddtlNtryInf>
+    <NtryDtls><TxDtls><RltdPties><Dbtr><Nm>Airline</Nm></Dbtr></RltdPties></TxDtls></NtryDtls>
+  </Ntry>
+</Stmt>
+</BkToCstmrStmt>
+</Document>`
+
+func TestParseCAMT053(t *testing.T) {
+	got, err := ParseCAMT053([]byte(camtSample))
+	if err != nil {
+		t.Fatalf("ParseCAMT053 error: %v", err)
+	}
+
+	refundID := contentHashID("2026-10-08", "100.00", "CRDT", "Airline", "Refund")
+	want := &mdlFeatureOne.BankStatement{
+		Format:    "camt053",
+		AccountID: "DE89370400440532013000",
+		Currency:  "EUR",
+		Transactions: []mdlFeatureOne.BankTransaction{
+			{ID: "REF-1", Date: "2026-10-05", Amount: -19.90, Payee: "Bookshop", Memo: "Invoice 4711"},
+			{ID: "BATCH-9#1", Date: "2026-10-07", Amount: -10, Payee: "Gym"},
+			{ID: "TX-2", Date: "2026-10-07", Amount: -20, Payee: "Insurance"},
+			{ID: refundID, Date: "2026-10-08", Amount: 100, Payee: "Airline", Memo: "Refund"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCAMT053:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestParseCAMT053Errors(t *testing.T) {
+	statement := func(account, entry string) string {
+		return `<Document><BkToCstmrStmt><Stmt><Acct><Id><Othr><Id>` + account + `</Id></Othr></Id></Acct>` +
+			entry + `</Stmt></BkToCstmrStmt></Document>`
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not XML", "hello", "invalid CAMT.053 XML"},
+		{"no statement", "<Document><Other/></Document>", "no BkToCstmrStmt/Stmt element"},
+		{
+			name:    "bad booking date",
+			content: statement("A", `<Ntry><Amt>1</Amt><CdtDbtInd>DBIT</CdtDbtInd><BookgDt><Dt>05.10.2026</Dt></BookgDt></Ntry>`),
+			wantErr: `statement 1, entry 1: invalid booking date "05.10.2026"`,
+		},
+		{
+			name:    "bad amount",
+			content: statement("A", `<Ntry><Amt>ten</Amt><CdtDbtInd>DBIT</CdtDbtInd><BookgDt><Dt>2026-10-05</Dt></BookgDt></Ntry>`),
+			wantErr: `invalid amount "ten"`,
+		},
+		{
+			name:    "bad indicator",
+			content: statement("A", `<Ntry><Amt>1</Amt><CdtDbtInd>X</CdtDbtInd><BookgDt><Dt>2026-10-05</Dt></BookgDt></Ntry>`),
+			wantErr: `invalid CdtDbtInd "X"`,
+		},
+		{
+			name: "several accounts",
+			content: `<Document><BkToCstmrStmt>` +
+				`<Stmt><Acct><Id><IBAN>A</IBAN></Id></Acct></Stmt>` +
+				`<Stmt><Acct><Id><IBAN>B</IBAN></Id></Acct></Stmt>` +
+				`</BkToCstmrStmt></Document>`,
+			wantErr: "several accounts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCAMT053([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseCAMT053 error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// OFX 1.x is SGML where leaf elements have no closing tag, and OFX 2.x (and QFX)
+// is XML. Reading each leaf as "<TAG>value" up to the next tag or line break
+// handles both without a full parser.
+
+var (
+	ofxRootPattern        = regexp.MustCompile(`(?i)<OFX>`)
+	ofxTransactionPattern = regexp.MustCompile(`(?i)<STMTTRN>`)
+	ofxTransactionEnd     = regexp.MustCompile(`(?i)</STMTTRN>`)
+	ofxFieldPatterns      = map[string]*regexp.Regexp{}
+)
+
+func init() {
+	for _, tag := range []string{"ACCTID", "CURDEF", "TRNTYPE", "DTPOSTED", "TRNAMT", "FITID", "NAME", "MEMO", "CHECKNUM"} {
+		ofxFieldPatterns[tag] = regexp.MustCompile(`(?i)<` + tag + `>([^<\r\n]*)`)
+	}
+}
+
+func ofxField(block, tag string) string {
+	match := ofxFieldPatterns[tag].FindStringSubmatch(block)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(match[1]))
+}
+
+// ParseOFX parses an OFX or QFX bank or credit card statement
+func ParseOFX(content []byte) (*mdlFeatureOne.BankStatement, error) {
+	doc := string(content)
+	if !ofxRootPattern.MatchString(doc) {
+		return nil, fmt.Errorf("not an OFX file: missing <OFX> element")
+	}
+
+	// Everything before the first transaction holds the account details
+	starts := ofxTransactionPattern.FindAllStringIndex(doc, -1)
+	header := doc
+	if len(starts) > 0 {
+		header = doc[:starts[0][0]]
+	}
+	statement := &mdlFeatureOne.BankStatement{
+		Format:    "ofx",
+		AccountID: ofxField(header, "ACCTID"),
+		Currency:  ofxField(header, "CURDEF"),
+	}
+
+	for i, start := range starts {
+		end := len(doc)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		block := doc[start[1]:end]
+		if loc := ofxTransactionEnd.FindStringIndex(block); loc != nil {
+			block = block[:loc[0]]
+		}
+
+		txn, err := parseOFXTransaction(block)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		statement.Transactions = append(statement.Transactions, *txn)
+	}
+	return statement, nil
+}
+
+func parseOFXTransaction(block string) (*mdlFeatureOne.BankTransaction, error) {
+	// DTPOSTED is YYYYMMDD optionally followed by time and zone, e.g. 20261005120000.000[-5:EST]
+	posted := ofxField(block, "DTPOSTED")
+	if len(posted) < 8 {
+		return nil, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+	date := fmt.Sprintf("%s-%s-%s", posted[0:4], posted[4:6], posted[6:8])
+	if _, err := parseISODate(date); err != nil {
+		return nil, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+
+	amount, err := parseStatementAmount(ofxField(block, "TRNAMT"))
+	if err != nil {
+		return nil, err
+	}
+
+	id := ofxField(block, "FITID")
+	if id == "" {
+		return nil, fmt.Errorf("missing FITID")
+	}
+
+	payee := ofxField(block, "NAME")
+	if payee == "" && ofxField(block, "CHECKNUM") != "" {
+		payee = "Check " + ofxField(block, "CHECKNUM")
+	}
+
+	return &mdlFeatureOne.BankTransaction{
+		ID:     id,
+		Date:   date,
+		Amount: amount,
+		Payee:  payee,
+		Memo:   ofxField(block, "MEMO"),
+	}, nil
+}
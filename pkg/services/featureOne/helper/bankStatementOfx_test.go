@@ -0,0 +1,134 @@
+package hlpFeatureOne
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+)
+
+const ofxSGMLSample = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>121000248<ACCTID>000123456<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20261005120000.000[-5:EST]
+<TRNAMT>-42.50
+<FITID>2026100501
+<NAME>Joe&amp;s Diner
+<MEMO>POS purchase
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CHECK
+<DTPOSTED>20261006
+<TRNAMT>-1,200.00
+<FITID>2026100602
+<CHECKNUM>1042
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20261007
+<TRNAMT>2500.00
+<FITID>2026100703
+<NAME>Payroll
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXMLSample = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+<CURDEF>EUR</CURDEF>
+<CCACCTFROM><ACCTID>4111XXXX1111</ACCTID></CCACCTFROM>
+<BANKTRANLIST>
+<stmttrn><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20261101</DTPOSTED><TRNAMT>-9.99</TRNAMT><FITID>A1</FITID><NAME>Streaming</NAME></stmttrn>
+</BANKTRANLIST>
+</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *mdlFeatureOne.BankStatement
+	}{
+		{
+			name:    "SGML 1.x with entities and a check",
+			content: ofxSGMLSample,
+			want: &mdlFeatureOne.BankStatement{
+				Format:    "ofx",
+				AccountID: "000123456",
+				Currency:  "USD",
+				Transactions: []mdlFeatureOne.BankTransaction{
+					{ID: "2026100501", Date: "2026-10-05", Amount: -42.50, Payee: "Joe&s Diner", Memo: "POS purchase"},
+					{ID: "2026100602", Date: "2026-10-06", Amount: -1200, Payee: "Check 1042"},
+					{ID: "2026100703", Date: "2026-10-07", Amount: 2500, Payee: "Payroll"},
+				},
+			},
+		},
+		{
+			name:    "XML 2.x credit card, tags in any case",
+			content: ofxXMLSample,
+			want: &mdlFeatureOne.BankStatement{
+				Format:    "ofx",
+				AccountID: "4111XXXX1111",
+				Currency:  "EUR",
+				Transactions: []mdlFeatureOne.BankTransaction{
+					{ID: "A1", Date: "2026-11-01", Amount: -9.99, Payee: "Streaming"},
+				},
+			},
+		},
+		{
+			name:    "no transactions",
+			content: "<OFX><ACCTID>1</OFX>",
+			want:    &mdlFeatureOne.BankStatement{Format: "ofx", AccountID: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOFX([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("ParseOFX error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOFX:\n got  %+v\n want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOFXErrors(t *testing.T) {
+	transaction := func(fields string) string {
+		return "<OFX><STMTTRN>" + fields + "</STMTTRN></OFX>"
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not OFX", "hello", "missing <OFX> element"},
+		{"short date", transaction("<DTPOSTED>2026<TRNAMT>-1<FITID>x"), `transaction 1: invalid DTPOSTED "2026"`},
+		{"impossible date", transaction("<DTPOSTED>20261340<TRNAMT>-1<FITID>x"), `invalid DTPOSTED "20261340"`},
+		{"bad amount", transaction("<DTPOSTED>20261005<TRNAMT>ten<FITID>x"), `invalid amount "ten"`},
+		{"missing FITID", transaction("<DTPOSTED>20261005<TRNAMT>-1"), "missing FITID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOFX([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseOFX error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package hlpFeatureOne
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QIF records are lines prefixed with a one-letter field code and ended by "^".
+// The format has no transaction IDs, so each line is identified by a hash of its
+// content plus how many identical lines came before it in the file.
+
+func parseISODate(value string) (time.Time, error) {
+	return time.Parse("2006-01-02", value)
+}
+
+// ParseQIF parses a Quicken Interchange Format bank, cash or credit card export.
+// dateOrder is mdy (default, as Quicken writes) or dmy.
+func ParseQIF(content []byte, dateOrder string) (*mdlFeatureOne.BankStatement, error) {
+	if dateOrder == "" {
+		dateOrder = "mdy"
+	}
+	if dateOrder != "mdy" && dateOrder != "dmy" {
+		return nil, fmt.Errorf("dateOrder must be mdy or dmy")
+	}
+
+	statement := &mdlFeatureOne.BankStatement{Format: "qif"}
+	occurrences := map[string]int{}
+
+	var (
+		fields     = map[byte]string{}
+		inAccount  bool
+		inTxnBlock bool
+		lineNo     int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Section headers: account details or a transaction list
+		if line[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(line))
+			inAccount = header == "!account"
+			inTxnBlock = strings.HasPrefix(header, "!type:") &&
+				!strings.HasPrefix(header, "!type:invst") &&
+				!strings.HasPrefix(header, "!type:cat") &&
+				!strings.HasPrefix(header, "!type:class") &&
+				!strings.HasPrefix(header, "!type:memorized")
+			fields = map[byte]string{}
+			continue
+		}
+
+		if line[0] != '^' {
+			// Split lines (S, E, $) repeat per split; only the first of each code is kept
+			if _, exists := fields[line[0]]; !exists {
+				fields[line[0]] = strings.TrimSpace(line[1:])
+			}
+			continue
+		}
+
+		record := fields
+		fields = map[byte]string{}
+		if inAccount {
+			statement.AccountID = record['N']
+			continue
+		}
+		if !inTxnBlock {
+			continue
+		}
+
+		txn, err := parseQIFRecord(record, dateOrder)
+		if err != nil {
+			return nil, fmt.Errorf("record ending at line %d: %w", lineNo, err)
+		}
+
+		key := strings.Join([]string{txn.Date, strconv.FormatFloat(txn.Amount, 'f', 2, 64), txn.Payee, txn.Memo, record['N']}, "|")
+		occurrences[key]++
+		txn.ID = contentHashID(key, strconv.Itoa(occurrences[key]))
+
+		statement.Transactions = append(statement.Transactions, *txn)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !inTxnBlock && len(statement.Transactions) == 0 {
+		return nil, fmt.Errorf("not a QIF file: missing !Type header")
+	}
+	return statement, nil
+}
+
+func parseQIFRecord(record map[byte]string, dateOrder string) (*mdlFeatureOne.BankTransaction, error) {
+	date, err := parseQIFDate(record['D'], dateOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	rawAmount := record['T']
+	if rawAmount == "" {
+		rawAmount = record['U']
+	}
+	// Quicken writes thousands separators in US exports
+	if dateOrder == "mdy" {
+		rawAmount = strings.ReplaceAll(rawAmount, ",", "")
+	}
+	amount, err := parseStatementAmount(rawAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mdlFeatureOne.BankTransaction{
+		Date:   date,
+		Amount: amount,
+		Payee:  record['P'],
+		Memo:   record['M'],
+	}, nil
+}
+
+// parseQIFDate reads dates such as 10/5/2026, 10/ 5'26, 5.10.2026 or 2026-10-05
+func parseQIFDate(value, dateOrder string) (string, error) {
+	normalized := strings.NewReplacer("'", "/", "-", "/", ".", "/").Replace(strings.TrimSpace(value))
+	parts := strings.Split(normalized, "/")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+
+	nums := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q", value)
+		}
+		nums[i] = n
+	}
+
+	var year, month, day int
+	switch {
+	case len(strings.TrimSpace(parts[0])) == 4:
+		year, month, day = nums[0], nums[1], nums[2]
+	case dateOrder == "dmy":
+		day, month, year = nums[0], nums[1], nums[2]
+	default:
+		month, day, year = nums[0], nums[1], nums[2]
+	}
+	if year < 100 {
+		if year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	date := fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	if _, err := parseISODate(date); err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
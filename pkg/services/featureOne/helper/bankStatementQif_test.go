@@ -0,0 +1,139 @@
+package hlpFeatureOne
+
+import (
+	"strings"
+	"testing"
+)
+
+const qifSample = `!Account
+NChecking 1234
+TBank
+^
+!Type:Bank
+D10/ 5'26
+T-1,234.56
+PHardware Store
+MShelves
+^
+D10/6/2026
+U-8.00
+PCoffee
+SGroceries
+$-5.00
+SHousehold
+$-3.00
+^
+D10/6/2026
+T-8.00
+PCoffee
+^
+D10/6/2026
+T-8.00
+PCoffee
+^
+!Type:Cat
+NFood
+^
+`
+
+func TestParseQIF(t *testing.T) {
+	statement, err := ParseQIF([]byte(qifSample), "")
+	if err != nil {
+		t.Fatalf("ParseQIF error: %v", err)
+	}
+
+	if statement.Format != "qif" || statement.AccountID != "Checking 1234" {
+		t.Errorf("statement = %q account %q, want qif account %q", statement.Format, statement.AccountID, "Checking 1234")
+	}
+	if len(statement.Transactions) != 4 {
+		t.Fatalf("got %d transactions, want 4 (the category list is not one): %+v",
+			len(statement.Transactions), statement.Transactions)
+	}
+
+	first := statement.Transactions[0]
+	if first.Date != "2026-10-05" || first.Amount != -1234.56 || first.Payee != "Hardware Store" || first.Memo != "Shelves" {
+		t.Errorf("first transaction = %+v", first)
+	}
+	if split := statement.Transactions[1]; split.Amount != -8 || split.Payee != "Coffee" {
+		t.Errorf("U amount with splits = %+v, want -8 at Coffee", split)
+	}
+
+	// Identical lines get different IDs, which stay the same when the file is read again
+	ids := map[string]bool{}
+	for _, txn := range statement.Transactions {
+		if txn.ID == "" || ids[txn.ID] {
+			t.Errorf("transaction ID %q is empty or repeated", txn.ID)
+		}
+		ids[txn.ID] = true
+	}
+	again, err := ParseQIF([]byte(qifSample), "mdy")
+	if err != nil {
+		t.Fatalf("ParseQIF error: %v", err)
+	}
+	for i := range again.Transactions {
+		if again.Transactions[i].ID != statement.Transactions[i].ID {
+			t.Errorf("transaction %d ID changed between reads", i)
+		}
+	}
+}
+
+func TestParseQIFDayFirst(t *testing.T) {
+	content := "!Type:CCard\nD05.10.2026\nT-12,50\nPBakery\n^\n"
+	statement, err := ParseQIF([]byte(content), "dmy")
+	if err != nil {
+		t.Fatalf("ParseQIF error: %v", err)
+	}
+	if len(statement.Transactions) != 1 {
+		t.Fatalf("got %d transactions, want 1", len(statement.Transactions))
+	}
+	if txn := statement.Transactions[0]; txn.Date != "2026-10-05" || txn.Amount != -12.5 {
+		t.Errorf("transaction = %+v, want 2026-10-05 and -12.50", txn)
+	}
+}
+
+func TestParseQIFDate(t *testing.T) {
+	tests := []struct {
+		value, order, want string
+	}{
+		{"10/5/2026", "mdy", "2026-10-05"},
+		{"10/ 5'26", "mdy", "2026-10-05"},
+		{"5/10/2026", "dmy", "2026-10-05"},
+		{"5.10.26", "dmy", "2026-10-05"},
+		{"2026-10-05", "dmy", "2026-10-05"},
+		{"12/31/99", "mdy", "1999-12-31"},
+	}
+	for _, tt := range tests {
+		got, err := parseQIFDate(tt.value, tt.order)
+		if err != nil || got != tt.want {
+			t.Errorf("parseQIFDate(%q, %s) = %q, %v; want %q", tt.value, tt.order, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "10/5", "13/1/2026", "a/b/c", "2/30/2026"} {
+		if got, err := parseQIFDate(value, "mdy"); err == nil {
+			t.Errorf("parseQIFDate(%q) = %q, want an error", value, got)
+		}
+	}
+}
+
+func TestParseQIFErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		order   string
+		wantErr string
+	}{
+		{"no header", "hello\n", "", "missing !Type header"},
+		{"bad date order", "!Type:Bank\n", "ymd", "dateOrder must be mdy or dmy"},
+		{"bad date", "!Type:Bank\nDsoon\nT-1\n^\n", "", `record ending at line 4: invalid date "soon"`},
+		{"bad amount", "!Type:Bank\nD10/5/2026\nTlots\n^\n", "", `invalid amount "lots"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQIF([]byte(tt.content), tt.order)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseQIF error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
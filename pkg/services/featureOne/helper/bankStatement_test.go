@@ -0,0 +1,107 @@
+package hlpFeatureOne
+
+import (
+	"strings"
+	"testing"
+
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+)
+
+func TestDetectStatementFormat(t *testing.T) {
+	tests := []struct {
+		filename, content, want string
+	}{
+		{"export.OFX", "", "ofx"},
+		{"export.qfx", "", "qfx"},
+		{"export.qif", "", "qif"},
+		{"statement.txt", "OFXHEADER:100\n<OFX>", "ofx"},
+		{"statement.xml", `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">`, "camt053"},
+		{"statement.xml", "<Document><BkToCstmrStmt>", "camt053"},
+		{"statement.txt", "\n!Type:Bank\nD10/5/2026", "qif"},
+		{"statement.txt", "!Account\nNChecking", "qif"},
+	}
+	for _, tt := range tests {
+		if got, ok := DetectStatementFormat(tt.filename, []byte(tt.content)); !ok || got != tt.want {
+			t.Errorf("DetectStatementFormat(%q, %q) = %q, %v; want %q", tt.filename, tt.content, got, ok, tt.want)
+		}
+	}
+
+	if got, ok := DetectStatementFormat("notes.txt", []byte("date,amount")); ok {
+		t.Errorf("DetectStatementFormat of a CSV = %q, want no format", got)
+	}
+}
+
+func TestParseStatementAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"1234.56", 1234.56},
+		{"-1,234.56", -1234.56},
+		{"-12,50", -12.5},
+		{" 1 000.00 ", 1000},
+		{"+7", 7},
+	}
+	for _, tt := range tests {
+		if got, err := parseStatementAmount(tt.value); err != nil || got != tt.want {
+			t.Errorf("parseStatementAmount(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"", "ten", "1.2.3"} {
+		if _, err := parseStatementAmount(value); err == nil {
+			t.Errorf("parseStatementAmount(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestStatementToExpenseRows(t *testing.T) {
+	statement := &mdlFeatureOne.BankStatement{
+		Format:    "qfx",
+		AccountID: "123",
+		Transactions: []mdlFeatureOne.BankTransaction{
+			{ID: "1", Date: "2026-10-05", Amount: -42.505, Payee: " Diner ", Memo: "Lunch"},
+			{ID: "2", Date: "2026-10-05", Amount: 100, Payee: "Payroll"},
+			{ID: "1", Date: "2026-10-05", Amount: -42.50, Payee: "Diner"},
+			{ID: "3", Date: "2026-10-06", Amount: -5, Memo: "Fee"},
+			{ID: "4", Date: "2026-10-06", Amount: -1},
+		},
+	}
+
+	rows, credits, duplicates := StatementToExpenseRows(statement)
+	if credits != 1 || duplicates != 1 {
+		t.Errorf("skipped %d credits and %d duplicates, want 1 and 1", credits, duplicates)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	first := rows[0]
+	if first.Title != "Diner" || first.Amount != 42.51 || first.Date != "2026-10-05" {
+		t.Errorf("first row = %+v", first)
+	}
+	if first.ExternalID == nil || *first.ExternalID != "ofx:123:1" {
+		t.Errorf("first row external ID = %v, want ofx:123:1 (qfx shares IDs with ofx)", first.ExternalID)
+	}
+	if first.Notes == nil || *first.Notes != "Lunch" || first.Merchant == nil || *first.Merchant != "Diner" {
+		t.Errorf("first row notes %v, merchant %v; want Lunch at Diner", first.Notes, first.Merchant)
+	}
+
+	if memoOnly := rows[1]; memoOnly.Title != "Fee" || memoOnly.Notes != nil || memoOnly.Merchant != nil {
+		t.Errorf("memo-only row = %+v, want title Fee without notes or merchant", memoOnly)
+	}
+	if empty := rows[2]; empty.Title != "Bank transaction" {
+		t.Errorf("row without text has title %q", empty.Title)
+	}
+}
+
+func TestStatementExternalIDFitsColumn(t *testing.T) {
+	statement := &mdlFeatureOne.BankStatement{Format: "camt053", AccountID: "DE89370400440532013000"}
+
+	id := statementExternalID(statement, strings.Repeat("x", 300))
+	if len(id) > maxExternalIDLength || !strings.HasPrefix(id, "camt053:sha256:") {
+		t.Errorf("overlong external ID = %q", id)
+	}
+	if again := statementExternalID(statement, strings.Repeat("x", 300)); again != id {
+		t.Errorf("hashed external ID is not stable: %q != %q", again, id)
+	}
+}
@@ -0,0 +1,187 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// defaultBudgetThresholds are the usage percentages that raise alerts when none are given
+var defaultBudgetThresholds = []int{80, 100}
+
+// ValidateCreateBudget validates a new budget and fills in default thresholds
+func ValidateCreateBudget(req *mdlFeatureOne.CreateBudgetRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	// The name goes into alert email subjects, where a line break would start a new header
+	if strings.IndexFunc(req.Name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("name must not contain line breaks or other control characters")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if strings.TrimSpace(req.StartDate) == "" {
+		return fmt.Errorf("startDate is required")
+	}
+	return ValidateBudget(req.Period, req.StartDate, req.EndDate, req.Rollover, req.Thresholds)
+}
+
+// ValidateBudget checks the period, dates, rollover and thresholds of a budget
+func ValidateBudget(period, startDate string, endDate *string, rollover bool, thresholds []int) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return fmt.Errorf("invalid startDate format (expected YYYY-MM-DD)")
+	}
+
+	switch period {
+	case "weekly", "monthly":
+	case "custom":
+		if endDate == nil || strings.TrimSpace(*endDate) == "" {
+			return fmt.Errorf("endDate is required for custom period")
+		}
+		if rollover {
+			return fmt.Errorf("rollover is only supported for weekly and monthly budgets")
+		}
+	default:
+		return fmt.Errorf("period must be one of weekly, monthly or custom")
+	}
+
+	if endDate != nil && strings.TrimSpace(*endDate) != "" {
+		end, err := time.Parse("2006-01-02", *endDate)
+		if err != nil {
+			return fmt.Errorf("invalid endDate format (expected YYYY-MM-DD)")
+		}
+		if end.Before(start) {
+			return fmt.Errorf("endDate must not be before startDate")
+		}
+	}
+
+	for _, t := range thresholds {
+		if t < 1 || t > 1000 {
+			return fmt.Errorf("thresholds must be percentages between 1 and 1000")
+		}
+	}
+	return nil
+}
+
+// NormalizeThresholds sorts and de-duplicates thresholds, falling back to the defaults
+func NormalizeThresholds(thresholds []int) []int {
+	if len(thresholds) == 0 {
+		return append([]int(nil), defaultBudgetThresholds...)
+	}
+	seen := make(map[int]bool, len(thresholds))
+	result := make([]int, 0, len(thresholds))
+	for _, t := range thresholds {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// BudgetPeriod returns the period of the budget containing asOf.
+// Weekly periods run Monday to Sunday and monthly periods follow the calendar month,
+// both clipped to the budget's own start and end dates. ok is false when the
+// budget is not active on asOf.
+func BudgetPeriod(budget *mdlFeatureOne.BudgetResponse, asOf time.Time) (start, end time.Time, ok bool) {
+	budgetStart, err := time.Parse("2006-01-02", budget.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	var budgetEnd *time.Time
+	if budget.EndDate != nil {
+		if e, err := time.Parse("2006-01-02", *budget.EndDate); err == nil {
+			budgetEnd = &e
+		}
+	}
+
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(budgetStart) || (budgetEnd != nil && day.After(*budgetEnd)) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	switch budget.Period {
+	case "weekly":
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		start = day.AddDate(0, 0, -offset)
+		end = start.AddDate(0, 0, 6)
+	case "monthly":
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, -1)
+	case "custom":
+		if budgetEnd == nil {
+			return time.Time{}, time.Time{}, false
+		}
+		return budgetStart, *budgetEnd, true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	if start.Before(budgetStart) {
+		start = budgetStart
+	}
+	if budgetEnd != nil && end.After(*budgetEnd) {
+		end = *budgetEnd
+	}
+	return start, end, true
+}
+
+// PreviousBudgetPeriod returns the period before the one starting at periodStart,
+// used to compute rollover. ok is false for custom budgets and the first period.
+func PreviousBudgetPeriod(budget *mdlFeatureOne.BudgetResponse, periodStart time.Time) (start, end time.Time, ok bool) {
+	if budget.Period == "custom" {
+		return time.Time{}, time.Time{}, false
+	}
+	return BudgetPeriod(budget, periodStart.AddDate(0, 0, -1))
+}
+
+// BuildBudgetStatus computes limit, remaining and usage for one budget period.
+// rollover is the previous period's unspent (or overspent, when negative) amount.
+func BuildBudgetStatus(budget *mdlFeatureOne.BudgetResponse, start, end time.Time, spent, rollover float64) mdlFeatureOne.BudgetStatusItem {
+	limit := roundCents(budget.Amount + rollover)
+	item := mdlFeatureOne.BudgetStatusItem{
+		Budget:         *budget,
+		PeriodStart:    start.Format("2006-01-02"),
+		PeriodEnd:      end.Format("2006-01-02"),
+		RolloverAmount: roundCents(rollover),
+		Limit:          limit,
+		Spent:          roundCents(spent),
+		Remaining:      roundCents(limit - spent),
+	}
+
+	if limit > 0 {
+		item.PercentUsed = math.Round(spent/limit*10000) / 100
+	} else if spent > 0 {
+		item.PercentUsed = 100
+	}
+
+	item.Status = "ok"
+	if item.PercentUsed >= 100 {
+		item.Status = "exceeded"
+	} else if len(budget.Thresholds) > 0 && item.PercentUsed >= float64(budget.Thresholds[0]) {
+		item.Status = "warning"
+	}
+	return item
+}
+
+// CrossedThresholds returns the thresholds reached by the given usage percentage
+func CrossedThresholds(thresholds []int, percentUsed float64) []int {
+	var crossed []int
+	for _, t := range thresholds {
+		if percentUsed >= float64(t) {
+			crossed = append(crossed, t)
+		}
+	}
+	return crossed
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
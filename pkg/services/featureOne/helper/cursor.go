@@ -0,0 +1,126 @@
+package hlpFeatureOne
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"regexp"
+	"time"
+)
+
+// cursorNumberPattern matches the text Postgres gives numeric and real sort keys
+var cursorNumberPattern = regexp.MustCompile(`^-?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`)
+
+// cursorToken is the payload of an opaque list cursor. It carries the sort it
+// was issued for so a cursor cannot be replayed against a different ordering.
+type cursorToken struct {
+	SortBy string `json:"s"`
+	Order  string `json:"o"`
+	Value  string `json:"v"`
+	ID     int    `json:"i"`
+	Dir    string `json:"d"`
+}
+
+// ValidSortBy reports whether sortBy is a supported list ordering
+func ValidSortBy(sortBy string) bool {
+	switch sortBy {
+	case "date", "amount", "createdAt", "title", "relevance":
+		return true
+	}
+	return false
+}
+
+// ApplyListSort fills in the default ordering and validates it. Searches sort by
+// relevance, everything else by date; titles default to ascending, the rest descending.
+func ApplyListSort(filters *mdlFeatureOne.ExpenseFilters) error {
+	if filters.SortBy == "" {
+		filters.SortBy = "date"
+		if filters.SearchQuery != nil {
+			filters.SortBy = "relevance"
+		}
+	}
+	if !ValidSortBy(filters.SortBy) {
+		return fmt.Errorf("sortBy must be one of date, amount, createdAt, title or relevance")
+	}
+	if filters.SortBy == "relevance" && filters.SearchQuery == nil {
+		return fmt.Errorf("sortBy relevance requires q")
+	}
+
+	if filters.Order == "" {
+		filters.Order = "desc"
+		if filters.SortBy == "title" {
+			filters.Order = "asc"
+		}
+	}
+	if filters.Order != "asc" && filters.Order != "desc" {
+		return fmt.Errorf("order must be asc or desc")
+	}
+	return nil
+}
+
+// EncodeCursor builds the opaque cursor for a row key under the filters' sort
+func EncodeCursor(filters *mdlFeatureOne.ExpenseFilters, key *mdlFeatureOne.CursorKey, dir string) *string {
+	if key == nil {
+		return nil
+	}
+	payload, err := json.Marshal(cursorToken{
+		SortBy: filters.SortBy,
+		Order:  filters.Order,
+		Value:  key.Value,
+		ID:     key.ID,
+		Dir:    dir,
+	})
+	if err != nil {
+		return nil
+	}
+	token := base64.RawURLEncoding.EncodeToString(payload)
+	return &token
+}
+
+// DecodeCursor resolves an opaque cursor against the filters' sort, which must
+// already be applied with ApplyListSort
+func DecodeCursor(filters *mdlFeatureOne.ExpenseFilters, token string) (*mdlFeatureOne.CursorKey, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor cursorToken
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.Dir != "next" && cursor.Dir != "prev" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.SortBy != filters.SortBy || cursor.Order != filters.Order {
+		return nil, fmt.Errorf("cursor was issued for a different sortBy or order")
+	}
+	if !validCursorValue(filters.SortBy, cursor.Value) {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &mdlFeatureOne.CursorKey{Value: cursor.Value, ID: cursor.ID, Dir: cursor.Dir}, nil
+}
+
+// validCursorValue reports whether a cursor's sort key casts to the column type
+// get_transactions compares it with, so a tampered cursor is a bad request
+func validCursorValue(sortBy, value string) bool {
+	switch sortBy {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "createdAt":
+		_, err := time.Parse("2006-01-02 15:04:05.999999", value)
+		return err == nil
+	case "amount", "relevance":
+		return cursorNumberPattern.MatchString(value)
+	}
+	return true
+}
+
+// ApplyListCursors sets nextCursor / prevCursor from the page's boundary keys
+func ApplyListCursors(filters *mdlFeatureOne.ExpenseFilters, pagination *mdlFeatureOne.PaginationResponse, cursors mdlFeatureOne.ListCursors) {
+	pagination.NextCursor = EncodeCursor(filters, cursors.Next, "next")
+	pagination.PrevCursor = EncodeCursor(filters, cursors.Prev, "prev")
+}
@@ -0,0 +1,78 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"sort"
+)
+
+const (
+	// DefaultDuplicateWindowDays and DefaultDuplicateSimilarity decide what counts
+	// as a duplicate on create and import
+	DefaultDuplicateWindowDays = 3
+	DefaultDuplicateSimilarity = 0.5
+
+	maxDuplicateWindowDays = 31
+)
+
+// ValidateDuplicateFilters checks the window, similarity and date range of a duplicate scan
+func ValidateDuplicateFilters(filters *mdlFeatureOne.DuplicateFilters) error {
+	if filters.WindowDays < 0 || filters.WindowDays > maxDuplicateWindowDays {
+		return fmt.Errorf("days must be between 0 and %d", maxDuplicateWindowDays)
+	}
+	if filters.Similarity <= 0 || filters.Similarity > 1 {
+		return fmt.Errorf("similarity must be greater than 0 and at most 1")
+	}
+	_, _, err := parseDateRange(filters.StartDate, filters.EndDate)
+	return err
+}
+
+// ClusterDuplicatePairs groups expenses linked directly or through other matches.
+// Each cluster lists its expense IDs in ascending order, with the closest match
+// between any two of them; clusters are ordered by their first expense ID.
+func ClusterDuplicatePairs(pairs []mdlFeatureOne.DuplicatePair) ([][]int, []float64) {
+	parent := map[int]int{}
+	var find func(int) int
+	find = func(id int) int {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	for _, p := range pairs {
+		a, b := find(p.ExpenseID), find(p.DuplicateID)
+		if a != b {
+			parent[b] = a
+		}
+	}
+
+	members := map[int][]int{}
+	best := map[int]float64{}
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+	for _, p := range pairs {
+		root := find(p.ExpenseID)
+		if p.Similarity > best[root] {
+			best[root] = p.Similarity
+		}
+	}
+
+	clusters := make([][]int, 0, len(members))
+	for _, ids := range members {
+		sort.Ints(ids)
+		clusters = append(clusters, ids)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+
+	similarities := make([]float64, len(clusters))
+	for i, ids := range clusters {
+		similarities[i] = best[find(ids[0])]
+	}
+	return clusters, similarities
+}
@@ -0,0 +1,36 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionETag is the strong entity tag of a row version
+func VersionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ParseIfMatch returns the version an If-Match header expects. It is nil when the
+// header is absent or "*", which any existing row satisfies.
+func ParseIfMatch(header string) (*int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(header, "W/") || strings.Contains(header, ",") {
+		return nil, fmt.Errorf("If-Match must be a single entity tag from ETag")
+	}
+
+	value := strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`)
+	if len(value) != len(header)-2 {
+		return nil, fmt.Errorf("If-Match must be a quoted entity tag")
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return nil, fmt.Errorf("If-Match does not name a version")
+	}
+	return &version, nil
+}
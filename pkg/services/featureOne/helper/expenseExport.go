@@ -0,0 +1,290 @@
+package hlpFeatureOne
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVImportHeaders is the column layout read by the CSV batch upload; CSV exports
+// use it too so an export can be imported again
+var CSVImportHeaders = []string{"title", "amount", "categoryid", "date", "notes", "tags", "merchant"}
+
+// ExpenseExportWriter writes expenses one row at a time, so an export never holds
+// more than a row (a page for PDF) in memory
+type ExpenseExportWriter interface {
+	WriteRow(row *mdlFeatureOne.ExportExpenseRow) error
+	Close() error
+}
+
+// ExportFormat returns the content type and file extension of an export format
+func ExportFormat(format string) (contentType, extension string, ok bool) {
+	switch format {
+	case "csv":
+		return "text/csv; charset=utf-8", "csv", true
+	case "xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", true
+	case "json":
+		return "application/json", "json", true
+	case "pdf":
+		return "application/pdf", "pdf", true
+	}
+	return "", "", false
+}
+
+// NewExpenseExportWriter starts an export in the given format; the format must be
+// one accepted by ExportFormat
+func NewExpenseExportWriter(format string, w io.Writer, meta mdlFeatureOne.ExportMeta) (ExpenseExportWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVExportWriter(w)
+	case "xlsx":
+		return newXLSXExportWriter(w)
+	case "json":
+		return newJSONExportWriter(w)
+	case "pdf":
+		return newPDFExportWriter(w, meta), nil
+	}
+	return nil, fmt.Errorf("unsupported export format %s", format)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// ============================================
+// CSV
+// ============================================
+
+// csvFormulaPrefixes are the first characters that make a spreadsheet evaluate
+// a CSV cell as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVFormula quotes a text value that a spreadsheet would run as a formula
+// by prefixing it with ', which spreadsheets show as plain text
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeCSVFormula removes the ' an export put in front of a formula-like
+// value, so exported files import unchanged
+func UnescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVImportHeaders); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{w: cw}, nil
+}
+
+func (e *csvExportWriter) WriteRow(row *mdlFeatureOne.ExportExpenseRow) error {
+	categoryID := ""
+	if row.CategoryID != nil {
+		categoryID = strconv.Itoa(*row.CategoryID)
+	}
+	notes := ""
+	if row.Notes != nil {
+		notes = *row.Notes
+	}
+	merchant := ""
+	if row.Merchant != nil {
+		merchant = *row.Merchant
+	}
+	// Text columns are user input, so none of them may start a formula
+	return e.w.Write([]string{
+		escapeCSVFormula(row.Title), formatAmount(row.Amount), categoryID, row.Date,
+		escapeCSVFormula(notes), escapeCSVFormula(row.Tags), escapeCSVFormula(merchant),
+	})
+}
+
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ============================================
+// JSON
+// ============================================
+
+type jsonExportRow struct {
+	*mdlFeatureOne.ExportExpenseRow
+	Tags []string `json:"tags"`
+}
+
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONExportWriter(w io.Writer) (*jsonExportWriter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonExportWriter{w: w}, nil
+}
+
+func (e *jsonExportWriter) WriteRow(row *mdlFeatureOne.ExportExpenseRow) error {
+	tags := SplitTagList(row.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(jsonExportRow{ExportExpenseRow: row, Tags: tags})
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExportWriter) Close() error {
+	_, err := io.WriteString(e.w, "]")
+	return err
+}
+
+// ============================================
+// XLSX
+// ============================================
+
+// The workbook is written as a minimal SpreadsheetML package; the sheet is the
+// last zip entry so rows can be appended while the export streams.
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Expenses" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+type xlsxExportWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	row   int
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	e := &xlsxExportWriter{zw: zw, sheet: sheet}
+	headers := []interface{}{"ID", "Title", "Amount", "Category ID", "Category", "Merchant", "Date", "Notes", "Tags", "Created At"}
+	if err := e.writeCells(headers); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// writeCells writes a row of string (inline) and float64 (numeric) cells; nil leaves
+// a cell empty. Text is only ever an inline string, never a formula, so user input
+// starting with = is shown as typed.
+func (e *xlsxExportWriter) writeCells(cells []interface{}) error {
+	e.row++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, e.row)
+	for i, cell := range cells {
+		ref := fmt.Sprintf("%c%d", 'A'+i, e.row)
+		switch v := cell.(type) {
+		case float64:
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+		case string:
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(&b, []byte(v)); err != nil {
+				return err
+			}
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(e.sheet, b.String())
+	return err
+}
+
+func (e *xlsxExportWriter) WriteRow(row *mdlFeatureOne.ExportExpenseRow) error {
+	cells := []interface{}{float64(row.ID), row.Title, row.Amount, nil, nil, nil, row.Date, nil, row.Tags, row.CreatedAt}
+	if row.CategoryID != nil {
+		cells[3] = float64(*row.CategoryID)
+	}
+	if row.CategoryName != nil {
+		cells[4] = *row.CategoryName
+	}
+	if row.Merchant != nil {
+		cells[5] = *row.Merchant
+	}
+	if row.Notes != nil {
+		cells[7] = *row.Notes
+	}
+	return e.writeCells(cells)
+}
+
+func (e *xlsxExportWriter) Close() error {
+	if _, err := io.WriteString(e.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
@@ -0,0 +1,303 @@
+package hlpFeatureOne
+
+import (
+	"bytes"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The statement is a plain PDF 1.4 file using the standard Helvetica fonts. Each
+// page is written as soon as it fills up; only the category totals and the
+// object offsets needed for the cross-reference table are kept until the end.
+
+const (
+	pdfPageWidth    = 595.0 // A4 in points
+	pdfPageHeight   = 842.0
+	pdfMargin       = 40.0
+	pdfLineHeight   = 14.0
+	pdfFontSize     = 9.0
+	pdfTitleMaxChar = 48
+	pdfCatMaxChar   = 24
+
+	pdfCatalogObj = 1
+	pdfPagesObj   = 2
+	pdfFontObj    = 3
+	pdfBoldObj    = 4
+)
+
+type pdfCategoryTotal struct {
+	name  string
+	total float64
+	count int
+}
+
+type pdfExportWriter struct {
+	w       *countingWriter
+	meta    mdlFeatureOne.ExportMeta
+	offsets map[int]int64
+	nextObj int
+	pages   []int
+	page    *bytes.Buffer
+	y       float64
+	err     error
+
+	totals     map[string]*pdfCategoryTotal
+	grandTotal float64
+	rowCount   int
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newPDFExportWriter(w io.Writer, meta mdlFeatureOne.ExportMeta) *pdfExportWriter {
+	e := &pdfExportWriter{
+		w:       &countingWriter{w: w},
+		meta:    meta,
+		offsets: map[int]int64{},
+		nextObj: pdfBoldObj + 1,
+		totals:  map[string]*pdfCategoryTotal{},
+	}
+
+	e.write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	e.writeObject(pdfFontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	e.writeObject(pdfBoldObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	return e
+}
+
+func (e *pdfExportWriter) write(s string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = io.WriteString(e.w, s)
+}
+
+func (e *pdfExportWriter) writeObject(id int, body string) {
+	e.offsets[id] = e.w.n
+	e.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", id, body))
+}
+
+// text draws a string at (x, y) measured from the top-left corner of the page
+func (e *pdfExportWriter) text(x, y float64, bold bool, size float64, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(e.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, pdfPageHeight-y, pdfEscape(s))
+}
+
+// textRight draws a number right-aligned at x
+func (e *pdfExportWriter) textRight(x, y float64, bold bool, s string) {
+	e.text(x-pdfNumberWidth(s, pdfFontSize), y, bold, pdfFontSize, s)
+}
+
+func (e *pdfExportWriter) line(y float64) {
+	fmt.Fprintf(e.page, "%.2f %.2f m %.2f %.2f l S\n", pdfMargin, pdfPageHeight-y, pdfPageWidth-pdfMargin, pdfPageHeight-y)
+}
+
+func (e *pdfExportWriter) startPage() {
+	e.page = &bytes.Buffer{}
+	e.page.WriteString("0.5 w\n")
+	e.y = pdfMargin
+
+	if len(e.pages) == 0 {
+		e.text(pdfMargin, e.y+12, true, 16, "Expense Statement")
+		e.y += 30
+		period := "All dates"
+		if e.meta.StartDate != nil || e.meta.EndDate != nil {
+			from, to := "beginning", "today"
+			if e.meta.StartDate != nil {
+				from = *e.meta.StartDate
+			}
+			if e.meta.EndDate != nil {
+				to = *e.meta.EndDate
+			}
+			period = fmt.Sprintf("Period: %s to %s", from, to)
+		}
+		e.text(pdfMargin, e.y, false, pdfFontSize, period)
+		e.y += pdfLineHeight
+		e.text(pdfMargin, e.y, false, pdfFontSize, "Generated: "+e.meta.GeneratedAt.Format("2006-01-02 15:04"))
+		e.y += pdfLineHeight * 2
+	}
+}
+
+func (e *pdfExportWriter) tableHeader() {
+	e.text(pdfMargin, e.y, true, pdfFontSize, "Date")
+	e.text(pdfMargin+70, e.y, true, pdfFontSize, "Title")
+	e.text(pdfMargin+330, e.y, true, pdfFontSize, "Category")
+	e.textRight(pdfPageWidth-pdfMargin, e.y, true, "Amount")
+	e.y += 4
+	e.line(e.y)
+	e.y += pdfLineHeight
+}
+
+// ensureSpace moves to a new page when fewer than lines rows remain on the current one
+func (e *pdfExportWriter) ensureSpace(lines int, withTableHeader bool) {
+	if e.page != nil && e.y+float64(lines)*pdfLineHeight <= pdfPageHeight-pdfMargin {
+		return
+	}
+	if e.page != nil {
+		e.flushPage()
+	}
+	e.startPage()
+	if withTableHeader {
+		e.tableHeader()
+	}
+}
+
+func (e *pdfExportWriter) flushPage() {
+	contentObj := e.nextObj
+	pageObj := e.nextObj + 1
+	e.nextObj += 2
+
+	// Page number in the footer
+	fmt.Fprintf(e.page, "BT /F1 8.0 Tf %.2f %.2f Td (Page %d) Tj ET\n", pdfMargin, pdfMargin/2, len(e.pages)+1)
+
+	content := e.page.String()
+	e.writeObject(contentObj, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	e.writeObject(pageObj, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R "+
+			"/Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pdfPagesObj, pdfPageWidth, pdfPageHeight, contentObj, pdfFontObj, pdfBoldObj))
+	e.pages = append(e.pages, pageObj)
+	e.page = nil
+}
+
+func (e *pdfExportWriter) WriteRow(row *mdlFeatureOne.ExportExpenseRow) error {
+	category := "Uncategorized"
+	if row.CategoryName != nil {
+		category = *row.CategoryName
+	}
+
+	e.ensureSpace(1, true)
+	e.text(pdfMargin, e.y, false, pdfFontSize, row.Date)
+	e.text(pdfMargin+70, e.y, false, pdfFontSize, pdfTruncate(row.Title, pdfTitleMaxChar))
+	e.text(pdfMargin+330, e.y, false, pdfFontSize, pdfTruncate(category, pdfCatMaxChar))
+	e.textRight(pdfPageWidth-pdfMargin, e.y, false, formatAmount(row.Amount))
+	e.y += pdfLineHeight
+
+	total, ok := e.totals[category]
+	if !ok {
+		total = &pdfCategoryTotal{name: category}
+		e.totals[category] = total
+	}
+	total.total += row.Amount
+	total.count++
+	e.grandTotal += row.Amount
+	e.rowCount++
+
+	return e.err
+}
+
+func (e *pdfExportWriter) Close() error {
+	if e.rowCount == 0 {
+		e.ensureSpace(1, false)
+		e.text(pdfMargin, e.y, false, pdfFontSize, "No expenses match the selected filters.")
+		e.y += pdfLineHeight
+	}
+
+	// Totals per category, largest first
+	totals := make([]*pdfCategoryTotal, 0, len(e.totals))
+	for _, total := range e.totals {
+		totals = append(totals, total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].total != totals[j].total {
+			return totals[i].total > totals[j].total
+		}
+		return totals[i].name < totals[j].name
+	})
+
+	e.ensureSpace(4, false)
+	e.y += pdfLineHeight
+	e.text(pdfMargin, e.y, true, 11, "Totals by Category")
+	e.y += pdfLineHeight + 4
+	for _, total := range totals {
+		e.ensureSpace(1, false)
+		e.text(pdfMargin, e.y, false, pdfFontSize, pdfTruncate(total.name, pdfTitleMaxChar))
+		e.textRight(pdfMargin+380, e.y, false, fmt.Sprintf("%d", total.count))
+		e.textRight(pdfPageWidth-pdfMargin, e.y, false, formatAmount(total.total))
+		e.y += pdfLineHeight
+	}
+
+	e.ensureSpace(2, false)
+	e.line(e.y - pdfLineHeight + 4)
+	e.y += 2
+	e.text(pdfMargin, e.y, true, pdfFontSize, "Total")
+	e.textRight(pdfMargin+380, e.y, true, fmt.Sprintf("%d", e.rowCount))
+	e.textRight(pdfPageWidth-pdfMargin, e.y, true, formatAmount(e.grandTotal))
+	e.flushPage()
+
+	kids := make([]string, len(e.pages))
+	for i, page := range e.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	e.writeObject(pdfPagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(e.pages)))
+	e.writeObject(pdfCatalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj))
+
+	// Cross-reference table: one 20-byte entry per object, in object order
+	xrefOffset := e.w.n
+	e.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", e.nextObj))
+	for id := 1; id < e.nextObj; id++ {
+		e.write(fmt.Sprintf("%010d 00000 n \n", e.offsets[id]))
+	}
+	e.write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", e.nextObj, pdfCatalogObj, xrefOffset))
+
+	return e.err
+}
+
+// pdfEscape converts text to WinAnsi bytes for the standard fonts, replacing
+// characters they cannot show, and escapes PDF string delimiters
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func pdfTruncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// pdfNumberWidth measures numbers and short labels in Helvetica for right alignment
+func pdfNumberWidth(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			units += 556
+		case r == '.' || r == ',':
+			units += 278
+		case r == '-':
+			units += 333
+		default:
+			units += 600
+		}
+	}
+	return float64(units) * size / 1000
+}
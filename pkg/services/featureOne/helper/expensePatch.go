@@ -0,0 +1,418 @@
+package hlpFeatureOne
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxJSONPatchOperations caps the operations of one JSON Patch document
+const maxJSONPatchOperations = 100
+
+// ErrJSONPatchTestFailed is returned when a "test" operation does not hold
+var ErrJSONPatchTestFailed = errors.New("json patch test operation failed")
+
+// ValidateUpdateExpense checks a partial expense update
+func ValidateUpdateExpense(req *mdlFeatureOne.UpdateExpenseRequest) error {
+	if req.Title == nil && req.Amount == nil && req.CategoryID == nil && req.Merchant == nil &&
+		req.AccountID == nil && req.Date == nil && req.Notes == nil && req.ImageURL == nil && req.Tags == nil && len(req.Clear) == 0 {
+		return fmt.Errorf("at least one field to update is required")
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if req.Merchant != nil {
+		if err := ValidateMerchantName(*req.Merchant); err != nil {
+			return err
+		}
+	}
+	if req.Date != nil {
+		if _, err := time.Parse("2006-01-02", *req.Date); err != nil {
+			return fmt.Errorf("invalid date format (expected YYYY-MM-DD)")
+		}
+	}
+	return ValidateTags(req.Tags)
+}
+
+// ParseExpenseMergePatch reads an RFC 7396 merge patch of an expense. Absent
+// fields are kept and null clears a field: categoryId, merchant, accountId, notes
+// and imageUrl are listed in Clear, tags become an empty list.
+func ParseExpenseMergePatch(body []byte) (*mdlFeatureOne.UpdateExpenseRequest, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("merge patch must be a JSON object")
+	}
+
+	var req mdlFeatureOne.UpdateExpenseRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %v", err)
+	}
+
+	for key, value := range fields {
+		if !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			continue
+		}
+		switch key {
+		case "categoryId", "merchant", "accountId", "notes", "imageUrl":
+			req.Clear = append(req.Clear, key)
+		case "tags":
+			req.Tags = []string{}
+		case "title", "amount", "date":
+			return nil, fmt.Errorf("%s cannot be removed", key)
+		}
+	}
+
+	return &req, nil
+}
+
+// ParseBatchUpdateItems reads batch update items with the merge patch rules of
+// ParseExpenseMergePatch, so an item can clear fields with null
+func ParseBatchUpdateItems(body []byte) ([]mdlFeatureOne.BatchUpdateItem, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("body must be a JSON array of updates")
+	}
+
+	items := make([]mdlFeatureOne.BatchUpdateItem, len(raw))
+	for i, data := range raw {
+		if err := json.Unmarshal(data, &items[i]); err != nil {
+			return nil, fmt.Errorf("update %d: %v", i, err)
+		}
+		patch, err := ParseExpenseMergePatch(data)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %v", i, err)
+		}
+		items[i].Tags = patch.Tags
+		items[i].Clear = patch.Clear
+	}
+	return items, nil
+}
+
+// ValidateBatchUpdateItem checks the fields of a batch update item that cannot be
+// left to the database
+func ValidateBatchUpdateItem(item *mdlFeatureOne.BatchUpdateItem) error {
+	if item.Merchant != nil {
+		if err := ValidateMerchantName(*item.Merchant); err != nil {
+			return err
+		}
+	}
+	return ValidateTags(item.Tags)
+}
+
+// ApplyExpenseJSONPatch applies an RFC 6902 JSON Patch to the editable fields of
+// an expense and returns the result as an update. Paths address the expense JSON,
+// e.g. /notes or /tags/-.
+func ApplyExpenseJSONPatch(expense *mdlFeatureOne.ExpenseResponse, body []byte) (*mdlFeatureOne.UpdateExpenseRequest, error) {
+	var ops []mdlFeatureOne.JSONPatchOperation
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, fmt.Errorf("json patch must be an array of operations")
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("json patch has no operations")
+	}
+	if len(ops) > maxJSONPatchOperations {
+		return nil, fmt.Errorf("json patch can have at most %d operations", maxJSONPatchOperations)
+	}
+
+	editable := expensePatchDocument(expense)
+	var doc interface{} = expensePatchDocument(expense)
+	for i, op := range ops {
+		var err error
+		if doc, err = applyJSONPatchOperation(doc, op); err != nil {
+			if errors.Is(err, ErrJSONPatchTestFailed) {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			return nil, fmt.Errorf("operation %d (%s %s): %v", i, op.Op, op.Path, err)
+		}
+	}
+
+	// The patched document is the complete new state, so it reads as a merge patch
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("json patch must leave an object")
+	}
+	for key := range fields {
+		if _, known := editable[key]; !known {
+			return nil, fmt.Errorf("%s is not an editable field", key)
+		}
+	}
+	for _, key := range []string{"title", "amount", "date"} {
+		if fields[key] == nil {
+			return nil, fmt.Errorf("%s cannot be removed", key)
+		}
+	}
+	for _, key := range []string{"categoryId", "merchant", "accountId", "notes", "imageUrl", "tags"} {
+		if _, present := fields[key]; !present {
+			fields[key] = nil
+		}
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExpenseMergePatch(merged)
+}
+
+// expensePatchDocument is the part of the expense JSON a JSON Patch may change
+func expensePatchDocument(expense *mdlFeatureOne.ExpenseResponse) map[string]interface{} {
+	var categoryID, merchant, accountID interface{}
+	if expense.Category != nil {
+		categoryID = float64(expense.Category.ID)
+	}
+	if expense.Merchant != nil {
+		merchant = expense.Merchant.Name
+	}
+	if expense.AccountID != nil {
+		accountID = float64(*expense.AccountID)
+	}
+	tags := make([]interface{}, len(expense.Tags))
+	for i, tag := range expense.Tags {
+		tags[i] = tag
+	}
+	date := expense.Date
+	if len(date) > 10 {
+		date = date[:10]
+	}
+
+	return map[string]interface{}{
+		"title":      expense.Title,
+		"amount":     expense.Amount,
+		"categoryId": categoryID,
+		"merchant":   merchant,
+		"accountId":  accountID,
+		"date":       date,
+		"notes":      stringOrNil(expense.Notes),
+		"imageUrl":   stringOrNil(expense.ImageURL),
+		"tags":       tags,
+	}
+}
+
+func stringOrNil(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func applyJSONPatchOperation(doc interface{}, op mdlFeatureOne.JSONPatchOperation) (interface{}, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("value is required")
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %v", err)
+		}
+		switch op.Op {
+		case "add":
+			return jsonPointerAdd(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if doc, _, err = jsonPointerRemove(doc, path); err != nil {
+				return nil, err
+			}
+			return jsonPointerAdd(doc, path, value)
+		default:
+			current, err := jsonPointerGet(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrJSONPatchTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		doc, _, err = jsonPointerRemove(doc, path)
+		return doc, err
+
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %v", err)
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("cannot move a value into itself")
+			}
+			if doc, value, err = jsonPointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			current, err := jsonPointerGet(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			// Copy through JSON so the two locations do not share maps or slices
+			encoded, _ := json.Marshal(current)
+			json.Unmarshal(encoded, &value)
+		}
+		return jsonPointerAdd(doc, path, value)
+	}
+
+	return nil, fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into unescaped tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func jsonArrayIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index >= length || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("array index %q out of range", token)
+	}
+	return index, nil
+}
+
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			doc = value
+		case []interface{}:
+			index, err := jsonArrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerAdd returns doc with value added at path; "-" appends to an array
+func jsonPointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("path not found")
+		}
+		updated, err := jsonPointerAdd(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+
+	case []interface{}:
+		if len(rest) == 0 {
+			if token == "-" {
+				return append(node, value), nil
+			}
+			index, err := jsonArrayIndex(token, len(node)+1)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]interface{}, 0, len(node)+1)
+			out = append(out, node[:index]...)
+			out = append(out, value)
+			return append(out, node[index:]...), nil
+		}
+		index, err := jsonArrayIndex(token, len(node))
+		if err != nil {
+			return nil, err
+		}
+		updated, err := jsonPointerAdd(node[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("path not found")
+}
+
+// jsonPointerRemove returns doc without the value at path, and that value
+func jsonPointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	token, rest := path[0], path[1:]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path not found")
+		}
+		if len(rest) == 0 {
+			delete(node, token)
+			return node, child, nil
+		}
+		updated, removed, err := jsonPointerRemove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = updated
+		return node, removed, nil
+
+	case []interface{}:
+		index, err := jsonArrayIndex(token, len(node))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := node[index]
+			out := make([]interface{}, 0, len(node)-1)
+			out = append(out, node[:index]...)
+			return append(out, node[index+1:]...), removed, nil
+		}
+		updated, removed, err := jsonPointerRemove(node[index], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[index] = updated
+		return node, removed, nil
+	}
+
+	return nil, nil, fmt.Errorf("path not found")
+}
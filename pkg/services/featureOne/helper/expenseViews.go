@@ -0,0 +1,106 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxViewName     = 100
+	MaxViewsPerUser = 50
+)
+
+// ValidateExpenseView checks a saved view and normalises its filters the way the
+// list endpoint reads them, so a view saves exactly what GET /expenses accepts
+func ValidateExpenseView(req *mdlFeatureOne.ExpenseViewRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(req.Name) > maxViewName {
+		return fmt.Errorf("name must be at most %d characters", maxViewName)
+	}
+
+	view := &req.Filters
+	view.Title = trimmedOrNil(view.Title)
+	view.Q = trimmedOrNil(view.Q)
+	view.StartDate = trimmedOrNil(view.StartDate)
+	view.EndDate = trimmedOrNil(view.EndDate)
+	view.Tags = NormalizeTags(view.Tags)
+	view.TagMatch = strings.ToLower(strings.TrimSpace(view.TagMatch))
+	view.Filter = strings.TrimSpace(view.Filter)
+	view.SortBy = strings.TrimSpace(view.SortBy)
+	view.Order = strings.ToLower(strings.TrimSpace(view.Order))
+
+	if err := ValidateTags(view.Tags); err != nil {
+		return err
+	}
+	if _, _, err := parseDateRange(view.StartDate, view.EndDate); err != nil {
+		return err
+	}
+	if view.MinAmount != nil && view.MaxAmount != nil && *view.MaxAmount < *view.MinAmount {
+		return fmt.Errorf("maxAmount must not be less than minAmount")
+	}
+
+	// Validate the rest on the filters the view will produce
+	filters := &mdlFeatureOne.ExpenseFilters{}
+	ApplySavedView(filters, view)
+	return ValidateExpenseFilters(filters)
+}
+
+// ApplySavedView fills the filters a request left unset from a saved view;
+// parameters given explicitly take precedence over the view's
+func ApplySavedView(filters *mdlFeatureOne.ExpenseFilters, view *mdlFeatureOne.SavedViewFilters) {
+	if filters.Title == nil {
+		filters.Title = view.Title
+	}
+	if filters.Q == nil && view.Q != nil {
+		filters.Q = view.Q
+		if query, ok := BuildSearchQuery(*view.Q); ok {
+			filters.SearchQuery = &query
+		}
+	}
+	if filters.MinAmount == nil {
+		filters.MinAmount = view.MinAmount
+	}
+	if filters.MaxAmount == nil {
+		filters.MaxAmount = view.MaxAmount
+	}
+	if filters.CategoryID == nil {
+		filters.CategoryID = view.CategoryID
+	}
+	if filters.StartDate == nil {
+		filters.StartDate = view.StartDate
+	}
+	if filters.EndDate == nil {
+		filters.EndDate = view.EndDate
+	}
+	if len(filters.Tags) == 0 {
+		filters.Tags = view.Tags
+	}
+	if filters.TagMatch == "" {
+		filters.TagMatch = view.TagMatch
+	}
+	if filters.Filter == "" {
+		filters.Filter = view.Filter
+	}
+	if filters.SortBy == "" {
+		filters.SortBy = view.SortBy
+	}
+	if filters.Order == "" {
+		filters.Order = view.Order
+	}
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -3,10 +3,16 @@ package hlpFeatureOne
 import (
 	"fmt"
 	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"regexp"
 	"strings"
 	"time"
 )
 
+// maxSearchTerms caps how many words of a search query are used
+const maxSearchTerms = 10
+
+var searchTermPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
 func ValidateCreateExpense(req *mdlFeatureOne.CreateExpenseRequest) error {
 	if strings.TrimSpace(req.Title) == "" {
 		return fmt.Errorf("title is required")
@@ -20,5 +26,56 @@ func ValidateCreateExpense(req *mdlFeatureOne.CreateExpenseRequest) error {
 	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
 		return fmt.Errorf("invalid date format (expected YYYY-MM-DD)")
 	}
+	if req.Merchant != nil {
+		if err := ValidateMerchantName(*req.Merchant); err != nil {
+			return err
+		}
+	}
+	return ValidateTags(req.Tags)
+}
+
+// ValidateExpenseFilters validates the list filters shared by expenses and transactions,
+// filling in the default sort, compiling the filter expression and decoding the
+// cursor if one was given
+func ValidateExpenseFilters(filters *mdlFeatureOne.ExpenseFilters) error {
+	if !ValidTagMatch(filters.TagMatch) {
+		return fmt.Errorf("tagMatch must be any or all")
+	}
+	if filters.Q != nil && filters.SearchQuery == nil {
+		return fmt.Errorf("q must contain at least one letter or digit")
+	}
+	if err := ApplyListSort(filters); err != nil {
+		return err
+	}
+	if filters.Filter != "" {
+		condition, params, err := CompileExpenseFilter(filters.Filter)
+		if err != nil {
+			return err
+		}
+		filters.FilterSQL = &condition
+		filters.FilterParams = params
+	}
+	if filters.CursorToken != "" {
+		cursor, err := DecodeCursor(filters, filters.CursorToken)
+		if err != nil {
+			return err
+		}
+		filters.Cursor = cursor
+		filters.Offset = 0
+	}
 	return nil
 }
+
+// BuildSearchQuery turns free text into a prefix-matching to_tsquery expression,
+// e.g. "Trip ceb" -> "trip:* & ceb:*". Only letters and digits survive, so the
+// result is always a valid tsquery. ok is false when no words remain.
+func BuildSearchQuery(q string) (query string, ok bool) {
+	terms := searchTermPattern.FindAllString(strings.ToLower(q), maxSearchTerms)
+	if len(terms) == 0 {
+		return "", false
+	}
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & "), true
+}
@@ -0,0 +1,653 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A filter expression narrows list, export and report queries beyond the fixed
+// filters, e.g.
+//
+//	amount > 500 and (category in (1,3) or tag = "travel") and date >= 2026-01-01
+//
+// It is parsed into an AST and compiled to a SQL condition on the transaction
+// row t. Field names map to fixed columns and every value becomes a parameter
+// read from filterParams, so nothing the user typed ends up in the SQL text.
+
+const (
+	maxFilterLength   = 1000
+	maxFilterDepth    = 20
+	maxFilterTerms    = 50
+	maxFilterInValues = 100
+
+	// filterParamsRef is how filter_transactions binds the filterParams array
+	filterParamsRef = "$3"
+)
+
+var filterDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+
+// FilterSyntaxError reports an invalid filter expression; Pos is the 1-based
+// character position of the offending token
+type FilterSyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Pos, e.Msg)
+}
+
+// ============================================
+// FIELDS
+// ============================================
+
+type filterFieldKind int
+
+const (
+	filterNumber filterFieldKind = iota
+	filterDate
+	filterText
+	filterRef
+	filterTag
+)
+
+type filterField struct {
+	kind     filterFieldKind
+	column   string
+	nullable bool
+}
+
+var filterFields = map[string]filterField{
+	"amount":   {kind: filterNumber, column: "t.amount"},
+	"date":     {kind: filterDate, column: "t.date"},
+	"title":    {kind: filterText, column: "t.title"},
+	"notes":    {kind: filterText, column: "t.notes", nullable: true},
+	"category": {kind: filterRef, column: "t.category_id", nullable: true},
+	"merchant": {kind: filterRef, column: "t.merchant_id", nullable: true},
+	"account":  {kind: filterRef, column: "t.account_id", nullable: true},
+	"tag":      {kind: filterTag, nullable: true},
+	"tags":     {kind: filterTag, nullable: true},
+}
+
+const filterFieldNames = "amount, date, title, notes, category, merchant, account or tag"
+
+// filterOperatorAllowed reports whether op applies to a field; ordering only
+// makes sense for numbers and dates, contains only for text
+func filterOperatorAllowed(field filterField, op string) bool {
+	switch op {
+	case "=", "!=", "in", "not in":
+		return true
+	case ">", ">=", "<", "<=":
+		return field.kind == filterNumber || field.kind == filterDate
+	case "contains":
+		return field.kind == filterText
+	case "is null", "is not null":
+		return field.nullable
+	}
+	return false
+}
+
+// ============================================
+// LEXER
+// ============================================
+
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokDate
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string // unquoted value for strings, lower-cased for identifiers
+	pos  int
+}
+
+func (t filterToken) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lexFilter(src string) ([]filterToken, error) {
+	var tokens []filterToken
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		pos := utf8.RuneCountInString(src[:i]) + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokRParen, text: ")", pos: pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: tokComma, text: ",", pos: pos})
+			i++
+
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			n := 1
+			if i+1 < len(src) {
+				switch src[i : i+2] {
+				case "==", "!=", "<=", ">=", "<>":
+					n = 2
+				}
+			}
+			op := src[i : i+n]
+			switch op {
+			case "==":
+				op = "="
+			case "<>":
+				op = "!="
+			case "!":
+				return nil, &FilterSyntaxError{Pos: pos, Msg: `unexpected "!" (use != or not)`}
+			}
+			tokens = append(tokens, filterToken{kind: tokOperator, text: op, pos: pos})
+			i += n
+
+		case r == '"' || r == '\'':
+			value, n, err := lexFilterString(src[i:], r)
+			if err != nil {
+				return nil, &FilterSyntaxError{Pos: pos, Msg: err.Error()}
+			}
+			tokens = append(tokens, filterToken{kind: tokString, text: value, pos: pos})
+			i += n
+
+		case r >= '0' && r <= '9':
+			if date := filterDatePattern.FindString(src[i:]); date != "" {
+				tokens = append(tokens, filterToken{kind: tokDate, text: date, pos: pos})
+				i += len(date)
+				break
+			}
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(src[i:j], 64); err != nil {
+				return nil, &FilterSyntaxError{Pos: pos, Msg: fmt.Sprintf("invalid number %q", src[i:j])}
+			}
+			tokens = append(tokens, filterToken{kind: tokNumber, text: src[i:j], pos: pos})
+			i = j
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(src) {
+				c, n := utf8.DecodeRuneInString(src[j:])
+				if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+					break
+				}
+				j += n
+			}
+			tokens = append(tokens, filterToken{kind: tokIdent, text: strings.ToLower(src[i:j]), pos: pos})
+			i = j
+
+		default:
+			return nil, &FilterSyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	tokens = append(tokens, filterToken{kind: tokEOF, pos: utf8.RuneCountInString(src) + 1})
+	return tokens, nil
+}
+
+// lexFilterString reads a quoted string where a backslash escapes the next
+// character; it returns the value and the number of bytes consumed
+func lexFilterString(src string, quote rune) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 == len(src) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			b.WriteByte(src[i])
+		case byte(quote):
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// ============================================
+// PARSER
+// ============================================
+
+// filterNode is a node of the parsed expression: *filterLogical, *filterNot
+// or *filterComparison
+type filterNode interface{}
+
+type filterLogical struct {
+	op          string // and | or
+	left, right filterNode
+}
+
+type filterNot struct {
+	expr filterNode
+}
+
+type filterComparison struct {
+	name   string
+	field  filterField
+	op     string
+	values []filterToken
+}
+
+type filterParser struct {
+	tokens []filterToken
+	next   int
+	depth  int
+	terms  int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) advance() filterToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *filterParser) peekKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && tok.text == word
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...interface{}) error {
+	return &FilterSyntaxError{Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseFilterExpression parses:
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op value | field ["not"] "in" "(" value { "," value } ")"
+//	           | field "contains" value | field "is" ["not"] "null"
+func parseFilterExpression(src string) (filterNode, error) {
+	if utf8.RuneCountInString(src) > maxFilterLength {
+		return nil, &FilterSyntaxError{Pos: maxFilterLength + 1,
+			Msg: fmt.Sprintf("filter must be at most %d characters", maxFilterLength)}
+	}
+
+	tokens, err := lexFilter(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "filter is empty")
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, p.errorf(tok, "unmatched )")
+		}
+		return nil, p.errorf(tok, "expected and, or or end of filter, got %s", tok.describe())
+	}
+	return node, nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterLogical{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterLogical{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	tok := p.peek()
+	if tok.kind == tokIdent && tok.text == "not" || tok.kind == tokLParen {
+		p.depth++
+		if p.depth > maxFilterDepth {
+			return nil, p.errorf(tok, "filter is nested more than %d levels deep", maxFilterDepth)
+		}
+		defer func() { p.depth-- }()
+	}
+
+	if p.peekKeyword("not") {
+		p.advance()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{expr: expr}, nil
+	}
+
+	if tok.kind == tokLParen {
+		p.advance()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected ) to close ( at position %d, got %s", tok.pos, closing.describe())
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	tok := p.advance()
+	if tok.kind != tokIdent {
+		return nil, p.errorf(tok, "expected a field (%s), got %s", filterFieldNames, tok.describe())
+	}
+	field, ok := filterFields[tok.text]
+	if !ok {
+		return nil, p.errorf(tok, "unknown field %q (expected %s)", tok.text, filterFieldNames)
+	}
+
+	p.terms++
+	if p.terms > maxFilterTerms {
+		return nil, p.errorf(tok, "filter has more than %d comparisons", maxFilterTerms)
+	}
+
+	cmp := &filterComparison{name: tok.text, field: field}
+	opTok := p.advance()
+	switch {
+	case opTok.kind == tokOperator:
+		cmp.op = opTok.text
+	case opTok.kind == tokIdent && (opTok.text == "in" || opTok.text == "contains"):
+		cmp.op = opTok.text
+	case opTok.kind == tokIdent && opTok.text == "not":
+		if in := p.advance(); in.kind != tokIdent || in.text != "in" {
+			return nil, p.errorf(in, "expected in after not, got %s", in.describe())
+		}
+		cmp.op = "not in"
+	case opTok.kind == tokIdent && opTok.text == "is":
+		cmp.op = "is null"
+		if p.peekKeyword("not") {
+			p.advance()
+			cmp.op = "is not null"
+		}
+		if null := p.advance(); null.kind != tokIdent || null.text != "null" {
+			return nil, p.errorf(null, "expected null after is, got %s", null.describe())
+		}
+	default:
+		return nil, p.errorf(opTok, "expected an operator after %s, got %s", cmp.name, opTok.describe())
+	}
+
+	if !filterOperatorAllowed(field, cmp.op) {
+		return nil, p.errorf(opTok, "operator %s is not supported for %s", cmp.op, cmp.name)
+	}
+
+	switch cmp.op {
+	case "is null", "is not null":
+		return cmp, nil
+
+	case "in", "not in":
+		if open := p.advance(); open.kind != tokLParen {
+			return nil, p.errorf(open, "expected ( after %s, got %s", cmp.op, open.describe())
+		}
+		for {
+			value, err := p.parseValue(cmp)
+			if err != nil {
+				return nil, err
+			}
+			cmp.values = append(cmp.values, value)
+			if len(cmp.values) > maxFilterInValues {
+				return nil, p.errorf(value, "a list can have at most %d values", maxFilterInValues)
+			}
+			sep := p.advance()
+			if sep.kind == tokRParen {
+				return cmp, nil
+			}
+			if sep.kind != tokComma {
+				return nil, p.errorf(sep, "expected , or ) in list, got %s", sep.describe())
+			}
+		}
+
+	default:
+		value, err := p.parseValue(cmp)
+		if err != nil {
+			return nil, err
+		}
+		cmp.values = []filterToken{value}
+		return cmp, nil
+	}
+}
+
+// parseValue reads a value and checks it fits the field: numbers for amount,
+// dates for date, quoted strings for text and tags, IDs or names for references
+func (p *filterParser) parseValue(cmp *filterComparison) (filterToken, error) {
+	tok := p.advance()
+	if tok.kind == tokIdent && tok.text == "null" {
+		return tok, p.errorf(tok, "use %s is null or %s is not null to compare with null", cmp.name, cmp.name)
+	}
+
+	switch cmp.field.kind {
+	case filterNumber:
+		if tok.kind != tokNumber {
+			return tok, p.errorf(tok, "expected a number for %s, got %s", cmp.name, tok.describe())
+		}
+
+	case filterDate:
+		if tok.kind != tokDate && tok.kind != tokString {
+			return tok, p.errorf(tok, "expected a date (YYYY-MM-DD) for %s, got %s", cmp.name, tok.describe())
+		}
+		if _, err := time.Parse("2006-01-02", tok.text); err != nil {
+			return tok, p.errorf(tok, "invalid date %q (expected YYYY-MM-DD)", tok.text)
+		}
+		tok.kind = tokDate
+
+	case filterText, filterTag:
+		if tok.kind != tokString {
+			return tok, p.errorf(tok, "expected a quoted string for %s, got %s", cmp.name, tok.describe())
+		}
+		if cmp.field.kind == filterTag {
+			tok.text = strings.ToLower(strings.TrimSpace(tok.text))
+		}
+
+	case filterRef:
+		if tok.kind == tokNumber {
+			if _, err := strconv.Atoi(tok.text); err != nil {
+				return tok, p.errorf(tok, "expected a whole number ID for %s, got %s", cmp.name, tok.text)
+			}
+		} else if tok.kind != tokString {
+			return tok, p.errorf(tok, "expected an ID or a quoted name for %s, got %s", cmp.name, tok.describe())
+		}
+	}
+	return tok, nil
+}
+
+// ============================================
+// COMPILER
+// ============================================
+
+type filterCompiler struct {
+	params []string
+}
+
+// param binds a value and returns the SQL that reads it, cast to sqlType
+func (c *filterCompiler) param(value, sqlType string) string {
+	c.params = append(c.params, value)
+	ref := fmt.Sprintf("(%s->>%d)", filterParamsRef, len(c.params)-1)
+	if sqlType == "" {
+		return ref
+	}
+	return ref + "::" + sqlType
+}
+
+func (c *filterCompiler) compile(node filterNode) string {
+	switch n := node.(type) {
+	case *filterLogical:
+		return "(" + c.compile(n.left) + " " + strings.ToUpper(n.op) + " " + c.compile(n.right) + ")"
+	case *filterNot:
+		return "(NOT " + c.compile(n.expr) + ")"
+	case *filterComparison:
+		return c.compileComparison(n)
+	}
+	panic(fmt.Sprintf("unexpected filter node %T", node))
+}
+
+// compileComparison compiles != and not in as the negation of = and in, where
+// an unknown (null) result counts as no match; != therefore matches rows where
+// the field is empty, e.g. uncategorized expenses for category != 3.
+func (c *filterCompiler) compileComparison(cmp *filterComparison) string {
+	switch cmp.op {
+	case "is null":
+		return c.compileIsNull(cmp)
+	case "is not null":
+		return "(NOT " + c.compileIsNull(cmp) + ")"
+	case "!=":
+		return "(NOT COALESCE(" + c.compileMatch(cmp, "=") + ", FALSE))"
+	case "not in":
+		return "(NOT COALESCE(" + c.compileMatch(cmp, "in") + ", FALSE))"
+	}
+	return c.compileMatch(cmp, cmp.op)
+}
+
+func (c *filterCompiler) compileIsNull(cmp *filterComparison) string {
+	switch {
+	case cmp.field.kind == filterTag:
+		return "(NOT EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id))"
+	case cmp.name == "account":
+		return "(t.account_id IS NULL AND t.transfer_account_id IS NULL)"
+	}
+	return "(" + cmp.field.column + " IS NULL)"
+}
+
+// compileMatch compiles =, in, contains and the ordering operators
+func (c *filterCompiler) compileMatch(cmp *filterComparison, op string) string {
+	switch cmp.field.kind {
+	case filterNumber, filterDate:
+		sqlType := "NUMERIC"
+		if cmp.field.kind == filterDate {
+			sqlType = "DATE"
+		}
+		if op == "in" {
+			refs := make([]string, len(cmp.values))
+			for i, v := range cmp.values {
+				refs[i] = c.param(v.text, sqlType)
+			}
+			return "(" + cmp.field.column + " IN (" + strings.Join(refs, ", ") + "))"
+		}
+		return "(" + cmp.field.column + " " + op + " " + c.param(cmp.values[0].text, sqlType) + ")"
+
+	case filterText:
+		if op == "contains" {
+			return "(" + cmp.field.column + " ILIKE '%' || " + c.param(escapeLikePattern(cmp.values[0].text), "") + " || '%')"
+		}
+		refs := make([]string, len(cmp.values))
+		for i, v := range cmp.values {
+			refs[i] = "lower(" + c.param(v.text, "") + ")"
+		}
+		return "(lower(" + cmp.field.column + ") IN (" + strings.Join(refs, ", ") + "))"
+
+	case filterTag:
+		refs := make([]string, len(cmp.values))
+		for i, v := range cmp.values {
+			refs[i] = c.param(v.text, "")
+		}
+		return "(EXISTS (SELECT 1 FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id" +
+			" WHERE tt.transaction_id = t.id AND tg.name IN (" + strings.Join(refs, ", ") + ")))"
+	}
+
+	// References match any of the values, each an ID or a name
+	conds := make([]string, len(cmp.values))
+	for i, v := range cmp.values {
+		conds[i] = c.compileRef(cmp, v)
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+func (c *filterCompiler) compileRef(cmp *filterComparison, value filterToken) string {
+	var ids string
+	if value.kind == tokNumber {
+		ids = c.param(value.text, "INT")
+	} else {
+		name := c.param(value.text, "")
+		switch cmp.name {
+		case "category":
+			ids = "SELECT c.id FROM expense_categories c WHERE lower(c.name) = lower(" + name + ")"
+		case "merchant":
+			ids = "SELECT m.id FROM merchants m WHERE m.user_id = t.user_id AND lower(m.name) = lower(" + name + ")"
+		case "account":
+			ids = "SELECT a.id FROM accounts a WHERE a.user_id = t.user_id AND a.deleted_at IS NULL" +
+				" AND lower(a.name) = lower(" + name + ")"
+		}
+	}
+
+	// Transfers belong to both of their accounts
+	if cmp.name == "account" {
+		return "(t.account_id IN (" + ids + ") OR t.transfer_account_id IN (" + ids + "))"
+	}
+	return "(" + cmp.field.column + " IN (" + ids + "))"
+}
+
+// escapeLikePattern makes % and _ match literally in an ILIKE pattern
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// CompileExpenseFilter parses a filter expression and compiles it to a SQL
+// condition on the transaction row t with its parameters. Invalid expressions
+// return a *FilterSyntaxError.
+func CompileExpenseFilter(src string) (string, []string, error) {
+	node, err := parseFilterExpression(src)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c := &filterCompiler{}
+	return c.compile(node), c.params, nil
+}
@@ -0,0 +1,301 @@
+package hlpFeatureOne
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileExpenseFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		wantSQL    string
+		wantParams []string
+	}{
+		{
+			name:       "number ordering",
+			src:        "amount > 500",
+			wantSQL:    "(t.amount > ($3->>0)::NUMERIC)",
+			wantParams: []string{"500"},
+		},
+		{
+			name:       "date ordering",
+			src:        "date >= 2026-01-01",
+			wantSQL:    "(t.date >= ($3->>0)::DATE)",
+			wantParams: []string{"2026-01-01"},
+		},
+		{
+			name:       "quoted date",
+			src:        `date < "2026-02-01"`,
+			wantSQL:    "(t.date < ($3->>0)::DATE)",
+			wantParams: []string{"2026-02-01"},
+		},
+		{
+			name:       "== is =",
+			src:        "amount == 5",
+			wantSQL:    "(t.amount = ($3->>0)::NUMERIC)",
+			wantParams: []string{"5"},
+		},
+		{
+			name:       "<> is != and counts null as no match",
+			src:        "amount <> 5",
+			wantSQL:    "(NOT COALESCE((t.amount = ($3->>0)::NUMERIC), FALSE))",
+			wantParams: []string{"5"},
+		},
+		{
+			name:       "number list",
+			src:        "amount in (1, 2.5)",
+			wantSQL:    "(t.amount IN (($3->>0)::NUMERIC, ($3->>1)::NUMERIC))",
+			wantParams: []string{"1", "2.5"},
+		},
+		{
+			name:       "text equality ignores case",
+			src:        `title = "Lunch"`,
+			wantSQL:    "(lower(t.title) IN (lower(($3->>0))))",
+			wantParams: []string{"Lunch"},
+		},
+		{
+			name:       "text not in",
+			src:        `title not in ('a', 'b')`,
+			wantSQL:    "(NOT COALESCE((lower(t.title) IN (lower(($3->>0)), lower(($3->>1)))), FALSE))",
+			wantParams: []string{"a", "b"},
+		},
+		{
+			name:       "contains escapes like wildcards",
+			src:        `notes contains "50%_off\\"`,
+			wantSQL:    "(t.notes ILIKE '%' || ($3->>0) || '%')",
+			wantParams: []string{`50\%\_off\\`},
+		},
+		{
+			name:       "reference by ID or name",
+			src:        `category in (1, "Food")`,
+			wantSQL:    "((t.category_id IN (($3->>0)::INT)) OR (t.category_id IN (SELECT c.id FROM expense_categories c WHERE lower(c.name) = lower(($3->>1)))))",
+			wantParams: []string{"1", "Food"},
+		},
+		{
+			name:       "reference !=",
+			src:        "category != 3",
+			wantSQL:    "(NOT COALESCE(((t.category_id IN (($3->>0)::INT))), FALSE))",
+			wantParams: []string{"3"},
+		},
+		{
+			name:       "merchant name is scoped to the user",
+			src:        `merchant = "Cafe"`,
+			wantSQL:    "((t.merchant_id IN (SELECT m.id FROM merchants m WHERE m.user_id = t.user_id AND lower(m.name) = lower(($3->>0)))))",
+			wantParams: []string{"Cafe"},
+		},
+		{
+			name:       "account matches both sides of a transfer",
+			src:        "account = 2",
+			wantSQL:    "((t.account_id IN (($3->>0)::INT) OR t.transfer_account_id IN (($3->>0)::INT)))",
+			wantParams: []string{"2"},
+		},
+		{
+			name:       "tag is normalized",
+			src:        `tag = " Travel "`,
+			wantSQL:    "(EXISTS (SELECT 1 FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.transaction_id = t.id AND tg.name IN (($3->>0))))",
+			wantParams: []string{"travel"},
+		},
+		{
+			name:    "is null",
+			src:     "notes is null",
+			wantSQL: "(t.notes IS NULL)",
+		},
+		{
+			name:    "tags is not null",
+			src:     "tags is not null",
+			wantSQL: "(NOT (NOT EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id)))",
+		},
+		{
+			name:    "account is null",
+			src:     "account is null",
+			wantSQL: "(t.account_id IS NULL AND t.transfer_account_id IS NULL)",
+		},
+		{
+			name:       "and binds tighter than or",
+			src:        "amount > 1 or amount < 5 and date = 2026-02-01",
+			wantSQL:    "((t.amount > ($3->>0)::NUMERIC) OR ((t.amount < ($3->>1)::NUMERIC) AND (t.date = ($3->>2)::DATE)))",
+			wantParams: []string{"1", "5", "2026-02-01"},
+		},
+		{
+			name:       "not and parentheses, keywords in any case",
+			src:        "AMOUNT > 1 And NOT (amount < 5 OR date = 2026-02-01)",
+			wantSQL:    "((t.amount > ($3->>0)::NUMERIC) AND (NOT ((t.amount < ($3->>1)::NUMERIC) OR (t.date = ($3->>2)::DATE))))",
+			wantParams: []string{"1", "5", "2026-02-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, params, err := CompileExpenseFilter(tt.src)
+			if err != nil {
+				t.Fatalf("CompileExpenseFilter(%q) error: %v", tt.src, err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql:\n got  %s\n want %s", sql, tt.wantSQL)
+			}
+			if len(params) != 0 || len(tt.wantParams) != 0 {
+				if !reflect.DeepEqual(params, tt.wantParams) {
+					t.Errorf("params = %q, want %q", params, tt.wantParams)
+				}
+			}
+		})
+	}
+}
+
+// Whatever a value contains, it must only reach the query as a bound parameter
+func TestCompileExpenseFilterBindsValues(t *testing.T) {
+	payloads := []string{
+		`x'); DROP TABLE users; --`,
+		`' OR '1'='1`,
+		`$3; SELECT pg_sleep(10)`,
+		`\'`,
+		`Robert"); DELETE FROM transactions; --`,
+	}
+	fields := []string{"title", "notes", "category", "merchant", "account", "tag"}
+
+	for _, payload := range payloads {
+		quoted := `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(payload) + `"`
+		for _, field := range fields {
+			for _, op := range []string{"=", "!=", "in", "contains"} {
+				if op == "contains" && field != "title" && field != "notes" {
+					continue
+				}
+				value := quoted
+				if op == "in" {
+					value = "(" + quoted + ", 'plain')"
+				}
+				src := field + " " + op + " " + value
+
+				sql, params, err := CompileExpenseFilter(src)
+				if err != nil {
+					t.Fatalf("CompileExpenseFilter(%q) error: %v", src, err)
+				}
+
+				// The only literals the compiler writes are the '%' around contains
+				if rest := strings.ReplaceAll(sql, "'%'", ""); strings.ContainsAny(rest, `'";`) {
+					t.Errorf("CompileExpenseFilter(%q) wrote a literal into the SQL: %s", src, sql)
+				}
+				if strings.Contains(sql, payload) {
+					t.Errorf("CompileExpenseFilter(%q) leaked the value into the SQL: %s", src, sql)
+				}
+
+				found := false
+				for _, param := range params {
+					if param == payload || param == strings.ToLower(strings.TrimSpace(payload)) ||
+						param == escapeLikePattern(payload) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("CompileExpenseFilter(%q) params %q do not carry the value", src, params)
+				}
+			}
+		}
+	}
+}
+
+func TestCompileExpenseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantPos int
+		wantMsg string
+	}{
+		{"empty", "", 1, "filter is empty"},
+		{"blank", "   ", 4, "filter is empty"},
+		{"unknown field", "foo = 1", 1, `unknown field "foo"`},
+		{"column name is not a field", "user_id = 1", 1, `unknown field "user_id"`},
+		{"value where a field belongs", "1 = amount", 1, "expected a field"},
+		{"missing operator", "amount", 7, "expected an operator after amount"},
+		{"contains on a number", "amount contains 'x'", 8, "operator contains is not supported for amount"},
+		{"ordering on text", "title > 'a'", 7, "operator > is not supported for title"},
+		{"is null on a required field", "amount is null", 8, "operator is null is not supported for amount"},
+		{"is without null", "notes is 1", 10, "expected null after is"},
+		{"not without in", "amount not 1", 12, "expected in after not"},
+		{"compare with null", "title = null", 9, "use title is null"},
+		{"string for a number", "amount > 'x'", 10, "expected a number for amount"},
+		{"bare word for text", "title = lunch", 9, "expected a quoted string for title"},
+		{"impossible date", "date = 2026-13-01", 8, `invalid date "2026-13-01"`},
+		{"number for a date", "date = 5", 8, "expected a date"},
+		{"fractional ID", "category = 1.5", 12, "expected a whole number ID"},
+		{"bad number", "amount > 1.2.3", 10, `invalid number "1.2.3"`},
+		{"unterminated string", "title = 'abc", 9, "unterminated string"},
+		{"trailing backslash", `title = "abc\`, 9, "unterminated string"},
+		{"lone bang", "amount ! 1", 8, `unexpected "!"`},
+		{"statement separator", "amount > 1; DROP TABLE users", 11, `unexpected character ';'`},
+		{"comment", "amount > 1 -- x", 12, `unexpected character '-'`},
+		{"unmatched )", "amount > 1 )", 12, "unmatched )"},
+		{"unclosed (", "(amount > 1", 12, "expected ) to close ( at position 1"},
+		{"dangling and", "amount > 1 and", 15, "expected a field"},
+		{"missing and", "amount > 1 amount < 5", 12, "expected and, or or end of filter"},
+		{"list without (", "amount in 1", 11, "expected ( after in"},
+		{"list without separator", "amount in (1 2)", 14, "expected , or ) in list"},
+		{"empty list", "amount in ()", 12, "expected a number for amount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterError(t, tt.src, tt.wantPos, tt.wantMsg)
+		})
+	}
+}
+
+func TestCompileExpenseFilterLimits(t *testing.T) {
+	t.Run("length", func(t *testing.T) {
+		assertFilterError(t, strings.Repeat("a", maxFilterLength+1), maxFilterLength+1, "at most 1000 characters")
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		src := strings.Repeat("(", maxFilterDepth+1) + "amount > 1" + strings.Repeat(")", maxFilterDepth+1)
+		assertFilterError(t, src, maxFilterDepth+1, "nested more than 20 levels")
+
+		ok := strings.Repeat("(", maxFilterDepth) + "amount > 1" + strings.Repeat(")", maxFilterDepth)
+		if _, _, err := CompileExpenseFilter(ok); err != nil {
+			t.Errorf("%d levels should compile: %v", maxFilterDepth, err)
+		}
+	})
+
+	t.Run("comparisons", func(t *testing.T) {
+		terms := make([]string, maxFilterTerms+1)
+		for i := range terms {
+			terms[i] = "amount > 1"
+		}
+		src := strings.Join(terms, " or ")
+		assertFilterError(t, src, len(src)-len("amount > 1")+1, "more than 50 comparisons")
+
+		if _, _, err := CompileExpenseFilter(strings.Join(terms[1:], " or ")); err != nil {
+			t.Errorf("%d comparisons should compile: %v", maxFilterTerms, err)
+		}
+	})
+
+	t.Run("list values", func(t *testing.T) {
+		values := make([]string, maxFilterInValues+1)
+		for i := range values {
+			values[i] = "1"
+		}
+		src := "amount in (" + strings.Join(values, ",") + ")"
+		assertFilterError(t, src, len(src)-1, "at most 100 values")
+	})
+}
+
+func assertFilterError(t *testing.T, src string, wantPos int, wantMsg string) {
+	t.Helper()
+
+	sql, params, err := CompileExpenseFilter(src)
+	if err == nil {
+		t.Fatalf("CompileExpenseFilter(%q) = %q, %q; want an error", src, sql, params)
+	}
+	var syntaxErr *FilterSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("CompileExpenseFilter(%q) error %v is not a *FilterSyntaxError", src, err)
+	}
+	if syntaxErr.Pos != wantPos {
+		t.Errorf("CompileExpenseFilter(%q) position = %d, want %d (%s)", src, syntaxErr.Pos, wantPos, syntaxErr.Msg)
+	}
+	if !strings.Contains(syntaxErr.Msg, wantMsg) {
+		t.Errorf("CompileExpenseFilter(%q) message = %q, want it to contain %q", src, syntaxErr.Msg, wantMsg)
+	}
+}
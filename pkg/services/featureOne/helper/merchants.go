@@ -0,0 +1,121 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxMerchantName     = 100
+	maxRuleName         = 100
+	maxRulePattern      = 255
+	defaultRulePriority = 100
+)
+
+// NormalizeMerchantName trims a merchant name and collapses inner whitespace, so
+// "Jollibee  Makati" and "Jollibee Makati" are the same merchant
+func NormalizeMerchantName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ValidateMerchantName checks a merchant name before it is normalised and stored
+func ValidateMerchantName(name string) error {
+	name = NormalizeMerchantName(name)
+	if name == "" {
+		return fmt.Errorf("merchant name is required")
+	}
+	if utf8.RuneCountInString(name) > maxMerchantName {
+		return fmt.Errorf("merchant name must be at most %d characters", maxMerchantName)
+	}
+	return nil
+}
+
+// ValidateCategorizationRule checks a rule and fills in its defaults. Regex
+// patterns use PostgreSQL syntax, so the caller still checks they compile.
+func ValidateCategorizationRule(req *mdlFeatureOne.CategorizationRuleRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(req.Name) > maxRuleName {
+		return fmt.Errorf("name must be at most %d characters", maxRuleName)
+	}
+
+	if req.Priority == nil {
+		priority := defaultRulePriority
+		req.Priority = &priority
+	}
+	if req.IsActive == nil {
+		active := true
+		req.IsActive = &active
+	}
+
+	if req.MatchField == "" {
+		req.MatchField = "title"
+	}
+	if req.MatchField != "title" && req.MatchField != "merchant" {
+		return fmt.Errorf("matchField must be title or merchant")
+	}
+
+	switch req.MatchType {
+	case "contains", "regex":
+		if req.Pattern == nil || strings.TrimSpace(*req.Pattern) == "" {
+			return fmt.Errorf("pattern is required for %s rules", req.MatchType)
+		}
+		if utf8.RuneCountInString(*req.Pattern) > maxRulePattern {
+			return fmt.Errorf("pattern must be at most %d characters", maxRulePattern)
+		}
+	case "amount_range":
+		if req.MinAmount == nil && req.MaxAmount == nil {
+			return fmt.Errorf("minAmount or maxAmount is required for amount_range rules")
+		}
+		req.Pattern = nil
+	default:
+		return fmt.Errorf("matchType must be contains, regex or amount_range")
+	}
+
+	if req.MinAmount != nil && *req.MinAmount < 0 {
+		return fmt.Errorf("minAmount must not be negative")
+	}
+	if req.MaxAmount != nil && *req.MaxAmount < 0 {
+		return fmt.Errorf("maxAmount must not be negative")
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return fmt.Errorf("minAmount must not be greater than maxAmount")
+	}
+
+	if req.SetNotes != nil && strings.TrimSpace(*req.SetNotes) == "" {
+		req.SetNotes = nil
+	}
+	if err := ValidateTags(req.SetTags); err != nil {
+		return err
+	}
+	req.SetTags = NormalizeTags(req.SetTags)
+	if req.SetCategoryID == nil && len(req.SetTags) == 0 && req.SetNotes == nil {
+		return fmt.Errorf("a rule must set a category, tags or notes")
+	}
+	return nil
+}
+
+// ValidateApplyRules checks the date range of a rules job
+func ValidateApplyRules(req *mdlFeatureOne.ApplyRulesRequest) error {
+	var start, end time.Time
+	var err error
+	if req.StartDate != nil {
+		if start, err = time.Parse("2006-01-02", *req.StartDate); err != nil {
+			return fmt.Errorf("invalid startDate format (expected YYYY-MM-DD)")
+		}
+	}
+	if req.EndDate != nil {
+		if end, err = time.Parse("2006-01-02", *req.EndDate); err != nil {
+			return fmt.Errorf("invalid endDate format (expected YYYY-MM-DD)")
+		}
+	}
+	if req.StartDate != nil && req.EndDate != nil && end.Before(start) {
+		return fmt.Errorf("endDate must not be before startDate")
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrencePeriods guards against runaway loops for rules without an end.
+const maxRecurrencePeriods = 100000
+
+// Recurrence describes when a recurring expense falls due.
+type Recurrence struct {
+	Freq       string         // daily, weekly, monthly, yearly
+	Interval   int            // every N periods
+	ByDay      []time.Weekday // weekly only, defaults to the start date's weekday
+	ByMonthDay int            // monthly only, -1 = last day, 0 = start date's day
+	Count      int            // total occurrences, 0 = unlimited
+	Start      time.Time
+	End        *time.Time
+}
+
+var rruleFreqs = map[string]string{
+	"DAILY":   "daily",
+	"WEEKLY":  "weekly",
+	"MONTHLY": "monthly",
+	"YEARLY":  "yearly",
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ValidateCreateRecurringExpense validates a new recurring expense rule
+func ValidateCreateRecurringExpense(req *mdlFeatureOne.CreateRecurringExpenseRequest) error {
+	if strings.TrimSpace(req.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if strings.TrimSpace(req.StartDate) == "" {
+		return fmt.Errorf("startDate is required")
+	}
+	_, err := BuildRecurrence(req.Frequency, req.Interval, req.DayOfMonth, req.RRule, req.StartDate, req.EndDate)
+	return err
+}
+
+// BuildRecurrence turns the stored rule columns into a Recurrence
+func BuildRecurrence(frequency string, interval int, dayOfMonth *int, rrule *string, startDate string, endDate *string) (*Recurrence, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate format (expected YYYY-MM-DD)")
+	}
+
+	r := &Recurrence{
+		Freq:     strings.ToLower(strings.TrimSpace(frequency)),
+		Interval: interval,
+		Start:    start,
+	}
+
+	if endDate != nil && strings.TrimSpace(*endDate) != "" {
+		end, err := time.Parse("2006-01-02", *endDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endDate format (expected YYYY-MM-DD)")
+		}
+		if end.Before(start) {
+			return nil, fmt.Errorf("endDate must not be before startDate")
+		}
+		r.End = &end
+	}
+
+	switch r.Freq {
+	case "daily", "weekly", "yearly":
+	case "monthly":
+		if dayOfMonth != nil {
+			r.ByMonthDay = *dayOfMonth
+		}
+	case "custom":
+		if rrule == nil || strings.TrimSpace(*rrule) == "" {
+			return nil, fmt.Errorf("rrule is required for custom frequency")
+		}
+		if err := r.applyRRule(*rrule); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("frequency must be one of daily, weekly, monthly, yearly or custom")
+	}
+
+	if r.Interval < 1 {
+		return nil, fmt.Errorf("interval must be at least 1")
+	}
+	if r.ByMonthDay < -1 || r.ByMonthDay > 31 {
+		return nil, fmt.Errorf("dayOfMonth must be between 1 and 31, or -1 for the last day")
+	}
+	if r.Freq == "weekly" && len(r.ByDay) == 0 {
+		r.ByDay = []time.Weekday{start.Weekday()}
+	}
+
+	return r, nil
+}
+
+// applyRRule parses the supported RRULE subset:
+// FREQ, INTERVAL, BYDAY (weekly), BYMONTHDAY (monthly), COUNT and UNTIL.
+func (r *Recurrence) applyRRule(rule string) error {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r.Freq = ""
+
+	for _, part := range strings.Split(rule, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid rrule part '%s'", part)
+		}
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		val := strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch key {
+		case "FREQ":
+			freq, ok := rruleFreqs[val]
+			if !ok {
+				return fmt.Errorf("unsupported rrule FREQ '%s'", val)
+			}
+			r.Freq = freq
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid rrule INTERVAL '%s'", val)
+			}
+			r.Interval = n
+		case "BYDAY":
+			r.ByDay = nil
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := rruleWeekdays[strings.TrimSpace(d)]
+				if !ok {
+					return fmt.Errorf("unsupported rrule BYDAY '%s'", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(val)
+			if err != nil || n == 0 || n < -1 || n > 31 {
+				return fmt.Errorf("invalid rrule BYMONTHDAY '%s'", val)
+			}
+			r.ByMonthDay = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid rrule COUNT '%s'", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseRRuleDate(val)
+			if err != nil {
+				return fmt.Errorf("invalid rrule UNTIL '%s'", val)
+			}
+			if r.End == nil || until.Before(*r.End) {
+				r.End = &until
+			}
+		default:
+			return fmt.Errorf("unsupported rrule part '%s'", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return fmt.Errorf("rrule FREQ is required")
+	}
+	if len(r.ByDay) > 0 && r.Freq != "weekly" {
+		return fmt.Errorf("rrule BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if r.ByMonthDay != 0 && r.Freq != "monthly" {
+		return fmt.Errorf("rrule BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return nil
+}
+
+func parseRRuleDate(val string) (time.Time, error) {
+	if len(val) >= 8 {
+		return time.Parse("20060102", val[:8])
+	}
+	return time.Time{}, fmt.Errorf("invalid date")
+}
+
+// Occurrences returns the due dates within [from, to], at most limit of them
+func (r *Recurrence) Occurrences(from, to time.Time, limit int) []time.Time {
+	var dates []time.Time
+	r.each(func(d time.Time) bool {
+		if d.After(to) || len(dates) >= limit {
+			return false
+		}
+		if !d.Before(from) {
+			dates = append(dates, d)
+		}
+		return true
+	})
+	return dates
+}
+
+// Upcoming returns the next n due dates on or after from
+func (r *Recurrence) Upcoming(from time.Time, n int) []time.Time {
+	var dates []time.Time
+	r.each(func(d time.Time) bool {
+		if len(dates) >= n {
+			return false
+		}
+		if !d.Before(from) {
+			dates = append(dates, d)
+		}
+		return true
+	})
+	return dates
+}
+
+// NextAfter returns the first due date strictly after the given date
+func (r *Recurrence) NextAfter(after time.Time) (time.Time, bool) {
+	next := r.Upcoming(after.AddDate(0, 0, 1), 1)
+	if len(next) == 0 {
+		return time.Time{}, false
+	}
+	return next[0], true
+}
+
+// each walks every occurrence in order until fn returns false or the rule ends
+func (r *Recurrence) each(fn func(time.Time) bool) {
+	seen := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		for _, d := range r.periodDates(period) {
+			if d.Before(r.Start) {
+				continue
+			}
+			if r.End != nil && d.After(*r.End) {
+				return
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return
+			}
+			if !fn(d) {
+				return
+			}
+		}
+	}
+}
+
+// periodDates returns the candidate dates of the n-th period, in order
+func (r *Recurrence) periodDates(n int) []time.Time {
+	step := n * r.Interval
+	start := r.Start
+
+	switch r.Freq {
+	case "daily":
+		return []time.Time{start.AddDate(0, 0, step)}
+	case "weekly":
+		weekStart := start.AddDate(0, 0, -int(start.Weekday())+7*step)
+		days := append([]time.Weekday(nil), r.ByDay...)
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		dates := make([]time.Time, 0, len(days))
+		for _, wd := range days {
+			dates = append(dates, weekStart.AddDate(0, 0, int(wd)))
+		}
+		return dates
+	case "monthly":
+		day := r.ByMonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		return []time.Time{clampedDate(start.Year(), start.Month()+time.Month(step), day)}
+	case "yearly":
+		return []time.Time{clampedDate(start.Year()+step, start.Month(), start.Day())}
+	}
+	return nil
+}
+
+// clampedDate builds a date, moving days past the end of the month (or -1) to its last day
+func clampedDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day == -1 || day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// NextRunDate returns the first due date on or after notBefore as YYYY-MM-DD,
+// or nil when the rule has no occurrences left
+func NextRunDate(r *Recurrence, notBefore time.Time) *string {
+	next := r.Upcoming(notBefore, 1)
+	if len(next) == 0 {
+		return nil
+	}
+	date := next[0].Format("2006-01-02")
+	return &date
+}
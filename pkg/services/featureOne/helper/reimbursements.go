@@ -0,0 +1,123 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxReimbursementTitle    = 200
+	maxReimbursementComment  = 1000
+	maxReimbursementExpenses = 500
+)
+
+// reimbursementTransitions maps a report status to the statuses it can move to
+// and the role allowed to make each move
+var reimbursementTransitions = map[string]map[string]string{
+	mdlFeatureOne.ReimbursementDraft: {
+		mdlFeatureOne.ReimbursementSubmitted: mdlFeatureOne.ReimbursementRoleOwner,
+	},
+	mdlFeatureOne.ReimbursementSubmitted: {
+		mdlFeatureOne.ReimbursementApproved: mdlFeatureOne.ReimbursementRoleApprover,
+		mdlFeatureOne.ReimbursementRejected: mdlFeatureOne.ReimbursementRoleApprover,
+		mdlFeatureOne.ReimbursementDraft:    mdlFeatureOne.ReimbursementRoleOwner, // recall
+	},
+	mdlFeatureOne.ReimbursementRejected: {
+		mdlFeatureOne.ReimbursementDraft: mdlFeatureOne.ReimbursementRoleOwner, // reopen
+	},
+	mdlFeatureOne.ReimbursementApproved: {
+		mdlFeatureOne.ReimbursementPaid: mdlFeatureOne.ReimbursementRoleApprover,
+	},
+}
+
+// ValidReimbursementStatus reports whether status is a report status
+func ValidReimbursementStatus(status string) bool {
+	switch status {
+	case mdlFeatureOne.ReimbursementDraft, mdlFeatureOne.ReimbursementSubmitted,
+		mdlFeatureOne.ReimbursementApproved, mdlFeatureOne.ReimbursementRejected,
+		mdlFeatureOne.ReimbursementPaid:
+		return true
+	}
+	return false
+}
+
+// ReimbursementTransitionRole returns the role that may move a report from one
+// status to another, or false when the move is not allowed at all
+func ReimbursementTransitionRole(from, to string) (string, bool) {
+	role, ok := reimbursementTransitions[from][to]
+	return role, ok
+}
+
+// ReimbursementReportDeletable reports whether the owner can still delete a report;
+// approved and paid reports are kept as the record of the claim
+func ReimbursementReportDeletable(status string) bool {
+	return status == mdlFeatureOne.ReimbursementDraft || status == mdlFeatureOne.ReimbursementRejected
+}
+
+// ValidateReimbursementReport checks a report and removes duplicate expense IDs
+func ValidateReimbursementReport(req *mdlFeatureOne.ReimbursementReportRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if utf8.RuneCountInString(req.Title) > maxReimbursementTitle {
+		return fmt.Errorf("title must be at most %d characters", maxReimbursementTitle)
+	}
+	req.Description = trimmedOrNil(req.Description)
+
+	req.ApproverEmail = trimmedOrNil(req.ApproverEmail)
+	if req.ApproverID != nil && req.ApproverEmail != nil {
+		return fmt.Errorf("provide either approverId or approverEmail, not both")
+	}
+
+	if len(req.ExpenseIDs) > maxReimbursementExpenses {
+		return fmt.Errorf("a report can claim at most %d expenses", maxReimbursementExpenses)
+	}
+	seen := make(map[int]bool, len(req.ExpenseIDs))
+	ids := make([]int, 0, len(req.ExpenseIDs))
+	for _, id := range req.ExpenseIDs {
+		if id <= 0 {
+			return fmt.Errorf("expense IDs must be positive")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	req.ExpenseIDs = ids
+	return nil
+}
+
+// ValidateReimbursementTransition checks the target status and comment of a transition
+func ValidateReimbursementTransition(req *mdlFeatureOne.ReimbursementTransitionRequest) error {
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if !ValidReimbursementStatus(req.Status) {
+		return fmt.Errorf("status must be one of draft, submitted, approved, rejected or paid")
+	}
+
+	req.Comment = trimmedOrNil(req.Comment)
+	if req.Comment != nil && utf8.RuneCountInString(*req.Comment) > maxReimbursementComment {
+		return fmt.Errorf("comment must be at most %d characters", maxReimbursementComment)
+	}
+	if req.Status == mdlFeatureOne.ReimbursementRejected && req.Comment == nil {
+		return fmt.Errorf("a comment is required when rejecting a report")
+	}
+	return nil
+}
+
+// ValidateReimbursementReportFilters defaults the role to owner and checks the status
+func ValidateReimbursementReportFilters(filters *mdlFeatureOne.ReimbursementReportFilters) error {
+	filters.Role = strings.ToLower(filters.Role)
+	if filters.Role == "" {
+		filters.Role = mdlFeatureOne.ReimbursementRoleOwner
+	}
+	if filters.Role != mdlFeatureOne.ReimbursementRoleOwner && filters.Role != mdlFeatureOne.ReimbursementRoleApprover {
+		return fmt.Errorf("role must be owner or approver")
+	}
+	if filters.Status != nil && !ValidReimbursementStatus(*filters.Status) {
+		return fmt.Errorf("status must be one of draft, submitted, approved, rejected or paid")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"time"
+)
+
+// maxReportDays bounds a report period so day series stay a sensible size
+const maxReportDays = 366 * 5
+
+// ValidReportInterval reports whether interval is a supported time-series bucket
+func ValidReportInterval(interval string) bool {
+	switch interval {
+	case "day", "week", "month", "year":
+		return true
+	}
+	return false
+}
+
+// ApplyReportPeriod defaults the date range to the current month up to today and
+// validates it. Paging and sorting do not apply to reports and are cleared so they
+// do not split the cache.
+func ApplyReportPeriod(filters *mdlFeatureOne.ExpenseFilters, today time.Time) (*mdlFeatureOne.ReportPeriod, error) {
+	if filters.StartDate == nil {
+		start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		filters.StartDate = &start
+	}
+	if filters.EndDate == nil {
+		end := today.Format("2006-01-02")
+		filters.EndDate = &end
+	}
+
+	start, err := time.Parse("2006-01-02", *filters.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate format (expected YYYY-MM-DD)")
+	}
+	end, err := time.Parse("2006-01-02", *filters.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endDate format (expected YYYY-MM-DD)")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("endDate must not be before startDate")
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days > maxReportDays {
+		return nil, fmt.Errorf("report period must not exceed %d days", maxReportDays)
+	}
+
+	filters.Limit = 0
+	filters.Offset = 0
+	filters.SortBy = ""
+	filters.Order = ""
+	filters.CursorToken = ""
+	filters.Cursor = nil
+
+	return &mdlFeatureOne.ReportPeriod{
+		StartDate: *filters.StartDate,
+		EndDate:   *filters.EndDate,
+		Days:      days,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	maxSplitParticipants = 50
+	maxSplitShares       = 1000000
+)
+
+// ValidSplitMethod checks the split method
+func ValidSplitMethod(method string) bool {
+	switch method {
+	case "equal", "exact", "percentage", "shares":
+		return true
+	}
+	return false
+}
+
+// ValidateSetSplit checks a split request against the amount of the expense
+func ValidateSetSplit(req *mdlFeatureOne.SetSplitRequest, amount float64) error {
+	req.Method = strings.ToLower(strings.TrimSpace(req.Method))
+	if !ValidSplitMethod(req.Method) {
+		return fmt.Errorf("method must be one of equal, exact, percentage or shares")
+	}
+	if len(req.Participants) == 0 {
+		return fmt.Errorf("at least one participant is required")
+	}
+	if len(req.Participants) > maxSplitParticipants {
+		return fmt.Errorf("at most %d participants are allowed", maxSplitParticipants)
+	}
+
+	var sum float64
+	var sumCents int64
+	for i, p := range req.Participants {
+		hasID := p.UserID != nil
+		hasEmail := p.Email != nil && strings.TrimSpace(*p.Email) != ""
+		if hasID == hasEmail {
+			return fmt.Errorf("participant %d: exactly one of userId or email is required", i+1)
+		}
+		if req.Method == "equal" {
+			continue
+		}
+		if p.Value == nil {
+			return fmt.Errorf("participant %d: value is required for %s splits", i+1, req.Method)
+		}
+
+		value := *p.Value
+		switch req.Method {
+		case "exact":
+			cents := math.Round(value * 100)
+			if value <= 0 || math.Abs(value*100-cents) > 1e-6 {
+				return fmt.Errorf("participant %d: exact amount must be positive with at most 2 decimals", i+1)
+			}
+			sumCents += int64(cents)
+		case "percentage":
+			if value <= 0 || value > 100 {
+				return fmt.Errorf("participant %d: percentage must be greater than 0 and at most 100", i+1)
+			}
+		case "shares":
+			if value <= 0 || value > maxSplitShares {
+				return fmt.Errorf("participant %d: shares must be greater than 0 and at most %d", i+1, maxSplitShares)
+			}
+		}
+		sum += value
+	}
+
+	switch req.Method {
+	case "exact":
+		if sumCents != int64(math.Round(amount*100)) {
+			return fmt.Errorf("exact amounts add up to %.2f but the expense is %.2f", float64(sumCents)/100, amount)
+		}
+	case "percentage":
+		if math.Abs(sum-100) > 0.0001 {
+			return fmt.Errorf("percentages add up to %g, not 100", sum)
+		}
+	}
+	return nil
+}
+
+// SplitWeights returns the stored weight of each participant; amounts are
+// derived from the weights in proportion, so every method reduces to them
+func SplitWeights(req *mdlFeatureOne.SetSplitRequest) []float64 {
+	weights := make([]float64, len(req.Participants))
+	for i, p := range req.Participants {
+		if req.Method == "equal" || p.Value == nil {
+			weights[i] = 1
+			continue
+		}
+		weights[i] = *p.Value
+	}
+	return weights
+}
+
+// ValidateSplitParticipants checks the resolved participant IDs: no user twice,
+// and someone other than the payer must owe a share
+func ValidateSplitParticipants(payerID int, userIDs []int) error {
+	seen := make(map[int]bool, len(userIDs))
+	others := 0
+	for _, id := range userIDs {
+		if seen[id] {
+			return fmt.Errorf("user %d is listed more than once", id)
+		}
+		seen[id] = true
+		if id != payerID {
+			others++
+		}
+	}
+	if others == 0 {
+		return fmt.Errorf("at least one participant other than yourself is required")
+	}
+	return nil
+}
+
+// ValidateCreateSettlement checks a settlement recorded by userID, defaulting the date to today
+func ValidateCreateSettlement(req *mdlFeatureOne.CreateSettlementRequest, userID int, today time.Time) error {
+	if req.PayerID <= 0 || req.PayeeID <= 0 {
+		return fmt.Errorf("payerId and payeeId are required")
+	}
+	if req.PayerID == req.PayeeID {
+		return fmt.Errorf("payerId and payeeId must be different users")
+	}
+	if req.PayerID != userID && req.PayeeID != userID {
+		return fmt.Errorf("you must be the payer or the payee of a settlement")
+	}
+	if req.Amount != nil {
+		cents := math.Round(*req.Amount * 100)
+		if *req.Amount <= 0 || math.Abs(*req.Amount*100-cents) > 1e-6 {
+			return fmt.Errorf("amount must be positive with at most 2 decimals")
+		}
+	}
+	if strings.TrimSpace(req.Date) == "" {
+		req.Date = today.Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return fmt.Errorf("invalid date format (expected YYYY-MM-DD)")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package hlpFeatureOne
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"regexp"
+	"strings"
+)
+
+const (
+	DefaultSyncLimit   = 500
+	MaxSyncLimit       = 1000
+	MaxSyncPushChanges = 200
+)
+
+var syncClientIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// syncToken is the payload of an opaque sync token: the last change a client has seen
+type syncToken struct {
+	Seq int64 `json:"q"`
+}
+
+// EncodeSyncToken builds the token a client passes as ?since= to get the changes after seq
+func EncodeSyncToken(seq int64) string {
+	payload, _ := json.Marshal(syncToken{Seq: seq})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeSyncToken returns the change a sync token was issued at
+func DecodeSyncToken(token string) (int64, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sync token")
+	}
+
+	var sync syncToken
+	if err := json.Unmarshal(payload, &sync); err != nil || sync.Seq < 0 {
+		return 0, fmt.Errorf("invalid sync token")
+	}
+	return sync.Seq, nil
+}
+
+func ValidateSyncFilters(filters *mdlFeatureOne.SyncFilters) error {
+	if filters.Limit < 1 || filters.Limit > MaxSyncLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxSyncLimit)
+	}
+	return nil
+}
+
+func ValidateSyncPush(req *mdlFeatureOne.SyncPushRequest) error {
+	if len(req.Changes) == 0 {
+		return fmt.Errorf("no changes provided")
+	}
+	if len(req.Changes) > MaxSyncPushChanges {
+		return fmt.Errorf("at most %d changes can be pushed at once", MaxSyncPushChanges)
+	}
+	return nil
+}
+
+// ValidateSyncChange checks one pushed change; it fails only that change
+func ValidateSyncChange(change *mdlFeatureOne.SyncChange) error {
+	if change.Entity != mdlFeatureOne.SyncEntityExpense {
+		return fmt.Errorf("only expenses can be pushed")
+	}
+
+	if change.ClientID != nil {
+		clientID := strings.ToLower(strings.TrimSpace(*change.ClientID))
+		if !syncClientIDPattern.MatchString(clientID) {
+			return fmt.Errorf("clientId must be a UUID")
+		}
+		change.ClientID = &clientID
+	}
+	if change.ID == nil && change.ClientID == nil {
+		return fmt.Errorf("id or clientId is required")
+	}
+
+	switch change.Operation {
+	case mdlFeatureOne.SyncOperationUpsert:
+		if change.Data == nil {
+			return fmt.Errorf("data is required for an upsert")
+		}
+		return ValidateCreateExpense(SyncCreateExpenseRequest(change.Data))
+	case mdlFeatureOne.SyncOperationDelete:
+		if change.Version == nil {
+			return fmt.Errorf("version is required for a delete")
+		}
+		return nil
+	}
+	return fmt.Errorf("operation must be upsert or delete")
+}
+
+// SyncCreateExpenseRequest turns pushed expense data into a create
+func SyncCreateExpenseRequest(data *mdlFeatureOne.SyncExpenseData) *mdlFeatureOne.CreateExpenseRequest {
+	return &mdlFeatureOne.CreateExpenseRequest{
+		Title:      data.Title,
+		Amount:     data.Amount,
+		CategoryID: data.CategoryID,
+		Merchant:   data.Merchant,
+		AccountID:  data.AccountID,
+		Date:       data.Date,
+		Notes:      data.Notes,
+		Tags:       data.Tags,
+	}
+}
+
+// SyncUpdateExpenseRequest turns pushed expense data into an update that
+// replaces the expense, clearing the optional fields and tags the data leaves out
+func SyncUpdateExpenseRequest(data *mdlFeatureOne.SyncExpenseData) *mdlFeatureOne.UpdateExpenseRequest {
+	req := &mdlFeatureOne.UpdateExpenseRequest{
+		Title:      &data.Title,
+		Amount:     &data.Amount,
+		CategoryID: data.CategoryID,
+		Merchant:   data.Merchant,
+		AccountID:  data.AccountID,
+		Date:       &data.Date,
+		Notes:      data.Notes,
+		Tags:       data.Tags,
+	}
+	if req.Tags == nil {
+		req.Tags = []string{}
+	}
+	if data.CategoryID == nil {
+		req.Clear = append(req.Clear, "categoryId")
+	}
+	if data.Merchant == nil {
+		req.Clear = append(req.Clear, "merchant")
+	}
+	if data.AccountID == nil {
+		req.Clear = append(req.Clear, "accountId")
+	}
+	if data.Notes == nil {
+		req.Clear = append(req.Clear, "notes")
+	}
+	return req
+}
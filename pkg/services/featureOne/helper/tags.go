@@ -0,0 +1,67 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxTagsPerTransaction = 20
+	maxTagLength          = 50
+)
+
+// ValidateTags checks tag names before they are normalised and stored
+func ValidateTags(tags []string) error {
+	normalized := NormalizeTags(tags)
+	if len(normalized) > maxTagsPerTransaction {
+		return fmt.Errorf("at most %d tags are allowed", maxTagsPerTransaction)
+	}
+	for _, tag := range normalized {
+		if err := ValidateTagName(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTagName checks a single tag name
+func ValidateTagName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("tag name is required")
+	}
+	if len(name) > maxTagLength {
+		return fmt.Errorf("tag '%s' is longer than %d characters", name, maxTagLength)
+	}
+	if strings.ContainsAny(name, ",;") {
+		return fmt.Errorf("tag '%s' must not contain commas or semicolons", name)
+	}
+	return nil
+}
+
+// NormalizeTags trims and lower-cases tags, dropping blanks and duplicates
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// SplitTagList splits a "a,b;c" style list (query strings, CSV cells) into tags
+func SplitTagList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+}
+
+// ValidTagMatch reports whether m is a supported tag match mode
+func ValidTagMatch(m string) bool {
+	return m == "" || m == "any" || m == "all"
+}
@@ -0,0 +1,96 @@
+package hlpFeatureOne
+
+import (
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"strings"
+	"time"
+)
+
+// ValidTransactionType reports whether t is a known transaction type
+func ValidTransactionType(t string) bool {
+	return t == "expense" || t == "income" || t == "transfer"
+}
+
+// ValidateCreateTransaction validates a new transaction
+func ValidateCreateTransaction(req *mdlFeatureOne.CreateTransactionRequest) error {
+	if !ValidTransactionType(req.Type) {
+		return fmt.Errorf("type must be one of expense, income or transfer")
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if strings.TrimSpace(req.Date) == "" {
+		return fmt.Errorf("date is required")
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return fmt.Errorf("invalid date format (expected YYYY-MM-DD)")
+	}
+	if err := ValidateTags(req.Tags); err != nil {
+		return err
+	}
+	return validateTransferFields(req.Type, req.CategoryID, req.AccountID, req.TransferAccountID)
+}
+
+// ValidateUpdateTransaction validates an update against the stored transaction
+func ValidateUpdateTransaction(req *mdlFeatureOne.UpdateTransactionRequest, current *mdlFeatureOne.TransactionResponse) error {
+	if req.Type != nil && !ValidTransactionType(*req.Type) {
+		return fmt.Errorf("type must be one of expense, income or transfer")
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return fmt.Errorf("title must not be empty")
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if req.Date != nil {
+		if _, err := time.Parse("2006-01-02", *req.Date); err != nil {
+			return fmt.Errorf("invalid date format (expected YYYY-MM-DD)")
+		}
+	}
+	if err := ValidateTags(req.Tags); err != nil {
+		return err
+	}
+
+	// Check the transfer rules on the merged result
+	txType, accountID, transferAccountID := current.Type, current.AccountID, current.TransferAccountID
+	var categoryID *int
+	if req.Type != nil {
+		txType = *req.Type
+	}
+	if req.AccountID != nil {
+		accountID = req.AccountID
+	}
+	if req.TransferAccountID != nil {
+		transferAccountID = req.TransferAccountID
+	}
+	if req.CategoryID != nil {
+		categoryID = req.CategoryID
+	}
+	if txType != "transfer" {
+		transferAccountID = nil
+	}
+	return validateTransferFields(txType, categoryID, accountID, transferAccountID)
+}
+
+func validateTransferFields(txType string, categoryID, accountID, transferAccountID *int) error {
+	if txType != "transfer" {
+		if transferAccountID != nil {
+			return fmt.Errorf("transferAccountId is only allowed for transfers")
+		}
+		return nil
+	}
+	if categoryID != nil {
+		return fmt.Errorf("transfers cannot have a category")
+	}
+	if accountID == nil || transferAccountID == nil {
+		return fmt.Errorf("accountId and transferAccountId are required for transfers")
+	}
+	if *accountID == *transferAccountID {
+		return fmt.Errorf("accountId and transferAccountId must be different")
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package hlpFeatureOne
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxWebhooksPerUser = 10
+
+	// MaxWebhookAttempts is how often a delivery is tried before it is marked failed;
+	// WebhookDisableAfter failed attempts in a row disable the endpoint
+	MaxWebhookAttempts  = 8
+	WebhookDisableAfter = 20
+
+	maxWebhookURL         = 2048
+	maxWebhookDescription = 200
+	webhookRetryBase      = time.Minute
+	webhookRetryCap       = 6 * time.Hour
+)
+
+// WebhookEvents are the events an endpoint can subscribe to
+var WebhookEvents = []string{
+	mdlFeatureOne.WebhookEventExpenseCreated,
+	mdlFeatureOne.WebhookEventExpenseUpdated,
+	mdlFeatureOne.WebhookEventExpenseDeleted,
+	mdlFeatureOne.WebhookEventExpenseRestored,
+	mdlFeatureOne.WebhookEventBatchJobCompleted,
+	mdlFeatureOne.WebhookEventBatchJobFailed,
+}
+
+// ValidWebhookEvent reports whether event is one an endpoint can subscribe to
+func ValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWebhookEndpoint checks an endpoint's URL and events; events are
+// lower-cased and deduplicated, keeping their order
+func ValidateWebhookEndpoint(req *mdlFeatureOne.WebhookEndpointRequest) error {
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if len(req.URL) > maxWebhookURL {
+		return fmt.Errorf("url must be at most %d characters", maxWebhookURL)
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return err
+	}
+
+	req.Description = trimmedOrNil(req.Description)
+	if req.Description != nil && utf8.RuneCountInString(*req.Description) > maxWebhookDescription {
+		return fmt.Errorf("description must be at most %d characters", maxWebhookDescription)
+	}
+
+	if len(req.Events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+	seen := map[string]bool{}
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !ValidWebhookEvent(event) {
+			return fmt.Errorf("unknown event %q; must be one of %s", event, strings.Join(WebhookEvents, ", "))
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	req.Events = events
+	return nil
+}
+
+// validateWebhookURL accepts absolute http(s) URLs. Hosts that are plainly on
+// the server's own network are refused up front; names are checked again when
+// a delivery connects (see WebhookDialControl), since they can resolve anywhere.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return fmt.Errorf("url must not contain credentials")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("url must not point to a local or private address")
+	}
+	if ip := net.ParseIP(host); ip != nil && !WebhookAddressAllowed(ip) {
+		return fmt.Errorf("url must not point to a local or private address")
+	}
+	return nil
+}
+
+// carrierGradeNAT is the shared address space (RFC 6598), which is as internal
+// as the private ranges
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// WebhookAddressAllowed reports whether a webhook may be sent to ip: loopback,
+// private, link-local (which holds cloud metadata services), unspecified and
+// multicast addresses are refused
+func WebhookAddressAllowed(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && (ip4[0] == 0 || carrierGradeNAT.Contains(ip4)) {
+		return false
+	}
+	return true
+}
+
+// WebhookDialControl refuses connections to addresses WebhookAddressAllowed
+// rejects. It runs after the name is resolved, for every address tried, so a
+// name that resolves or rebinds to an internal address cannot be reached.
+func WebhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !WebhookAddressAllowed(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+// ValidateWebhookDeliveryFilters checks the delivery log's status, event and paging
+func ValidateWebhookDeliveryFilters(filters *mdlFeatureOne.WebhookDeliveryFilters) error {
+	filters.Status = strings.ToLower(strings.TrimSpace(filters.Status))
+	switch filters.Status {
+	case "", mdlFeatureOne.WebhookDeliveryStatusPending,
+		mdlFeatureOne.WebhookDeliveryStatusDelivered, mdlFeatureOne.WebhookDeliveryStatusFailed:
+	default:
+		return fmt.Errorf("status must be pending, delivered or failed")
+	}
+
+	filters.Event = strings.ToLower(strings.TrimSpace(filters.Event))
+	if filters.Event != "" && !ValidWebhookEvent(filters.Event) {
+		return fmt.Errorf("unknown event %q", filters.Event)
+	}
+
+	if filters.Limit < 1 || filters.Limit > 100 {
+		return fmt.Errorf("limit must be between 1 and 100")
+	}
+	if filters.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	return nil
+}
+
+// GenerateWebhookSecret returns a new random signing secret
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature header for a body sent at
+// timestamp: "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Receivers
+// recompute it with their secret and reject old timestamps to stop replays.
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRetryDelay is the wait before the next try after attempts failed tries:
+// one minute, doubling each time, at most six hours
+func WebhookRetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		return webhookRetryBase
+	}
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryCap {
+			return webhookRetryCap
+		}
+	}
+	return delay
+}
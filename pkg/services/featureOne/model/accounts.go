@@ -0,0 +1,99 @@
+package mdlFeatureOne
+
+// ============================================
+// ACCOUNT REQUEST STRUCTS
+// ============================================
+
+// CreateAccountRequest creates or replaces an account. Type is one of cash,
+// debit_card, credit_card, e_wallet or other; Currency is an ISO 4217 code.
+type CreateAccountRequest struct {
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"openingBalance"`
+}
+
+type AccountLedgerFilters struct {
+	StartDate  *string `json:"startDate"`
+	EndDate    *string `json:"endDate"`
+	Reconciled *bool   `json:"reconciled"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+}
+
+// ReconcileAccountRequest marks the transactions found on a statement as cleared
+type ReconcileAccountRequest struct {
+	StatementDate    string  `json:"statementDate"`
+	StatementBalance float64 `json:"statementBalance"`
+	TransactionIDs   []int   `json:"transactionIds"`
+}
+
+// ============================================
+// ACCOUNT RESPONSE STRUCTS
+// ============================================
+
+// AccountResponse carries the current balance and the balance of the
+// transactions cleared by reconciliations
+type AccountResponse struct {
+	ID                 int     `json:"id"`
+	Name               string  `json:"name"`
+	Type               string  `json:"type"`
+	Currency           string  `json:"currency"`
+	OpeningBalance     float64 `json:"openingBalance"`
+	Balance            float64 `json:"balance"`
+	ClearedBalance     float64 `json:"clearedBalance"`
+	LastReconciledDate *string `json:"lastReconciledDate"`
+	CreatedAt          string  `json:"createdAt"`
+	UpdatedAt          string  `json:"updatedAt"`
+}
+
+// AccountLedgerEntry is a transaction as seen by one account: Amount is signed
+// (negative for money leaving the account) and Balance is the running balance
+// after it
+type AccountLedgerEntry struct {
+	TransactionID        int           `json:"transactionId"`
+	Type                 string        `json:"type"`
+	Title                string        `json:"title"`
+	Date                 string        `json:"date"`
+	Amount               float64       `json:"amount"`
+	Balance              float64       `json:"balance"`
+	CounterpartAccountID *int          `json:"counterpartAccountId"` // other side of a transfer
+	Category             *CategoryInfo `json:"category"`
+	Reconciled           bool          `json:"reconciled"`
+	ReconciliationID     *int          `json:"reconciliationId"`
+}
+
+type AccountLedgerResponse struct {
+	Account        AccountResponse      `json:"account"`
+	OpeningBalance float64              `json:"openingBalance"`
+	ClosingBalance float64              `json:"closingBalance"`
+	Inflow         float64              `json:"inflow"`
+	Outflow        float64              `json:"outflow"`
+	Entries        []AccountLedgerEntry `json:"entries"`
+	Pagination     PaginationResponse   `json:"pagination"`
+}
+
+type AccountBalancePoint struct {
+	PeriodStart string  `json:"periodStart"`
+	Date        string  `json:"date"`
+	Balance     float64 `json:"balance"`
+}
+
+type AccountBalanceHistoryResponse struct {
+	AccountID int                   `json:"accountId"`
+	Interval  string                `json:"interval"`
+	Balances  []AccountBalancePoint `json:"balances"`
+}
+
+// AccountReconciliationResponse compares the statement balance with the balance
+// of all cleared transactions; a non-zero Difference means something is missing
+type AccountReconciliationResponse struct {
+	ID               int     `json:"id"`
+	AccountID        int     `json:"accountId"`
+	StatementDate    string  `json:"statementDate"`
+	StatementBalance float64 `json:"statementBalance"`
+	ClearedBalance   float64 `json:"clearedBalance"`
+	Difference       float64 `json:"difference"`
+	TransactionIDs   []int   `json:"transactionIds"`
+	CreatedAt        string  `json:"createdAt"`
+}
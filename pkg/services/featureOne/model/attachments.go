@@ -0,0 +1,38 @@
+package mdlFeatureOne
+
+// ============================================
+// ATTACHMENT RESPONSE STRUCTS
+// ============================================
+
+// AttachmentResponse is a file attached to an expense. Size, content type and
+// checksum are unknown for images attached before attachments existed.
+type AttachmentResponse struct {
+	ID          int     `json:"id"`
+	URL         string  `json:"url"`
+	Storage     string  `json:"storage"`
+	Filename    string  `json:"filename"`
+	ContentType *string `json:"contentType"`
+	Size        *int64  `json:"size"`
+	Checksum    *string `json:"checksum"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+// ============================================
+// ATTACHMENT HELPER STRUCTS
+// ============================================
+
+// NewAttachment describes an uploaded file before it is stored
+type NewAttachment struct {
+	URL         string
+	Storage     string
+	Filename    string
+	ContentType string
+	Size        int64
+	Checksum    string
+}
+
+// AttachmentFile locates a stored file for cleanup
+type AttachmentFile struct {
+	URL     string `json:"url"`
+	Storage string `json:"storage"`
+}
@@ -0,0 +1,36 @@
+package mdlFeatureOne
+
+// ============================================
+// BANK STATEMENT STRUCTS
+// ============================================
+
+// BankTransaction is one booked line of a bank statement
+type BankTransaction struct {
+	ID     string  // bank's transaction ID (FITID, AcctSvcrRef, ...), or a content hash for QIF
+	Date   string  // YYYY-MM-DD
+	Amount float64 // negative for debits
+	Payee  string
+	Memo   string
+}
+
+type BankStatement struct {
+	Format       string // ofx, qif or camt053
+	AccountID    string
+	Currency     string
+	Transactions []BankTransaction
+}
+
+// ============================================
+// BANK STATEMENT RESPONSE STRUCTS
+// ============================================
+
+type StatementImportResponse struct {
+	JobID             int    `json:"jobId"`
+	TotalItems        int    `json:"totalItems"`
+	Status            string `json:"status"`
+	Format            string `json:"format"`
+	AccountID         string `json:"accountId"`
+	Transactions      int    `json:"transactions"`
+	SkippedCredits    int    `json:"skippedCredits"`
+	SkippedDuplicates int    `json:"skippedDuplicates"`
+}
@@ -0,0 +1,76 @@
+package mdlFeatureOne
+
+// ============================================
+// BUDGET REQUEST STRUCTS
+// ============================================
+
+type CreateBudgetRequest struct {
+	Name       string  `json:"name"`
+	CategoryID *int    `json:"categoryId"`
+	Amount     float64 `json:"amount"`
+	Period     string  `json:"period"`
+	StartDate  string  `json:"startDate"`
+	EndDate    *string `json:"endDate"`
+	Rollover   bool    `json:"rollover"`
+	Thresholds []int   `json:"thresholds"`
+}
+
+type UpdateBudgetRequest struct {
+	Name       *string  `json:"name"`
+	CategoryID *int     `json:"categoryId"`
+	Amount     *float64 `json:"amount"`
+	Period     *string  `json:"period"`
+	StartDate  *string  `json:"startDate"`
+	EndDate    *string  `json:"endDate"`
+	Rollover   *bool    `json:"rollover"`
+	Thresholds []int    `json:"thresholds"`
+}
+
+// ============================================
+// BUDGET RESPONSE STRUCTS
+// ============================================
+
+type BudgetResponse struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	CategoryID *int    `json:"categoryId"`
+	Amount     float64 `json:"amount"`
+	Period     string  `json:"period"`
+	StartDate  string  `json:"startDate"`
+	EndDate    *string `json:"endDate"`
+	Rollover   bool    `json:"rollover"`
+	Thresholds []int   `json:"thresholds"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+type BudgetStatusItem struct {
+	Budget         BudgetResponse `json:"budget"`
+	PeriodStart    string         `json:"periodStart"`
+	PeriodEnd      string         `json:"periodEnd"`
+	RolloverAmount float64        `json:"rolloverAmount"`
+	Limit          float64        `json:"limit"`
+	Spent          float64        `json:"spent"`
+	Remaining      float64        `json:"remaining"`
+	PercentUsed    float64        `json:"percentUsed"`
+	Status         string         `json:"status"`
+}
+
+type BudgetStatusResponse struct {
+	AsOf    string             `json:"asOf"`
+	Budgets []BudgetStatusItem `json:"budgets"`
+}
+
+// ============================================
+// HELPER STRUCTS
+// ============================================
+
+type BudgetAlertEvent struct {
+	BudgetID    int     `json:"budgetId"`
+	BudgetName  string  `json:"budgetName"`
+	Threshold   int     `json:"threshold"`
+	PeriodStart string  `json:"periodStart"`
+	PeriodEnd   string  `json:"periodEnd"`
+	Limit       float64 `json:"limit"`
+	Spent       float64 `json:"spent"`
+}
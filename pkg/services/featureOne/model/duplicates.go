@@ -0,0 +1,49 @@
+package mdlFeatureOne
+
+// ============================================
+// DUPLICATE DETECTION STRUCTS
+// ============================================
+
+// DuplicateFilters tunes what counts as a duplicate: the largest gap in days
+// between the dates and the least title similarity (0 to 1)
+type DuplicateFilters struct {
+	WindowDays int
+	Similarity float64
+	StartDate  *string
+	EndDate    *string
+}
+
+// DuplicateCandidate is an existing expense that looks like the one being created
+type DuplicateCandidate struct {
+	ExpenseID  int     `json:"expenseId"`
+	Title      string  `json:"title"`
+	Amount     float64 `json:"amount"`
+	Date       string  `json:"date"`
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateWarningResponse is returned instead of creating an expense that looks
+// like existing ones when duplicates are rejected
+type DuplicateWarningResponse struct {
+	Duplicates []DuplicateCandidate `json:"duplicates"`
+}
+
+// DuplicatePair links two expenses that look like the same spending
+type DuplicatePair struct {
+	ExpenseID   int
+	DuplicateID int
+	Similarity  float64
+}
+
+// DuplicateCluster is a group of expenses linked by pairwise matches; Similarity
+// is the closest match within the group
+type DuplicateCluster struct {
+	Similarity float64           `json:"similarity"`
+	Expenses   []ExpenseResponse `json:"expenses"`
+}
+
+type DuplicateClustersResponse struct {
+	Clusters   []DuplicateCluster `json:"clusters"`
+	WindowDays int                `json:"windowDays"`
+	Similarity float64            `json:"similarity"`
+}
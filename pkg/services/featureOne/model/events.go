@@ -0,0 +1,29 @@
+package mdlFeatureOne
+
+// ============================================
+// LIVE EVENT CONSTANTS
+// ============================================
+
+// EventBatchJobProgress is streamed while a batch job runs; the other live
+// events share their names and data with the webhook events
+const EventBatchJobProgress = "batch_job.progress"
+
+// ============================================
+// LIVE EVENT RESPONSE STRUCTS
+// ============================================
+
+type EventStreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expiresIn"` // Seconds
+}
+
+// BatchJobProgressEvent is the data of a batch_job.progress event
+type BatchJobProgressEvent struct {
+	JobID           int    `json:"jobId"`
+	JobType         string `json:"jobType"`
+	Status          string `json:"status"`
+	TotalItems      int    `json:"totalItems"`
+	ProcessedItems  int    `json:"processedItems"`
+	SuccessfulItems int    `json:"successfulItems"`
+	FailedItems     int    `json:"failedItems"`
+}
@@ -7,11 +7,13 @@ package mdlFeatureOne
 type CreateCategoryRequest struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description"`
+	Kind        string  `json:"kind"`
 }
 
 type CategoryFilters struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+	Kind   *string `json:"kind"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
 }
 
 // ============================================
@@ -22,6 +24,7 @@ type CategoryResponse struct {
 	ID          int     `json:"id"`
 	Name        string  `json:"name"`
 	Description *string `json:"description"`
+	Kind        string  `json:"kind"`
 	CreatedAt   string  `json:"createdAt"`
 	UpdatedAt   string  `json:"updatedAt"`
 }
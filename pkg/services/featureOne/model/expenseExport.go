@@ -0,0 +1,28 @@
+package mdlFeatureOne
+
+import "time"
+
+// ============================================
+// EXPORT STRUCTS
+// ============================================
+
+// ExportExpenseRow is one exported expense; Tags holds the tag names joined with ";"
+type ExportExpenseRow struct {
+	ID           int     `json:"id" gorm:"column:id"`
+	Title        string  `json:"title" gorm:"column:title"`
+	Amount       float64 `json:"amount" gorm:"column:amount"`
+	CategoryID   *int    `json:"categoryId" gorm:"column:category_id"`
+	CategoryName *string `json:"categoryName" gorm:"column:category_name"`
+	Merchant     *string `json:"merchant" gorm:"column:merchant"`
+	Date         string  `json:"date" gorm:"column:date"`
+	Notes        *string `json:"notes" gorm:"column:notes"`
+	Tags         string  `json:"tags" gorm:"column:tags"`
+	CreatedAt    string  `json:"createdAt" gorm:"column:created_at"`
+}
+
+// ExportMeta describes an export for formats with a header, such as the PDF statement
+type ExportMeta struct {
+	StartDate   *string
+	EndDate     *string
+	GeneratedAt time.Time
+}
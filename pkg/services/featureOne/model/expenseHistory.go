@@ -0,0 +1,63 @@
+package mdlFeatureOne
+
+import "encoding/json"
+
+// Revision sources record what changed an expense
+const (
+	RevisionSourceAPI       = "api"
+	RevisionSourceBatch     = "batch"
+	RevisionSourceImport    = "import"
+	RevisionSourceRecurring = "recurring"
+	RevisionSourceRevert    = "revert"
+	RevisionSourceRules     = "rules"
+	RevisionSourceSync      = "sync"
+)
+
+// ============================================
+// EXPENSE HISTORY RESPONSE STRUCTS
+// ============================================
+
+// FieldChange is the value of a tracked field before and after a revision
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// ExpenseRevisionState is the tracked state of an expense after a revision
+type ExpenseRevisionState struct {
+	Title      string   `json:"title"`
+	Amount     float64  `json:"amount"`
+	CategoryID *int     `json:"categoryId"`
+	MerchantID *int     `json:"merchantId"`
+	AccountID  *int     `json:"accountId"`
+	Date       string   `json:"date"`
+	Notes      *string  `json:"notes"`
+	ImageURL   *string  `json:"imageUrl"`
+	Tags       []string `json:"tags"`
+}
+
+type ExpenseRevisionResponse struct {
+	Revision  int                    `json:"revision"`
+	ActorID   *int                   `json:"actorId"`
+	Source    string                 `json:"source"`
+	SourceRef *string                `json:"sourceRef"` // batch job ID or reverted revision
+	Changes   map[string]FieldChange `json:"changes"`
+	Snapshot  ExpenseRevisionState   `json:"snapshot"`
+	CreatedAt string                 `json:"createdAt"`
+}
+
+type ExpenseHistoryResponse struct {
+	ExpenseID  int                       `json:"expenseId"`
+	Revisions  []ExpenseRevisionResponse `json:"revisions"`
+	Pagination PaginationResponse        `json:"pagination"`
+}
+
+// ============================================
+// EXPENSE HISTORY HELPER STRUCTS
+// ============================================
+
+// RevisionSource tells what made a change; Ref identifies the batch job or reverted revision
+type RevisionSource struct {
+	Type string
+	Ref  *string
+}
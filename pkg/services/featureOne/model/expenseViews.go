@@ -0,0 +1,45 @@
+package mdlFeatureOne
+
+// ============================================
+// SAVED VIEW REQUEST STRUCTS
+// ============================================
+
+// SavedViewFilters is the filter set of a saved view: the ExpenseFilters query
+// parameters and sort, without paging. Filter is the expression text.
+type SavedViewFilters struct {
+	Title      *string  `json:"title,omitempty"`
+	Q          *string  `json:"q,omitempty"`
+	MinAmount  *float64 `json:"minAmount,omitempty"`
+	MaxAmount  *float64 `json:"maxAmount,omitempty"`
+	CategoryID *int     `json:"categoryId,omitempty"`
+	StartDate  *string  `json:"startDate,omitempty"`
+	EndDate    *string  `json:"endDate,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	TagMatch   string   `json:"tagMatch,omitempty"`
+	Filter     string   `json:"filter,omitempty"`
+	SortBy     string   `json:"sortBy,omitempty"`
+	Order      string   `json:"order,omitempty"`
+}
+
+// ExpenseViewRequest creates or replaces a saved view. Making a view the default
+// takes the flag from the user's previous default view.
+type ExpenseViewRequest struct {
+	Name      string           `json:"name"`
+	Filters   SavedViewFilters `json:"filters"`
+	IsPinned  bool             `json:"isPinned"`
+	IsDefault bool             `json:"isDefault"`
+}
+
+// ============================================
+// SAVED VIEW RESPONSE STRUCTS
+// ============================================
+
+type ExpenseViewResponse struct {
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Filters   SavedViewFilters `json:"filters"`
+	IsPinned  bool             `json:"isPinned"`
+	IsDefault bool             `json:"isDefault"`
+	CreatedAt string           `json:"createdAt"`
+	UpdatedAt string           `json:"updatedAt"`
+}
@@ -1,36 +1,74 @@
 package mdlFeatureOne
 
+import "encoding/json"
+
 // ============================================
 // EXPENSE REQUEST STRUCTS
 // ============================================
 
 type CreateExpenseRequest struct {
-	Title      string  `json:"title"`
-	Amount     float64 `json:"amount"`
-	CategoryID *int    `json:"categoryId"`
-	Date       string  `json:"date"`
-	Notes      *string `json:"notes"`
-	ImageURL   *string `json:"imageUrl"`
+	Title      string   `json:"title"`
+	Amount     float64  `json:"amount"`
+	CategoryID *int     `json:"categoryId"`
+	Merchant   *string  `json:"merchant"` // merchant name, created on first use
+	AccountID  *int     `json:"accountId"`
+	Date       string   `json:"date"`
+	Notes      *string  `json:"notes"`
+	ImageURL   *string  `json:"imageUrl"`
+	Tags       []string `json:"tags"`
 }
 
+// UpdateExpenseRequest leaves tags untouched when Tags is nil; an empty list clears them.
+// Clear names the fields a merge patch set to null (categoryId, merchant, accountId,
+// notes, imageUrl). ApplyRules runs the categorization rules after the update.
 type UpdateExpenseRequest struct {
 	Title      *string  `json:"title"`
 	Amount     *float64 `json:"amount"`
 	CategoryID *int     `json:"categoryId"`
+	Merchant   *string  `json:"merchant"`
+	AccountID  *int     `json:"accountId"`
 	Date       *string  `json:"date"`
 	Notes      *string  `json:"notes"`
 	ImageURL   *string  `json:"imageUrl"`
+	Tags       []string `json:"tags"`
+	Clear      []string `json:"-"`
+	ApplyRules bool     `json:"-"`
+}
+
+// JSONPatchOperation is one operation of an RFC 6902 JSON Patch
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
 }
 
 type ExpenseFilters struct {
-	Title      *string  `json:"title"`
-	MinAmount  *float64 `json:"minAmount"`
-	MaxAmount  *float64 `json:"maxAmount"`
-	CategoryID *int     `json:"categoryId"`
-	StartDate  *string  `json:"startDate"`
-	EndDate    *string  `json:"endDate"`
-	Limit      int      `json:"limit"`
-	Offset     int      `json:"offset"`
+	Title       *string  `json:"title"`
+	Q           *string  `json:"q,omitempty"`
+	SearchQuery *string  `json:"searchQuery,omitempty"` // to_tsquery expression built from Q
+	MinAmount   *float64 `json:"minAmount"`
+	MaxAmount   *float64 `json:"maxAmount"`
+	CategoryID  *int     `json:"categoryId"`
+	StartDate   *string  `json:"startDate"`
+	EndDate     *string  `json:"endDate"`
+	Tags        []string `json:"tags,omitempty"`
+	TagMatch    string   `json:"tagMatch,omitempty"`
+	SortBy      string   `json:"sortBy,omitempty"`
+	Order       string   `json:"order,omitempty"`
+	Limit       int      `json:"limit"`
+	Offset      int      `json:"offset"`
+
+	// Filter is the ?filter= expression; FilterSQL and FilterParams are its
+	// compiled condition and values, set only by ValidateExpenseFilters
+	Filter       string   `json:"-"`
+	FilterSQL    *string  `json:"filterSql,omitempty"`
+	FilterParams []string `json:"filterParams,omitempty"`
+
+	// CursorToken is the opaque cursor sent by the client; Cursor is its decoded
+	// position, in which case Offset is ignored
+	CursorToken string     `json:"-"`
+	Cursor      *CursorKey `json:"cursor,omitempty"`
 }
 
 // ============================================
@@ -41,24 +79,51 @@ type CategoryInfo struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Kind        string `json:"kind,omitempty"`
+}
+
+type MerchantInfo struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 type ExpenseResponse struct {
-	ID        int           `json:"id"`
-	Title     string        `json:"title"`
-	Amount    float64       `json:"amount"`
-	Category  *CategoryInfo `json:"category"`
-	Date      string        `json:"date"`
-	Notes     *string       `json:"notes"`
-	ImageURL  *string       `json:"imageUrl"`
-	CreatedAt string        `json:"createdAt"`
-	UpdatedAt string        `json:"updatedAt"`
+	ID          int                  `json:"id"`
+	Title       string               `json:"title"`
+	Amount      float64              `json:"amount"`
+	Category    *CategoryInfo        `json:"category"`
+	Merchant    *MerchantInfo        `json:"merchant"`
+	AccountID   *int                 `json:"accountId"`
+	Date        string               `json:"date"`
+	Notes       *string              `json:"notes"`
+	ImageURL    *string              `json:"imageUrl"`
+	ExternalID  *string              `json:"externalId"` // bank transaction ID of imported statements
+	Tags        []string             `json:"tags"`
+	Attachments []AttachmentResponse `json:"attachments"`
+	Version     int                  `json:"version"` // sent as the ETag; If-Match expects it
+	CreatedAt   string               `json:"createdAt"`
+	UpdatedAt   string               `json:"updatedAt"`
+
+	// Set only for full-text searches (q)
+	Rank       *float64          `json:"rank,omitempty"`
+	Highlights *SearchHighlights `json:"highlights,omitempty"`
+
+	// Set only on create, for existing expenses the new one looks like
+	PossibleDuplicates []DuplicateCandidate `json:"possibleDuplicates,omitempty"`
+}
+
+// SearchHighlights are HTML: the text is escaped and the matches are wrapped in <mark>
+type SearchHighlights struct {
+	Title string  `json:"title"`
+	Notes *string `json:"notes"`
 }
 
 type PaginationResponse struct {
-	Total  int `json:"total"`
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+	Total      int     `json:"total"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	NextCursor *string `json:"nextCursor,omitempty"`
+	PrevCursor *string `json:"prevCursor,omitempty"`
 }
 
 type ExpenseListResponse struct {
@@ -70,7 +135,23 @@ type ExpenseListResponse struct {
 // HELPER STRUCTS
 // ============================================
 
+// CursorKey is the position of a row in a sorted list: its sort value and ID.
+// Dir tells whether the page continues after (next) or before (prev) it.
+type CursorKey struct {
+	Value string `json:"value"`
+	ID    int    `json:"id"`
+	Dir   string `json:"dir,omitempty"`
+}
+
+// ListCursors holds the first and last row keys of a page when neighbouring pages exist
+type ListCursors struct {
+	Next *CursorKey `json:"next"`
+	Prev *CursorKey `json:"prev"`
+}
+
 type DeleteExpenseResult struct {
-	IsDeleted bool    `json:"isDeleted"`
-	ImageURL  *string `json:"imageUrl"`
+	IsDeleted       bool             `json:"isDeleted"`
+	VersionMismatch bool             `json:"versionMismatch"` // If-Match named an outdated version
+	ImageURL        *string          `json:"imageUrl"`
+	Attachments     []AttachmentFile `json:"attachments"`
 }
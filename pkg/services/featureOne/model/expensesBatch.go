@@ -9,20 +9,31 @@ type BatchUpdateItem struct {
 	Title      *string  `json:"title"`
 	Amount     *float64 `json:"amount"`
 	CategoryID *int     `json:"categoryId"`
+	Merchant   *string  `json:"merchant"`
+	AccountID  *int     `json:"accountId"`
 	Date       *string  `json:"date"`
 	Notes      *string  `json:"notes"`
+	Tags       []string `json:"tags"`
+	Version    *int     `json:"version"` // expected version; the item fails if the expense changed since
+	Clear      []string `json:"-"`       // fields set to null, as in UpdateExpenseRequest
 }
 
 type BatchUpdateRequest struct {
 	Updates []BatchUpdateItem `json:"updates"`
 }
 
+// CSVExpenseRow is one row of a batch upload; ExternalID is set for bank statement
+// imports and makes the row a no-op when that bank transaction was already imported
 type CSVExpenseRow struct {
-	Title      string  `json:"title"`
-	Amount     float64 `json:"amount"`
-	CategoryID *int    `json:"categoryId"`
-	Date       string  `json:"date"`
-	Notes      *string `json:"notes"`
+	Title      string   `json:"title"`
+	Amount     float64  `json:"amount"`
+	CategoryID *int     `json:"categoryId"`
+	Merchant   *string  `json:"merchant"`
+	AccountID  *int     `json:"accountId"`
+	Date       string   `json:"date"`
+	Notes      *string  `json:"notes"`
+	Tags       []string `json:"tags"`
+	ExternalID *string  `json:"externalId,omitempty"`
 }
 
 type BatchUploadRequest struct {
@@ -33,11 +44,15 @@ type BatchUploadRequest struct {
 // BATCH RESPONSE STRUCTS
 // ============================================
 
+// BatchUpdateResultItem is the outcome of one item. Uploaded rows that look like
+// existing expenses are created with Duplicate set and the matching expense IDs.
 type BatchUpdateResultItem struct {
-	Index     int    `json:"index"`
-	ExpenseID int    `json:"expenseId"`
-	Message   string `json:"message"`
-	Success   bool   `json:"success"`
+	Index       int    `json:"index"`
+	ExpenseID   int    `json:"expenseId"`
+	Message     string `json:"message"`
+	Success     bool   `json:"success"`
+	Duplicate   bool   `json:"duplicate,omitempty"`
+	DuplicateOf []int  `json:"duplicateOf,omitempty"`
 }
 
 type BatchUpdateResponse struct {
@@ -0,0 +1,78 @@
+package mdlFeatureOne
+
+// ============================================
+// MERCHANT REQUEST STRUCTS
+// ============================================
+
+type MerchantRequest struct {
+	Name string `json:"name"`
+}
+
+// ============================================
+// MERCHANT RESPONSE STRUCTS
+// ============================================
+
+type MerchantResponse struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	UsageCount int    `json:"usageCount"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+// ============================================
+// CATEGORIZATION RULE REQUEST STRUCTS
+// ============================================
+
+// CategorizationRuleRequest creates or replaces a rule. MatchField is title
+// (default) or merchant; MatchType is contains, regex or amount_range.
+// MinAmount and MaxAmount bound amount_range and narrow the other types.
+type CategorizationRuleRequest struct {
+	Name          string   `json:"name"`
+	Priority      *int     `json:"priority"` // lower runs first, default 100
+	MatchField    string   `json:"matchField"`
+	MatchType     string   `json:"matchType"`
+	Pattern       *string  `json:"pattern"`
+	MinAmount     *float64 `json:"minAmount"`
+	MaxAmount     *float64 `json:"maxAmount"`
+	SetCategoryID *int     `json:"setCategoryId"`
+	SetTags       []string `json:"setTags"`
+	SetNotes      *string  `json:"setNotes"`
+	IsActive      *bool    `json:"isActive"` // default true
+}
+
+// ApplyRulesRequest selects the expenses a rules job runs over; Overwrite lets
+// rules replace a category or notes that is already set
+type ApplyRulesRequest struct {
+	Overwrite bool    `json:"overwrite"`
+	StartDate *string `json:"startDate"`
+	EndDate   *string `json:"endDate"`
+}
+
+// ============================================
+// CATEGORIZATION RULE RESPONSE STRUCTS
+// ============================================
+
+type CategorizationRuleResponse struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Priority    int           `json:"priority"`
+	MatchField  string        `json:"matchField"`
+	MatchType   string        `json:"matchType"`
+	Pattern     *string       `json:"pattern"`
+	MinAmount   *float64      `json:"minAmount"`
+	MaxAmount   *float64      `json:"maxAmount"`
+	SetCategory *CategoryInfo `json:"setCategory"`
+	SetTags     []string      `json:"setTags"`
+	SetNotes    *string       `json:"setNotes"`
+	IsActive    bool          `json:"isActive"`
+	CreatedAt   string        `json:"createdAt"`
+	UpdatedAt   string        `json:"updatedAt"`
+}
+
+// RuleApplicationResult tells how many rules matched an expense and whether
+// they changed it
+type RuleApplicationResult struct {
+	MatchedRules int  `json:"matchedRules"`
+	Changed      bool `json:"changed"`
+}
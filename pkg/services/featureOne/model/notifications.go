@@ -0,0 +1,31 @@
+package mdlFeatureOne
+
+// ============================================
+// NOTIFICATION REQUEST STRUCTS
+// ============================================
+
+type NotificationFilters struct {
+	UnreadOnly bool `json:"unreadOnly"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+}
+
+// ============================================
+// NOTIFICATION RESPONSE STRUCTS
+// ============================================
+
+type NotificationResponse struct {
+	ID        int         `json:"id"`
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data"`
+	ReadAt    *string     `json:"readAt"`
+	CreatedAt string      `json:"createdAt"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Unread        int                    `json:"unread"`
+	Pagination    PaginationResponse     `json:"pagination"`
+}
@@ -0,0 +1,92 @@
+package mdlFeatureOne
+
+// ============================================
+// RECURRING EXPENSE REQUEST STRUCTS
+// ============================================
+
+type CreateRecurringExpenseRequest struct {
+	Title      string  `json:"title"`
+	Amount     float64 `json:"amount"`
+	CategoryID *int    `json:"categoryId"`
+	Notes      *string `json:"notes"`
+	Frequency  string  `json:"frequency"`
+	Interval   int     `json:"interval"`
+	DayOfMonth *int    `json:"dayOfMonth"`
+	RRule      *string `json:"rrule"`
+	StartDate  string  `json:"startDate"`
+	EndDate    *string `json:"endDate"`
+}
+
+type UpdateRecurringExpenseRequest struct {
+	Title      *string  `json:"title"`
+	Amount     *float64 `json:"amount"`
+	CategoryID *int     `json:"categoryId"`
+	Notes      *string  `json:"notes"`
+	Frequency  *string  `json:"frequency"`
+	Interval   *int     `json:"interval"`
+	DayOfMonth *int     `json:"dayOfMonth"`
+	RRule      *string  `json:"rrule"`
+	StartDate  *string  `json:"startDate"`
+	EndDate    *string  `json:"endDate"`
+}
+
+type RecurringExpenseFilters struct {
+	IsPaused *bool `json:"isPaused"`
+	Limit    int   `json:"limit"`
+	Offset   int   `json:"offset"`
+}
+
+// ============================================
+// RECURRING EXPENSE RESPONSE STRUCTS
+// ============================================
+
+type RecurringExpenseResponse struct {
+	ID          int     `json:"id"`
+	Title       string  `json:"title"`
+	Amount      float64 `json:"amount"`
+	CategoryID  *int    `json:"categoryId"`
+	Notes       *string `json:"notes"`
+	Frequency   string  `json:"frequency"`
+	Interval    int     `json:"interval" gorm:"column:interval_count"`
+	DayOfMonth  *int    `json:"dayOfMonth"`
+	RRule       *string `json:"rrule" gorm:"column:rrule"`
+	StartDate   string  `json:"startDate"`
+	EndDate     *string `json:"endDate"`
+	NextRunDate *string `json:"nextRunDate"`
+	LastRunDate *string `json:"lastRunDate"`
+	IsPaused    bool    `json:"isPaused"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+type RecurringExpenseListResponse struct {
+	Rules      []RecurringExpenseResponse `json:"rules"`
+	Pagination PaginationResponse         `json:"pagination"`
+}
+
+type RecurringExpensePreviewResponse struct {
+	RuleID      int      `json:"ruleId"`
+	Occurrences []string `json:"occurrences"`
+}
+
+// ============================================
+// RECURRING EXPENSE ENTITY STRUCTS (DB)
+// ============================================
+
+type RecurringExpenseEntity struct {
+	ID          int     `db:"id"`
+	UserID      int     `db:"user_id"`
+	Title       string  `db:"title"`
+	Amount      float64 `db:"amount"`
+	CategoryID  *int    `db:"category_id"`
+	Notes       *string `db:"notes"`
+	Frequency   string  `db:"frequency"`
+	Interval    int     `db:"interval_count" gorm:"column:interval_count"`
+	DayOfMonth  *int    `db:"day_of_month"`
+	RRule       *string `db:"rrule" gorm:"column:rrule"`
+	StartDate   string  `db:"start_date"`
+	EndDate     *string `db:"end_date"`
+	NextRunDate *string `db:"next_run_date"`
+	LastRunDate *string `db:"last_run_date"`
+	IsPaused    bool    `db:"is_paused"`
+}
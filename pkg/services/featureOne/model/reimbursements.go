@@ -0,0 +1,86 @@
+package mdlFeatureOne
+
+// Reimbursement report statuses
+const (
+	ReimbursementDraft     = "draft"
+	ReimbursementSubmitted = "submitted"
+	ReimbursementApproved  = "approved"
+	ReimbursementRejected  = "rejected"
+	ReimbursementPaid      = "paid"
+)
+
+// Who may move a report between two statuses
+const (
+	ReimbursementRoleOwner    = "owner"
+	ReimbursementRoleApprover = "approver"
+)
+
+// ============================================
+// REIMBURSEMENT REQUEST STRUCTS
+// ============================================
+
+// ReimbursementReportRequest creates a report or replaces a draft. The approver is
+// named by approverId or approverEmail and may be left out until submission;
+// expenseIds replaces the expenses the report claims.
+type ReimbursementReportRequest struct {
+	Title         string  `json:"title"`
+	Description   *string `json:"description"`
+	ApproverID    *int    `json:"approverId"`
+	ApproverEmail *string `json:"approverEmail"`
+	ExpenseIDs    []int   `json:"expenseIds"`
+}
+
+// ReimbursementTransitionRequest moves a report to a new status; rejecting needs a comment
+type ReimbursementTransitionRequest struct {
+	Status  string  `json:"status"`
+	Comment *string `json:"comment"`
+}
+
+// ReimbursementReportFilters selects the reports a user owns (role=owner, the
+// default) or has been asked to approve (role=approver)
+type ReimbursementReportFilters struct {
+	Role   string
+	Status *string
+}
+
+// ============================================
+// REIMBURSEMENT RESPONSE STRUCTS
+// ============================================
+
+type ReimbursementUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type ReimbursementReportResponse struct {
+	ID           int                `json:"id"`
+	Title        string             `json:"title"`
+	Description  *string            `json:"description"`
+	Status       string             `json:"status"`
+	Owner        ReimbursementUser  `json:"owner"`
+	Approver     *ReimbursementUser `json:"approver"`
+	ExpenseCount int                `json:"expenseCount"`
+	Total        float64            `json:"total"`
+	SubmittedAt  *string            `json:"submittedAt"`
+	DecidedAt    *string            `json:"decidedAt"`
+	PaidAt       *string            `json:"paidAt"`
+	CreatedAt    string             `json:"createdAt"`
+	UpdatedAt    string             `json:"updatedAt"`
+}
+
+// ReimbursementEventResponse is one status change of a report
+type ReimbursementEventResponse struct {
+	ID         int                `json:"id"`
+	Actor      *ReimbursementUser `json:"actor"`
+	FromStatus *string            `json:"fromStatus"`
+	ToStatus   string             `json:"toStatus"`
+	Comment    *string            `json:"comment"`
+	CreatedAt  string             `json:"createdAt"`
+}
+
+type ReimbursementReportDetailResponse struct {
+	ReimbursementReportResponse
+	Expenses []ExpenseResponse            `json:"expenses"`
+	History  []ReimbursementEventResponse `json:"history"`
+}
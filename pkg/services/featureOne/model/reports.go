@@ -0,0 +1,85 @@
+package mdlFeatureOne
+
+// ============================================
+// REPORT RESPONSE STRUCTS
+// ============================================
+
+type ReportPeriod struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	Days      int    `json:"days"`
+}
+
+type CategoryReportItem struct {
+	CategoryID   *int    `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	Total        float64 `json:"total"`
+	Count        int     `json:"count"`
+	Share        float64 `json:"share"`
+}
+
+type TimeReportItem struct {
+	PeriodStart string  `json:"periodStart"`
+	PeriodEnd   string  `json:"periodEnd"`
+	Total       float64 `json:"total"`
+	Count       int     `json:"count"`
+}
+
+// TagReportItem has a nil Tag for untagged expenses
+type TagReportItem struct {
+	Tag   *string `json:"tag"`
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+}
+
+type TitleReportItem struct {
+	Title    string  `json:"title"`
+	Total    float64 `json:"total"`
+	Count    int     `json:"count"`
+	LastDate string  `json:"lastDate"`
+}
+
+type PreviousPeriodSummary struct {
+	Period       ReportPeriod `json:"period"`
+	Total        float64      `json:"total"`
+	Count        int          `json:"count"`
+	AverageDaily float64      `json:"averageDaily"`
+}
+
+// PeriodChange compares a period with the previous one; Percent is nil when the previous total is 0
+type PeriodChange struct {
+	Amount  float64  `json:"amount"`
+	Percent *float64 `json:"percent"`
+}
+
+type ReportSummaryResponse struct {
+	Period         ReportPeriod          `json:"period"`
+	Total          float64               `json:"total"`
+	Count          int                   `json:"count"`
+	AverageExpense float64               `json:"averageExpense"`
+	AverageDaily   float64               `json:"averageDaily"`
+	Previous       PreviousPeriodSummary `json:"previous"`
+	Change         PeriodChange          `json:"change"`
+	TopTitles      []TitleReportItem     `json:"topTitles"`
+}
+
+type CategoryReportResponse struct {
+	Period     ReportPeriod         `json:"period"`
+	Categories []CategoryReportItem `json:"categories"`
+}
+
+type TimeReportResponse struct {
+	Period   ReportPeriod     `json:"period"`
+	Interval string           `json:"interval"`
+	Series   []TimeReportItem `json:"series"`
+}
+
+type TagReportResponse struct {
+	Period ReportPeriod    `json:"period"`
+	Tags   []TagReportItem `json:"tags"`
+}
+
+type TitleReportResponse struct {
+	Period ReportPeriod      `json:"period"`
+	Titles []TitleReportItem `json:"titles"`
+}
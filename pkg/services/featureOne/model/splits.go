@@ -0,0 +1,107 @@
+package mdlFeatureOne
+
+// ============================================
+// SPLIT REQUEST STRUCTS
+// ============================================
+
+// SplitParticipantRequest names a participant by userId or email. Value is the
+// exact amount, the percentage or the number of shares, depending on the method;
+// it is ignored for equal splits.
+type SplitParticipantRequest struct {
+	UserID *int     `json:"userId"`
+	Email  *string  `json:"email"`
+	Value  *float64 `json:"value"`
+}
+
+// SetSplitRequest splits an expense between its owner (the payer) and others.
+// The payer may be listed to take a share; otherwise only the others owe.
+type SetSplitRequest struct {
+	Method       string                    `json:"method"`
+	Participants []SplitParticipantRequest `json:"participants"`
+}
+
+// CreateSettlementRequest records that payerId paid payeeId back. The current
+// user must be one of the two; amount defaults to the full balance between them.
+type CreateSettlementRequest struct {
+	PayerID int      `json:"payerId"`
+	PayeeID int      `json:"payeeId"`
+	Amount  *float64 `json:"amount"`
+	Date    string   `json:"date"`
+	Notes   *string  `json:"notes"`
+}
+
+type SettlementFilters struct {
+	UserID *int `json:"userId"`
+	Limit  int  `json:"limit"`
+	Offset int  `json:"offset"`
+}
+
+// ============================================
+// SPLIT RESPONSE STRUCTS
+// ============================================
+
+type SplitShareResponse struct {
+	UserID int     `json:"userId"`
+	Name   string  `json:"name"`
+	Email  string  `json:"email"`
+	Value  float64 `json:"value"`
+	Amount float64 `json:"amount"`
+}
+
+type SplitResponse struct {
+	ExpenseID int                  `json:"expenseId"`
+	PayerID   int                  `json:"payerId"`
+	Method    string               `json:"method"`
+	Total     float64              `json:"total"`
+	Shares    []SplitShareResponse `json:"shares"`
+	CreatedAt string               `json:"createdAt"`
+	UpdatedAt string               `json:"updatedAt"`
+}
+
+// UserBalance is the net balance with another user; positive means they owe you
+type UserBalance struct {
+	UserID  int     `json:"userId"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Balance float64 `json:"balance"`
+}
+
+type BalancesResponse struct {
+	Balances  []UserBalance `json:"balances"`
+	OwedToYou float64       `json:"owedToYou"`
+	YouOwe    float64       `json:"youOwe"`
+	Net       float64       `json:"net"`
+}
+
+// BalanceActivityItem is a split or settlement between two users; a positive
+// amount raised what the other user owes you
+type BalanceActivityItem struct {
+	Type      string  `json:"type"`
+	ID        int     `json:"id"`
+	ExpenseID *int    `json:"expenseId"`
+	Title     string  `json:"title"`
+	Date      string  `json:"date"`
+	Amount    float64 `json:"amount"`
+}
+
+type UserBalanceResponse struct {
+	UserBalance
+	Activity   []BalanceActivityItem `json:"activity"`
+	Pagination PaginationResponse    `json:"pagination"`
+}
+
+type SettlementResponse struct {
+	ID        int     `json:"id"`
+	PayerID   int     `json:"payerId"`
+	PayeeID   int     `json:"payeeId"`
+	Amount    float64 `json:"amount"`
+	Date      string  `json:"date"`
+	Notes     *string `json:"notes"`
+	CreatedBy int     `json:"createdBy"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type SettlementListResponse struct {
+	Settlements []SettlementResponse `json:"settlements"`
+	Pagination  PaginationResponse   `json:"pagination"`
+}
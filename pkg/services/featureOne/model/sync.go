@@ -0,0 +1,125 @@
+package mdlFeatureOne
+
+// ============================================
+// SYNC CONSTANTS
+// ============================================
+
+const (
+	SyncEntityExpense   = "expense"
+	SyncEntityTag       = "tag"
+	SyncEntityCategory  = "category"
+	SyncOperationUpsert = "upsert"
+	SyncOperationDelete = "delete"
+	SyncStatusApplied   = "applied"
+	SyncStatusConflict  = "conflict"
+	SyncStatusRejected  = "rejected"
+)
+
+// ============================================
+// SYNC REQUEST STRUCTS
+// ============================================
+
+// SyncFilters asks for the changes after the position a sync token names
+type SyncFilters struct {
+	Since int64 `json:"since"`
+	Limit int   `json:"limit"`
+}
+
+type SyncPushRequest struct {
+	Changes []SyncChange `json:"changes"`
+}
+
+// SyncChange is one change a client made to an expense while offline. An expense
+// is named by its server ID or, until the client has learnt it, by the UUID the
+// client gave it. Version is the server version the change was based on; it is
+// required for every change to an expense the server already has.
+type SyncChange struct {
+	ClientID  *string          `json:"clientId"`
+	Entity    string           `json:"entity"`
+	Operation string           `json:"operation"` // upsert or delete
+	ID        *int             `json:"id"`
+	Version   *int             `json:"version"`
+	Data      *SyncExpenseData `json:"data"`
+}
+
+// SyncExpenseData is the complete state of an expense after an upsert; optional
+// fields left out are cleared. Images are managed through the attachment endpoints.
+type SyncExpenseData struct {
+	Title      string   `json:"title"`
+	Amount     float64  `json:"amount"`
+	CategoryID *int     `json:"categoryId"`
+	Merchant   *string  `json:"merchant"`
+	AccountID  *int     `json:"accountId"`
+	Date       string   `json:"date"`
+	Notes      *string  `json:"notes"`
+	Tags       []string `json:"tags"`
+}
+
+// ============================================
+// SYNC RESPONSE STRUCTS
+// ============================================
+
+// SyncExpense is an expense with the UUID of the client that created it
+type SyncExpense struct {
+	ExpenseResponse
+	ClientID *string `json:"clientId"`
+}
+
+// SyncTombstone reports a record deleted since the token
+type SyncTombstone struct {
+	Entity    string  `json:"entity"`
+	ID        int     `json:"id"`
+	ClientID  *string `json:"clientId"`
+	DeletedAt string  `json:"deletedAt"`
+}
+
+// SyncPullResponse holds the records changed since the token. Token is passed as
+// ?since= on the next pull; while HasMore is set there are more changes to fetch.
+type SyncPullResponse struct {
+	Expenses   []SyncExpense      `json:"expenses"`
+	Categories []CategoryResponse `json:"categories"`
+	Tags       []TagResponse      `json:"tags"`
+	Deleted    []SyncTombstone    `json:"deleted"`
+	Token      string             `json:"token"`
+	HasMore    bool               `json:"hasMore"`
+}
+
+// SyncChangeResult is the outcome of one pushed change. A conflict carries the
+// server's copy of the expense unless it was deleted.
+type SyncChangeResult struct {
+	Index    int          `json:"index"`
+	ClientID *string      `json:"clientId"`
+	ID       *int         `json:"id"`
+	Status   string       `json:"status"` // applied, conflict or rejected
+	Message  string       `json:"message,omitempty"`
+	Expense  *SyncExpense `json:"expense,omitempty"`
+}
+
+type SyncPushResponse struct {
+	Results   []SyncChangeResult `json:"results"`
+	Applied   int                `json:"applied"`
+	Conflicts int                `json:"conflicts"`
+	Rejected  int                `json:"rejected"`
+}
+
+// ============================================
+// SYNC ENTITY STRUCTS (DB)
+// ============================================
+
+// SyncChangeEntity is the latest change of a synced record
+type SyncChangeEntity struct {
+	Entity    string  `db:"entity"`
+	EntityID  int     `db:"entity_id"`
+	ClientID  *string `db:"client_id"`
+	Operation string  `db:"operation"`
+	Seq       int64   `db:"seq"`
+	ChangedAt string  `db:"changed_at"`
+}
+
+// SyncExpenseState is where the expense a pushed change names stands on the
+// server; ID is 0 when it is unknown
+type SyncExpenseState struct {
+	ID      int  `db:"id"`
+	Version int  `db:"version"`
+	Deleted bool `db:"deleted"`
+}
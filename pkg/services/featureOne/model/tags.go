@@ -0,0 +1,35 @@
+package mdlFeatureOne
+
+// ============================================
+// TAG REQUEST STRUCTS
+// ============================================
+
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name"`
+}
+
+type MergeTagRequest struct {
+	TargetTagID int `json:"targetTagId"`
+}
+
+// ============================================
+// TAG RESPONSE STRUCTS
+// ============================================
+
+type TagResponse struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	UsageCount int    `json:"usageCount"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+type MergeTagResponse struct {
+	Tag         TagResponse `json:"tag"`
+	MovedLinks  int64       `json:"movedLinks"`
+	MergedTagID int         `json:"mergedTagId"`
+}
@@ -0,0 +1,78 @@
+package mdlFeatureOne
+
+// ============================================
+// TRANSACTION REQUEST STRUCTS
+// ============================================
+
+type CreateTransactionRequest struct {
+	Type              string   `json:"type"`
+	Title             string   `json:"title"`
+	Amount            float64  `json:"amount"`
+	CategoryID        *int     `json:"categoryId"`
+	AccountID         *int     `json:"accountId"`
+	TransferAccountID *int     `json:"transferAccountId"`
+	Date              string   `json:"date"`
+	Notes             *string  `json:"notes"`
+	ImageURL          *string  `json:"imageUrl"`
+	Tags              []string `json:"tags"`
+}
+
+type UpdateTransactionRequest struct {
+	Type              *string  `json:"type"`
+	Title             *string  `json:"title"`
+	Amount            *float64 `json:"amount"`
+	CategoryID        *int     `json:"categoryId"`
+	AccountID         *int     `json:"accountId"`
+	TransferAccountID *int     `json:"transferAccountId"`
+	Date              *string  `json:"date"`
+	Notes             *string  `json:"notes"`
+	ImageURL          *string  `json:"imageUrl"`
+	Tags              []string `json:"tags"`
+}
+
+// TransactionFilters extends ExpenseFilters with a type and account filter
+type TransactionFilters struct {
+	ExpenseFilters
+	Type      *string `json:"type"`
+	AccountID *int    `json:"accountId"`
+}
+
+// ============================================
+// TRANSACTION RESPONSE STRUCTS
+// ============================================
+
+type TransactionResponse struct {
+	ID                int                  `json:"id"`
+	Type              string               `json:"type"`
+	Title             string               `json:"title"`
+	Amount            float64              `json:"amount"`
+	Category          *CategoryInfo        `json:"category"`
+	Merchant          *MerchantInfo        `json:"merchant"`
+	AccountID         *int                 `json:"accountId"`
+	TransferAccountID *int                 `json:"transferAccountId"`
+	Date              string               `json:"date"`
+	Notes             *string              `json:"notes"`
+	ImageURL          *string              `json:"imageUrl"`
+	ExternalID        *string              `json:"externalId"` // bank transaction ID of imported statements
+	Tags              []string             `json:"tags"`
+	Attachments       []AttachmentResponse `json:"attachments"`
+	Version           int                  `json:"version"`
+	CreatedAt         string               `json:"createdAt"`
+	UpdatedAt         string               `json:"updatedAt"`
+
+	// Set only for full-text searches (q)
+	Rank       *float64          `json:"rank,omitempty"`
+	Highlights *SearchHighlights `json:"highlights,omitempty"`
+}
+
+type TransactionSummary struct {
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+	Net     float64 `json:"net"`
+}
+
+type TransactionListResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	Summary      TransactionSummary    `json:"summary"`
+	Pagination   PaginationResponse    `json:"pagination"`
+}
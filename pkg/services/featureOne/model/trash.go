@@ -0,0 +1,28 @@
+package mdlFeatureOne
+
+// ============================================
+// TRASH RESPONSE STRUCTS
+// ============================================
+
+// TrashedExpenseResponse is a deleted expense; it can be restored until PurgeAt
+type TrashedExpenseResponse struct {
+	ExpenseResponse
+	DeletedAt string `json:"deletedAt"`
+	PurgeAt   string `json:"purgeAt"`
+}
+
+type TrashListResponse struct {
+	Expenses      []TrashedExpenseResponse `json:"expenses"`
+	RetentionDays int                      `json:"retentionDays"`
+	Pagination    PaginationResponse       `json:"pagination"`
+}
+
+// ============================================
+// TRASH HELPER STRUCTS
+// ============================================
+
+// PurgeResult is what purge_deleted_transactions removed in one batch
+type PurgeResult struct {
+	Purged int              `json:"purged"`
+	Files  []AttachmentFile `json:"files"`
+}
@@ -0,0 +1,116 @@
+package mdlFeatureOne
+
+import "encoding/json"
+
+// ============================================
+// WEBHOOK CONSTANTS
+// ============================================
+
+const (
+	WebhookEventExpenseCreated     = "expense.created"
+	WebhookEventExpenseUpdated     = "expense.updated"
+	WebhookEventExpenseDeleted     = "expense.deleted"
+	WebhookEventExpenseRestored    = "expense.restored"
+	WebhookEventBatchJobCompleted  = "batch_job.completed"
+	WebhookEventBatchJobFailed     = "batch_job.failed"
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// ============================================
+// WEBHOOK REQUEST STRUCTS
+// ============================================
+
+// WebhookEndpointRequest registers or replaces a webhook endpoint. IsActive is
+// only read on update, where true re-enables an endpoint disabled after failures.
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url"`
+	Description *string  `json:"description"`
+	Events      []string `json:"events"`
+	IsActive    *bool    `json:"isActive"`
+}
+
+type WebhookDeliveryFilters struct {
+	Status string `json:"status"`
+	Event  string `json:"event"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// ============================================
+// WEBHOOK RESPONSE STRUCTS
+// ============================================
+
+type WebhookEndpointResponse struct {
+	ID            int      `json:"id"`
+	URL           string   `json:"url"`
+	Description   *string  `json:"description"`
+	Events        []string `json:"events"`
+	IsActive      bool     `json:"isActive"`
+	FailureCount  int      `json:"failureCount"`
+	DisabledAt    *string  `json:"disabledAt"`
+	LastSuccessAt *string  `json:"lastSuccessAt"`
+	LastFailureAt *string  `json:"lastFailureAt"`
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+// WebhookEndpointCreatedResponse carries the signing secret, which is only
+// shown when the endpoint is created
+type WebhookEndpointCreatedResponse struct {
+	WebhookEndpointResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	EndpointID     int             `json:"endpointId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *string         `json:"nextAttemptAt"`
+	LastAttemptAt  *string         `json:"lastAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode"`
+	LastError      *string         `json:"lastError"`
+	DurationMs     *int            `json:"durationMs"`
+	DeliveredAt    *string         `json:"deliveredAt"`
+	CreatedAt      string          `json:"createdAt"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Pagination PaginationResponse        `json:"pagination"`
+}
+
+// WebhookEventEnvelope is the signed body POSTed to an endpoint. ID is the
+// delivery ID, which stays the same on retries so receivers can drop repeats.
+type WebhookEventEnvelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt string          `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookExpenseDeletedData is the data of an expense.deleted event
+type WebhookExpenseDeletedData struct {
+	ID int `json:"id"`
+}
+
+// ============================================
+// WEBHOOK ENTITY STRUCTS (DB)
+// ============================================
+
+// WebhookDeliveryEntity is a queued delivery claimed by the delivery worker
+type WebhookDeliveryEntity struct {
+	ID         int64  `db:"id"`
+	EndpointID int    `db:"endpoint_id"`
+	UserID     int    `db:"user_id"`
+	URL        string `db:"url"`
+	Secret     string `db:"secret"`
+	Event      string `db:"event"`
+	Payload    string `db:"payload"`
+	Attempts   int    `db:"attempts"`
+	CreatedAt  string `db:"created_at"`
+}
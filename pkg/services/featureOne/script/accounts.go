@@ -0,0 +1,309 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+)
+
+// ============================================
+// ACCOUNT OPERATIONS
+// ============================================
+
+// AccountExists checks if an account exists for a user
+func AccountExists(userID, accountID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM accounts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		accountID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[AccountExists] Error checking account %d for user %d: %v", accountID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateAccount inserts a new account
+func CreateAccount(userID int, req *mdlFeatureOne.CreateAccountRequest) (*mdlFeatureOne.AccountResponse, error) {
+	var account mdlFeatureOne.AccountResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		WITH inserted AS (
+			INSERT INTO accounts (user_id, name, type, currency, opening_balance)
+			VALUES (?, ?, ?, ?, ?)
+			RETURNING *
+		)
+		SELECT account_to_jsonb(inserted)::text FROM inserted
+	`, userID, req.Name, req.Type, req.Currency, req.OpeningBalance).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[CreateAccount] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &account); err != nil {
+		log.Printf("[CreateAccount] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[CreateAccount] Success - AccountID: %d, UserID: %d, Name: %s", account.ID, userID, account.Name)
+	return &account, nil
+}
+
+// GetAccounts retrieves all accounts of a user with their current balances
+func GetAccounts(userID int) ([]mdlFeatureOne.AccountResponse, error) {
+	accounts := []mdlFeatureOne.AccountResponse{}
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(jsonb_agg(account_to_jsonb(a) ORDER BY a.name, a.id), '[]'::jsonb)::text
+		FROM accounts a
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
+	`, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetAccounts] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &accounts); err != nil {
+		log.Printf("[GetAccounts] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetAccounts] Success - UserID: %d, Count: %d", userID, len(accounts))
+	return accounts, nil
+}
+
+// GetAccountByID retrieves a single account with its current balance
+func GetAccountByID(userID, accountID int) (*mdlFeatureOne.AccountResponse, error) {
+	var account mdlFeatureOne.AccountResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT account_to_jsonb(a)::text
+		FROM accounts a
+		WHERE a.id = ? AND a.user_id = ? AND a.deleted_at IS NULL
+	`, accountID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetAccountByID] Error for user %d, account %d: %v", userID, accountID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &account); err != nil {
+		log.Printf("[GetAccountByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// UpdateAccount replaces the name, type, currency and opening balance of an account.
+// Changing the opening balance shifts every running balance of the account.
+func UpdateAccount(userID, accountID int, req *mdlFeatureOne.CreateAccountRequest) (*mdlFeatureOne.AccountResponse, error) {
+	err := config.DBConnList[0].Exec(`
+		UPDATE accounts SET
+			name            = ?,
+			type            = ?,
+			currency        = ?,
+			opening_balance = ?,
+			updated_at      = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, req.Name, req.Type, req.Currency, req.OpeningBalance, accountID, userID).Error
+
+	if err != nil {
+		log.Printf("[UpdateAccount] Error for user %d, account %d: %v", userID, accountID, err)
+		return nil, err
+	}
+
+	log.Printf("[UpdateAccount] Success - AccountID: %d, UserID: %d", accountID, userID)
+	return GetAccountByID(userID, accountID)
+}
+
+// ============================================
+// LEDGER OPERATIONS
+// ============================================
+
+// GetAccountLedger retrieves the transactions of an account oldest first, each
+// with the running balance after it
+func GetAccountLedger(userID, accountID int, filters *mdlFeatureOne.AccountLedgerFilters) (*mdlFeatureOne.AccountLedgerResponse, error) {
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		log.Printf("[GetAccountLedger] Error marshaling filters: %v", err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = config.DBConnList[0].Raw(
+		`SELECT get_account_ledger($1, $2, $3::jsonb)::text`,
+		userID,
+		accountID,
+		string(filtersJSON),
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetAccountLedger] Error for user %d, account %d: %v", userID, accountID, err)
+		return nil, err
+	}
+
+	var ledger mdlFeatureOne.AccountLedgerResponse
+	if err := json.Unmarshal([]byte(jsonResult), &ledger); err != nil {
+		log.Printf("[GetAccountLedger] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetAccountLedger] Success - AccountID: %d, UserID: %d, Entries: %d/%d",
+		accountID, userID, len(ledger.Entries), ledger.Pagination.Total)
+	return &ledger, nil
+}
+
+// GetAccountBalanceHistory retrieves the balance of an account at the end of each
+// interval between two dates
+func GetAccountBalanceHistory(userID, accountID int, startDate, endDate, interval string) (*mdlFeatureOne.AccountBalanceHistoryResponse, error) {
+	result := mdlFeatureOne.AccountBalanceHistoryResponse{
+		AccountID: accountID,
+		Interval:  interval,
+		Balances:  []mdlFeatureOne.AccountBalancePoint{},
+	}
+
+	var jsonResult string
+	err := config.DBConnList[0].Raw(
+		`SELECT get_account_balance_history($1, $2, $3::date, $4::date, $5)::text`,
+		userID,
+		accountID,
+		startDate,
+		endDate,
+		interval,
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetAccountBalanceHistory] Error for user %d, account %d: %v", userID, accountID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Balances); err != nil {
+		log.Printf("[GetAccountBalanceHistory] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ============================================
+// RECONCILIATION OPERATIONS
+// ============================================
+
+// AccountReconciliationExists checks if a reconciliation belongs to an account
+func AccountReconciliationExists(accountID, reconciliationID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM account_reconciliations WHERE id = $1 AND account_id = $2)`,
+		reconciliationID,
+		accountID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[AccountReconciliationExists] Error checking reconciliation %d of account %d: %v",
+			reconciliationID, accountID, err)
+		return false
+	}
+
+	return exists
+}
+
+// ReconcileAccount marks transactions of an account as cleared against a statement.
+// When some of them cannot be cleared nothing is marked and their IDs are returned.
+func ReconcileAccount(userID, accountID int, req *mdlFeatureOne.ReconcileAccountRequest) (*mdlFeatureOne.AccountReconciliationResponse, []int, error) {
+	idsJSON, err := json.Marshal(req.TransactionIDs)
+	if err != nil {
+		log.Printf("[ReconcileAccount] Error marshaling transaction IDs: %v", err)
+		return nil, nil, err
+	}
+
+	var jsonResult string
+	err = config.DBConnList[0].Raw(
+		`SELECT reconcile_account($1, $2, $3::date, $4, $5::jsonb)::text`,
+		userID,
+		accountID,
+		req.StatementDate,
+		req.StatementBalance,
+		string(idsJSON),
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[ReconcileAccount] Error for user %d, account %d: %v", userID, accountID, err)
+		return nil, nil, err
+	}
+
+	var result struct {
+		mdlFeatureOne.AccountReconciliationResponse
+		InvalidTransactionIDs []int `json:"invalidTransactionIds"`
+	}
+	if err := json.Unmarshal([]byte(jsonResult), &result); err != nil {
+		log.Printf("[ReconcileAccount] JSON parse error: %v", err)
+		return nil, nil, err
+	}
+	if len(result.InvalidTransactionIDs) > 0 {
+		log.Printf("[ReconcileAccount] Rejected - AccountID: %d, UserID: %d, Invalid: %v",
+			accountID, userID, result.InvalidTransactionIDs)
+		return nil, result.InvalidTransactionIDs, nil
+	}
+
+	log.Printf("[ReconcileAccount] Success - ReconciliationID: %d, AccountID: %d, Cleared: %d, Difference: %.2f",
+		result.ID, accountID, len(result.TransactionIDs), result.Difference)
+	return &result.AccountReconciliationResponse, nil, nil
+}
+
+// GetAccountReconciliations retrieves the reconciliations of an account, newest first
+func GetAccountReconciliations(accountID int) ([]mdlFeatureOne.AccountReconciliationResponse, error) {
+	reconciliations := []mdlFeatureOne.AccountReconciliationResponse{}
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(jsonb_agg(account_reconciliation_to_jsonb(r)
+			ORDER BY r.statement_date DESC, r.id DESC), '[]'::jsonb)::text
+		FROM account_reconciliations r
+		WHERE r.account_id = ?
+	`, accountID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetAccountReconciliations] Error for account %d: %v", accountID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &reconciliations); err != nil {
+		log.Printf("[GetAccountReconciliations] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return reconciliations, nil
+}
+
+// DeleteAccountReconciliation undoes a reconciliation so its transactions are
+// uncleared again; the cleared balances recorded by other reconciliations are kept
+func DeleteAccountReconciliation(accountID, reconciliationID int) error {
+	err := config.DBConnList[0].Exec(
+		`DELETE FROM account_reconciliations WHERE id = ? AND account_id = ?`,
+		reconciliationID,
+		accountID,
+	).Error
+
+	if err != nil {
+		log.Printf("[DeleteAccountReconciliation] Error for account %d, reconciliation %d: %v",
+			accountID, reconciliationID, err)
+		return err
+	}
+
+	log.Printf("[DeleteAccountReconciliation] Success - ReconciliationID: %d, AccountID: %d",
+		reconciliationID, accountID)
+	return nil
+}
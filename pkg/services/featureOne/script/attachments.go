@@ -0,0 +1,201 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// attachmentColumns selects an attachment in the shape of AttachmentResponse
+const attachmentColumns = `
+	a.id, a.url, a.storage, a.filename, a.content_type AS "contentType", a.size_bytes AS size,
+	a.checksum, a.created_at AS "createdAt"`
+
+// ============================================
+// ATTACHMENT OPERATIONS
+// ============================================
+
+// AttachmentExists checks if an attachment belongs to an expense of the user
+func AttachmentExists(userID, expenseID, attachmentID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM expense_attachments a
+			JOIN transactions t ON t.id = a.transaction_id
+			WHERE a.id = $1 AND a.transaction_id = $2 AND t.user_id = $3 AND t.deleted_at IS NULL)`,
+		attachmentID,
+		expenseID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[AttachmentExists] Error checking attachment %d for user %d: %v", attachmentID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CountExpenseAttachments returns how many files an expense carries
+func CountExpenseAttachments(expenseID int) (int, error) {
+	var count int
+
+	err := config.DBConnList[0].Raw(
+		`SELECT COUNT(*) FROM expense_attachments WHERE transaction_id = $1`,
+		expenseID,
+	).Scan(&count).Error
+
+	if err != nil {
+		log.Printf("[CountExpenseAttachments] Error for expense %d: %v", expenseID, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// AttachmentChecksumExists checks if a file with the checksum is already attached to the expense
+func AttachmentChecksumExists(expenseID int, checksum string) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM expense_attachments WHERE transaction_id = $1 AND checksum = $2)`,
+		expenseID,
+		checksum,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[AttachmentChecksumExists] Error for expense %d: %v", expenseID, err)
+		return false
+	}
+
+	return exists
+}
+
+// AddExpenseAttachments records uploaded files on an expense in one transaction
+func AddExpenseAttachments(userID, expenseID int, attachments []mdlFeatureOne.NewAttachment) ([]mdlFeatureOne.AttachmentResponse, error) {
+	ids := make([]int, 0, len(attachments))
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		for _, a := range attachments {
+			var id int
+			err := tx.Raw(`
+				INSERT INTO expense_attachments (transaction_id, url, storage, filename, content_type, size_bytes, checksum)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				RETURNING id
+			`, expenseID, a.URL, a.Storage, a.Filename, a.ContentType, a.Size, a.Checksum).Scan(&id).Error
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+
+		// Attachments are part of the expense, so it counts as modified
+		return tx.Exec(`UPDATE transactions SET updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?`,
+			expenseID, userID).Error
+	})
+	if err != nil {
+		log.Printf("[AddExpenseAttachments] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+
+	created := []mdlFeatureOne.AttachmentResponse{}
+	err = config.DBConnList[0].Raw(`
+		SELECT `+attachmentColumns+`
+		FROM expense_attachments a
+		WHERE a.id IN ?
+		ORDER BY a.id
+	`, ids).Scan(&created).Error
+	if err != nil {
+		log.Printf("[AddExpenseAttachments] Error reading attachments of expense %d: %v", expenseID, err)
+		return nil, err
+	}
+
+	log.Printf("[AddExpenseAttachments] Success - ExpenseID: %d, UserID: %d, Count: %d",
+		expenseID, userID, len(created))
+	emitExpenseUpdated(userID, expenseID)
+	return created, nil
+}
+
+// GetExpenseAttachments retrieves the files of an expense, oldest first
+func GetExpenseAttachments(userID, expenseID int) ([]mdlFeatureOne.AttachmentResponse, error) {
+	var jsonResult string
+	attachments := []mdlFeatureOne.AttachmentResponse{}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(json_agg(x ORDER BY x.id), '[]')::text
+		FROM (
+			SELECT `+attachmentColumns+`
+			FROM expense_attachments a
+			JOIN transactions t ON t.id = a.transaction_id
+			WHERE a.transaction_id = ? AND t.user_id = ?
+		) x
+	`, expenseID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetExpenseAttachments] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &attachments); err != nil {
+		log.Printf("[GetExpenseAttachments] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// DeleteExpenseAttachment removes an attachment and returns where its file is
+// stored; the expense's imageUrl is cleared when it pointed at the same file
+func DeleteExpenseAttachment(userID, expenseID, attachmentID int) (*mdlFeatureOne.AttachmentFile, error) {
+	var file mdlFeatureOne.AttachmentFile
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`
+			DELETE FROM expense_attachments a
+			USING transactions t
+			WHERE a.transaction_id = t.id AND a.id = ? AND t.id = ? AND t.user_id = ?
+			RETURNING a.url, a.storage
+		`, attachmentID, expenseID, userID).Scan(&file).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE transactions
+			SET image_url = CASE WHEN image_url = ? THEN NULL ELSE image_url END,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ?
+		`, file.URL, expenseID, userID).Error
+	})
+	if err != nil {
+		log.Printf("[DeleteExpenseAttachment] Error for user %d, attachment %d: %v", userID, attachmentID, err)
+		return nil, err
+	}
+
+	log.Printf("[DeleteExpenseAttachment] Success - AttachmentID: %d, ExpenseID: %d, UserID: %d",
+		attachmentID, expenseID, userID)
+	emitExpenseUpdated(userID, expenseID)
+	return &file, nil
+}
+
+// RemoveAttachmentFile deletes a stored file from its backend; failures are only logged
+func RemoveAttachmentFile(file mdlFeatureOne.AttachmentFile) {
+	if file.Storage == "cloudinary" {
+		publicID := config.CloudinaryPublicIDFromURL(file.URL)
+		if publicID == "" {
+			return
+		}
+		if err := config.DeleteCloudinaryImage(publicID, config.LoadCloudinaryConfig()); err != nil {
+			log.Printf("[RemoveAttachmentFile] Error deleting Cloudinary file %s: %v", file.URL, err)
+		}
+		return
+	}
+
+	if err := utils.DeleteAssetFile(file.URL); err != nil {
+		log.Printf("[RemoveAttachmentFile] Error deleting file %s: %v", file.URL, err)
+	}
+}
@@ -0,0 +1,63 @@
+package scpFeatureOne
+
+import (
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// ============================================
+// BANK STATEMENT OPERATIONS
+// ============================================
+
+// ImportExpense creates an expense from a bank statement line, keeping the bank's
+// transaction ID. It returns nil without an error when that ID was already imported,
+// which the unique index decides atomically even for concurrent imports.
+func ImportExpense(userID int, req *mdlFeatureOne.CreateExpenseRequest, externalID string, source mdlFeatureOne.RevisionSource) (*mdlFeatureOne.ExpenseResponse, error) {
+	var expenseID int
+
+	var inserted bool
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`
+			INSERT INTO transactions (user_id, type, title, amount, category_id, date, notes, external_id)
+			VALUES (?, 'expense', ?, ?, ?, ?::date, ?, ?)
+			ON CONFLICT (user_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
+			RETURNING id
+		`, userID, req.Title, req.Amount, req.CategoryID, req.Date, req.Notes, externalID).Scan(&expenseID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		inserted = true
+
+		if len(req.Tags) > 0 {
+			return setTransactionTags(tx, userID, expenseID, req.Tags)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("[ImportExpense] Error for user %d, external ID %s: %v", userID, externalID, err)
+		return nil, err
+	}
+	if !inserted {
+		log.Printf("[ImportExpense] Skipped - UserID: %d, ExternalID: %s already imported", userID, externalID)
+		return nil, nil
+	}
+
+	completeNewExpense(userID, expenseID, req)
+
+	recordExpenseRevision(userID, expenseID, source)
+
+	created, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[ImportExpense] Success - ExpenseID: %d, UserID: %d, ExternalID: %s, Amount: %.2f",
+		created.ID, userID, externalID, created.Amount)
+	checkBudgetAlertsAsync(userID, created)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseCreated, created)
+	return created, nil
+}
@@ -0,0 +1,356 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"fmt"
+	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"html"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// budgetJSONSelect selects budgets with the keys of BudgetResponse
+const budgetJSONSelect = `
+	SELECT b.id, b.name, b.category_id AS "categoryId", b.amount, b.period,
+		TO_CHAR(b.start_date, 'YYYY-MM-DD') AS "startDate",
+		TO_CHAR(b.end_date, 'YYYY-MM-DD') AS "endDate",
+		b.rollover, b.thresholds, b.created_at AS "createdAt", b.updated_at AS "updatedAt"
+	FROM budgets b`
+
+// ============================================
+// BUDGET OPERATIONS
+// ============================================
+
+// BudgetExists checks if a budget exists for a user
+func BudgetExists(userID, budgetID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM budgets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		budgetID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[BudgetExists] Error checking budget %d for user %d: %v", budgetID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateBudget inserts a new budget
+func CreateBudget(userID int, req *mdlFeatureOne.CreateBudgetRequest) (*mdlFeatureOne.BudgetResponse, error) {
+	thresholdsJSON, _ := json.Marshal(req.Thresholds)
+
+	var budgetID int
+	err := config.DBConnList[0].Raw(`
+		INSERT INTO budgets (user_id, name, category_id, amount, period, start_date, end_date, rollover, thresholds)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ARRAY(SELECT jsonb_array_elements_text(?::jsonb)::int))
+		RETURNING id
+	`, userID, req.Name, req.CategoryID, req.Amount, req.Period, req.StartDate, req.EndDate,
+		req.Rollover, string(thresholdsJSON)).Scan(&budgetID).Error
+
+	if err != nil {
+		log.Printf("[CreateBudget] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[CreateBudget] Success - BudgetID: %d, UserID: %d, Period: %s, Amount: %.2f",
+		budgetID, userID, req.Period, req.Amount)
+	return GetBudgetByID(userID, budgetID)
+}
+
+// GetBudgets retrieves all active budgets of a user
+func GetBudgets(userID int) ([]mdlFeatureOne.BudgetResponse, error) {
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(json_agg(x ORDER BY x.id), '[]')::text
+		FROM (`+budgetJSONSelect+` WHERE b.user_id = ? AND b.deleted_at IS NULL) x
+	`, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetBudgets] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	budgets := []mdlFeatureOne.BudgetResponse{}
+	if err := json.Unmarshal([]byte(jsonResult), &budgets); err != nil {
+		log.Printf("[GetBudgets] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetBudgets] Success - UserID: %d, Count: %d", userID, len(budgets))
+	return budgets, nil
+}
+
+// GetBudgetByID retrieves a single budget
+func GetBudgetByID(userID, budgetID int) (*mdlFeatureOne.BudgetResponse, error) {
+	var budget mdlFeatureOne.BudgetResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT row_to_json(x)::text
+		FROM (`+budgetJSONSelect+` WHERE b.id = ? AND b.user_id = ? AND b.deleted_at IS NULL) x
+	`, budgetID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetBudgetByID] Error for user %d, budget %d: %v", userID, budgetID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &budget); err != nil {
+		log.Printf("[GetBudgetByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+// UpdateBudget saves an edited budget; the caller passes the merged budget
+func UpdateBudget(userID, budgetID int, budget *mdlFeatureOne.BudgetResponse) (*mdlFeatureOne.BudgetResponse, error) {
+	thresholdsJSON, _ := json.Marshal(budget.Thresholds)
+
+	err := config.DBConnList[0].Exec(`
+		UPDATE budgets
+		SET name = ?, category_id = ?, amount = ?, period = ?, start_date = ?, end_date = ?, rollover = ?,
+			thresholds = ARRAY(SELECT jsonb_array_elements_text(?::jsonb)::int),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, budget.Name, budget.CategoryID, budget.Amount, budget.Period, budget.StartDate, budget.EndDate,
+		budget.Rollover, string(thresholdsJSON), budgetID, userID).Error
+
+	if err != nil {
+		log.Printf("[UpdateBudget] Error for user %d, budget %d: %v", userID, budgetID, err)
+		return nil, err
+	}
+
+	log.Printf("[UpdateBudget] Success - BudgetID: %d, UserID: %d", budgetID, userID)
+	return GetBudgetByID(userID, budgetID)
+}
+
+// DeleteBudget soft deletes a budget
+func DeleteBudget(userID, budgetID int) error {
+	err := config.DBConnList[0].Exec(`
+		UPDATE budgets SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, budgetID, userID).Error
+
+	if err != nil {
+		log.Printf("[DeleteBudget] Error for user %d, budget %d: %v", userID, budgetID, err)
+		return err
+	}
+
+	log.Printf("[DeleteBudget] Success - BudgetID: %d, UserID: %d", budgetID, userID)
+	return nil
+}
+
+// ============================================
+// BUDGET STATUS
+// ============================================
+
+// GetBudgetStatuses computes spent vs. remaining for every budget active on asOf
+func GetBudgetStatuses(userID int, asOf time.Time) (*mdlFeatureOne.BudgetStatusResponse, error) {
+	budgets, err := GetBudgets(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mdlFeatureOne.BudgetStatusResponse{
+		AsOf:    asOf.Format("2006-01-02"),
+		Budgets: []mdlFeatureOne.BudgetStatusItem{},
+	}
+
+	for i := range budgets {
+		status, ok, err := budgetStatus(userID, &budgets[i], asOf)
+		if err != nil {
+			log.Printf("[GetBudgetStatuses] Error for user %d, budget %d: %v", userID, budgets[i].ID, err)
+			return nil, err
+		}
+		if ok {
+			result.Budgets = append(result.Budgets, status)
+		}
+	}
+
+	log.Printf("[GetBudgetStatuses] Success - UserID: %d, AsOf: %s, Active: %d",
+		userID, result.AsOf, len(result.Budgets))
+	return result, nil
+}
+
+// budgetStatus computes the status of the budget period containing asOf.
+// ok is false when the budget is not active on that date.
+func budgetStatus(userID int, budget *mdlFeatureOne.BudgetResponse, asOf time.Time) (mdlFeatureOne.BudgetStatusItem, bool, error) {
+	start, end, ok := hlpFeatureOne.BudgetPeriod(budget, asOf)
+	if !ok {
+		return mdlFeatureOne.BudgetStatusItem{}, false, nil
+	}
+
+	spent, err := budgetSpent(userID, budget.CategoryID, start, end)
+	if err != nil {
+		return mdlFeatureOne.BudgetStatusItem{}, false, err
+	}
+
+	// Rollover carries only the previous period's balance, not the whole history
+	rollover := 0.0
+	if budget.Rollover {
+		if prevStart, prevEnd, ok := hlpFeatureOne.PreviousBudgetPeriod(budget, start); ok {
+			prevSpent, err := budgetSpent(userID, budget.CategoryID, prevStart, prevEnd)
+			if err != nil {
+				return mdlFeatureOne.BudgetStatusItem{}, false, err
+			}
+			rollover = budget.Amount - prevSpent
+		}
+	}
+
+	return hlpFeatureOne.BuildBudgetStatus(budget, start, end, spent, rollover), true, nil
+}
+
+// budgetSpent sums the user's expenses in [start, end], optionally for one category
+func budgetSpent(userID int, categoryID *int, start, end time.Time) (float64, error) {
+	var spent float64
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(SUM(amount), 0)::float8
+		FROM expenses
+		WHERE user_id = ? AND deleted_at IS NULL
+			AND date BETWEEN ? AND ?
+			AND (?::int IS NULL OR category_id = ?::int)
+	`, userID, start.Format("2006-01-02"), end.Format("2006-01-02"), categoryID, categoryID).Scan(&spent).Error
+
+	return spent, err
+}
+
+// ============================================
+// BUDGET ALERTS
+// ============================================
+
+// checkBudgetAlertsAsync runs the threshold check for a created or updated expense
+// in the background so the request is not held up by it
+func checkBudgetAlertsAsync(userID int, expense *mdlFeatureOne.ExpenseResponse) {
+	var categoryID *int
+	if expense.Category != nil {
+		id := expense.Category.ID
+		categoryID = &id
+	}
+	date := expense.Date
+	if len(date) > 10 {
+		date = date[:10]
+	}
+	go CheckBudgetAlerts(userID, categoryID, date)
+}
+
+// CheckBudgetAlerts raises an alert for every budget covering the given category
+// and date whose usage has newly crossed one of its thresholds. Each threshold
+// fires at most once per budget period.
+func CheckBudgetAlerts(userID int, categoryID *int, date string) {
+	asOf, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		log.Printf("[CheckBudgetAlerts] Invalid date %q for user %d", date, userID)
+		return
+	}
+
+	budgets, err := GetBudgets(userID)
+	if err != nil {
+		return
+	}
+
+	for i := range budgets {
+		budget := &budgets[i]
+		if budget.CategoryID != nil && (categoryID == nil || *budget.CategoryID != *categoryID) {
+			continue
+		}
+
+		status, ok, err := budgetStatus(userID, budget, asOf)
+		if err != nil {
+			log.Printf("[CheckBudgetAlerts] Error computing budget %d: %v", budget.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		// Record every crossed threshold, but only notify once for the highest new one
+		var highest int
+		for _, threshold := range hlpFeatureOne.CrossedThresholds(budget.Thresholds, status.PercentUsed) {
+			var alertIDs []int
+			err := config.DBConnList[0].Raw(`
+				INSERT INTO budget_alerts (budget_id, period_start, threshold, spent)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT (budget_id, period_start, threshold) DO NOTHING
+				RETURNING id
+			`, budget.ID, status.PeriodStart, threshold, status.Spent).Scan(&alertIDs).Error
+			if err != nil {
+				log.Printf("[CheckBudgetAlerts] Error recording alert for budget %d: %v", budget.ID, err)
+				continue
+			}
+			if len(alertIDs) > 0 {
+				highest = threshold
+			}
+		}
+
+		if highest > 0 {
+			sendBudgetAlert(userID, &mdlFeatureOne.BudgetAlertEvent{
+				BudgetID:    budget.ID,
+				BudgetName:  budget.Name,
+				Threshold:   highest,
+				PeriodStart: status.PeriodStart,
+				PeriodEnd:   status.PeriodEnd,
+				Limit:       status.Limit,
+				Spent:       status.Spent,
+			})
+		}
+	}
+}
+
+// sendBudgetAlert delivers a budget alert as an in-app notification and an email
+func sendBudgetAlert(userID int, event *mdlFeatureOne.BudgetAlertEvent) {
+	title := fmt.Sprintf("Budget \"%s\" reached %d%%", event.BudgetName, event.Threshold)
+	if event.Threshold >= 100 {
+		title = fmt.Sprintf("Budget \"%s\" exceeded", event.BudgetName)
+	}
+	message := fmt.Sprintf("You have spent %.2f of your %.2f budget for %s to %s.",
+		event.Spent, event.Limit, event.PeriodStart, event.PeriodEnd)
+
+	if _, err := CreateNotification(userID, "budget_alert", title, message, event); err != nil {
+		log.Printf("[sendBudgetAlert] Error creating notification for user %d: %v", userID, err)
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil || user.Email == "" {
+		return
+	}
+
+	// Budgets saved before names were checked may still hold line breaks, which
+	// must not reach the Subject header; everything in the body is escaped
+	subject := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, title)
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+		<h2>%s</h2>
+		<p>Hello %s,</p>
+		<p>%s</p>
+		<p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+	</body>
+	</html>
+	`, html.EscapeString(title), html.EscapeString(user.Name), html.EscapeString(message))
+
+	if err := utils.SendEmail(user.Email, subject, htmlBody); err != nil {
+		log.Printf("[sendBudgetAlert] Error sending email to user %d: %v", userID, err)
+		return
+	}
+
+	log.Printf("[sendBudgetAlert] Success - UserID: %d, BudgetID: %d, Threshold: %d",
+		userID, event.BudgetID, event.Threshold)
+}
@@ -0,0 +1,299 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ============================================
+// CATEGORIZATION RULE OPERATIONS
+// ============================================
+
+// CategorizationRuleExists checks if a rule exists for a user
+func CategorizationRuleExists(userID, ruleID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM categorization_rules WHERE id = $1 AND user_id = $2)`,
+		ruleID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[CategorizationRuleExists] Error checking rule %d for user %d: %v", ruleID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// RegexPatternValid checks that a pattern compiles as a PostgreSQL regular expression,
+// the dialect rules are matched with
+func RegexPatternValid(pattern string) bool {
+	var matched bool
+
+	err := config.DBConnList[0].Raw(`SELECT '' ~* $1`, pattern).Scan(&matched).Error
+	if err != nil {
+		log.Printf("[RegexPatternValid] Invalid pattern %q: %v", pattern, err)
+		return false
+	}
+
+	return true
+}
+
+// CreateCategorizationRule inserts a rule; the request is validated and defaulted
+func CreateCategorizationRule(userID int, req *mdlFeatureOne.CategorizationRuleRequest) (*mdlFeatureOne.CategorizationRuleResponse, error) {
+	tagsJSON, err := json.Marshal(req.SetTags)
+	if err != nil {
+		return nil, err
+	}
+
+	var ruleID int
+	err = config.DBConnList[0].Raw(`
+		INSERT INTO categorization_rules (user_id, name, priority, match_field, match_type, pattern,
+			min_amount, max_amount, set_category_id, set_tags, set_notes, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?::jsonb, ?, ?)
+		RETURNING id
+	`, userID, req.Name, *req.Priority, req.MatchField, req.MatchType, req.Pattern,
+		req.MinAmount, req.MaxAmount, req.SetCategoryID, string(tagsJSON), req.SetNotes, *req.IsActive,
+	).Scan(&ruleID).Error
+
+	if err != nil {
+		log.Printf("[CreateCategorizationRule] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[CreateCategorizationRule] Success - RuleID: %d, UserID: %d, Name: %s", ruleID, userID, req.Name)
+	return GetCategorizationRuleByID(userID, ruleID)
+}
+
+// GetCategorizationRules retrieves the rules of a user in the order they run
+func GetCategorizationRules(userID int) ([]mdlFeatureOne.CategorizationRuleResponse, error) {
+	rules := []mdlFeatureOne.CategorizationRuleResponse{}
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(jsonb_agg(categorization_rule_to_jsonb(r) ORDER BY r.priority, r.id), '[]'::jsonb)::text
+		FROM categorization_rules r
+		WHERE r.user_id = ?
+	`, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetCategorizationRules] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &rules); err != nil {
+		log.Printf("[GetCategorizationRules] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetCategorizationRules] Success - UserID: %d, Count: %d", userID, len(rules))
+	return rules, nil
+}
+
+// GetCategorizationRuleByID retrieves a single rule
+func GetCategorizationRuleByID(userID, ruleID int) (*mdlFeatureOne.CategorizationRuleResponse, error) {
+	var rule mdlFeatureOne.CategorizationRuleResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT categorization_rule_to_jsonb(r)::text
+		FROM categorization_rules r
+		WHERE r.id = ? AND r.user_id = ?
+	`, ruleID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetCategorizationRuleByID] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &rule); err != nil {
+		log.Printf("[GetCategorizationRuleByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// UpdateCategorizationRule replaces a rule; the request is validated and defaulted
+func UpdateCategorizationRule(userID, ruleID int, req *mdlFeatureOne.CategorizationRuleRequest) (*mdlFeatureOne.CategorizationRuleResponse, error) {
+	tagsJSON, err := json.Marshal(req.SetTags)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DBConnList[0].Exec(`
+		UPDATE categorization_rules SET
+			name = ?, priority = ?, match_field = ?, match_type = ?, pattern = ?,
+			min_amount = ?, max_amount = ?, set_category_id = ?, set_tags = ?::jsonb,
+			set_notes = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?
+	`, req.Name, *req.Priority, req.MatchField, req.MatchType, req.Pattern,
+		req.MinAmount, req.MaxAmount, req.SetCategoryID, string(tagsJSON), req.SetNotes, *req.IsActive,
+		ruleID, userID,
+	).Error
+
+	if err != nil {
+		log.Printf("[UpdateCategorizationRule] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return nil, err
+	}
+
+	log.Printf("[UpdateCategorizationRule] Success - RuleID: %d, UserID: %d", ruleID, userID)
+	return GetCategorizationRuleByID(userID, ruleID)
+}
+
+// DeleteCategorizationRule deletes a rule; expenses it already changed keep their values
+func DeleteCategorizationRule(userID, ruleID int) error {
+	err := config.DBConnList[0].Exec(
+		`DELETE FROM categorization_rules WHERE id = ? AND user_id = ?`,
+		ruleID,
+		userID,
+	).Error
+
+	if err != nil {
+		log.Printf("[DeleteCategorizationRule] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return err
+	}
+
+	log.Printf("[DeleteCategorizationRule] Success - RuleID: %d, UserID: %d", ruleID, userID)
+	return nil
+}
+
+// applyCategorizationRules runs the user's rules over an expense inside the
+// caller's database transaction
+func applyCategorizationRules(tx *gorm.DB, userID, expenseID int, overwrite bool) (*mdlFeatureOne.RuleApplicationResult, error) {
+	var result mdlFeatureOne.RuleApplicationResult
+	var jsonResult string
+
+	err := tx.Raw(
+		`SELECT apply_categorization_rules($1, $2, $3)::text`,
+		userID,
+		expenseID,
+		overwrite,
+	).Scan(&jsonResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApplyCategorizationRules runs the user's rules over an existing expense and
+// records the change in its history
+func ApplyCategorizationRules(userID, expenseID int, overwrite bool, source mdlFeatureOne.RevisionSource) (*mdlFeatureOne.RuleApplicationResult, error) {
+	var result *mdlFeatureOne.RuleApplicationResult
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = applyCategorizationRules(tx, userID, expenseID, overwrite)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("[ApplyCategorizationRules] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+	if !result.Changed {
+		return result, nil
+	}
+
+	recordExpenseRevision(userID, expenseID, source)
+
+	// A new category can take a budget over its thresholds
+	if expense, err := GetExpenseByID(userID, expenseID); err == nil {
+		checkBudgetAlertsAsync(userID, expense)
+		emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseUpdated, expense)
+	}
+
+	log.Printf("[ApplyCategorizationRules] Success - ExpenseID: %d, UserID: %d, MatchedRules: %d",
+		expenseID, userID, result.MatchedRules)
+	return result, nil
+}
+
+// GetRuleApplicationTargets lists the IDs of the user's expenses a rules job runs
+// over, leaving out those locked by a reimbursement report
+func GetRuleApplicationTargets(userID int, req *mdlFeatureOne.ApplyRulesRequest) ([]int, error) {
+	ids := []int{}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT id FROM transactions
+		WHERE user_id = ? AND type = 'expense' AND deleted_at IS NULL AND NOT expense_locked(id)
+		  AND (?::date IS NULL OR date >= ?::date)
+		  AND (?::date IS NULL OR date <= ?::date)
+		ORDER BY id
+	`, userID, req.StartDate, req.StartDate, req.EndDate, req.EndDate).Scan(&ids).Error
+
+	if err != nil {
+		log.Printf("[GetRuleApplicationTargets] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// ============================================
+// RULES JOB PROCESSING
+// ============================================
+
+// ProcessRuleApplication re-runs the categorization rules over existing expenses
+// asynchronously. Results list only the expenses that changed or failed.
+func ProcessRuleApplication(jobID, userID int, expenseIDs []int, overwrite bool) {
+	log.Printf("[ProcessRuleApplication] Starting - JobID: %d, Items: %d", jobID, len(expenseIDs))
+
+	// Update status to processing
+	UpdateBatchJob(jobID, "processing", 0, 0, 0, nil)
+
+	results := []mdlFeatureOne.BatchUpdateResultItem{}
+	successCount := 0
+	failCount := 0
+	source := revisionSourceWithRef(mdlFeatureOne.RevisionSourceRules, jobID)
+
+	for i, expenseID := range expenseIDs {
+		// Add delay to prevent overwhelming database
+		time.Sleep(50 * time.Millisecond)
+
+		result, err := ApplyCategorizationRules(userID, expenseID, overwrite, source)
+		if err != nil {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: expenseID,
+				Success:   false,
+				Message:   "Failed to apply rules",
+			})
+			log.Printf("[ProcessRuleApplication] Failed - Item %d, ExpenseID: %d", i, expenseID)
+		} else {
+			successCount++
+			if result.Changed {
+				results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+					Index:     i,
+					ExpenseID: expenseID,
+					Success:   true,
+					Message:   "Updated by matching rules",
+				})
+			}
+		}
+
+		// Update progress
+		UpdateBatchJob(jobID, "processing", i+1, successCount, failCount, results)
+	}
+
+	// Mark as completed
+	finalStatus := "completed"
+	if len(expenseIDs) > 0 && failCount == len(expenseIDs) {
+		finalStatus = "failed"
+	}
+
+	UpdateBatchJob(jobID, finalStatus, len(expenseIDs), successCount, failCount, results)
+	log.Printf("[ProcessRuleApplication] Completed - JobID: %d, Success: %d, Failed: %d",
+		jobID, successCount, failCount)
+}
@@ -0,0 +1,105 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+)
+
+// ============================================
+// DUPLICATE DETECTION OPERATIONS
+// ============================================
+
+// FindDuplicateExpenses lists the user's expenses that look like a new one: the same
+// amount, dated within a few days and with a similar title. externalID is the bank's
+// ID of a statement line, so the line is not matched with its own earlier import.
+func FindDuplicateExpenses(userID int, req *mdlFeatureOne.CreateExpenseRequest, externalID *string) ([]mdlFeatureOne.DuplicateCandidate, error) {
+	candidates := []mdlFeatureOne.DuplicateCandidate{}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT id AS expense_id, title, amount, date::text AS date, similarity
+		FROM find_duplicate_expenses(?, ?::numeric, ?::date, ?, ?, ?, ?)
+	`, userID, req.Amount, req.Date, req.Title, externalID,
+		hlpFeatureOne.DefaultDuplicateWindowDays, hlpFeatureOne.DefaultDuplicateSimilarity,
+	).Scan(&candidates).Error
+
+	if err != nil {
+		log.Printf("[FindDuplicateExpenses] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if len(candidates) > 0 {
+		log.Printf("[FindDuplicateExpenses] UserID: %d, Title: %s, Candidates: %d", userID, req.Title, len(candidates))
+	}
+	return candidates, nil
+}
+
+// GetDuplicateClusters groups the user's expenses that look like the same spending
+func GetDuplicateClusters(userID int, filters *mdlFeatureOne.DuplicateFilters) (*mdlFeatureOne.DuplicateClustersResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.DuplicateClustersResponse{
+		Clusters:   []mdlFeatureOne.DuplicateCluster{},
+		WindowDays: filters.WindowDays,
+		Similarity: filters.Similarity,
+	}
+
+	var pairs []mdlFeatureOne.DuplicatePair
+	err := db.Raw(`
+		SELECT expense_id, duplicate_id, similarity
+		FROM duplicate_expense_pairs(?, ?::date, ?::date, ?, ?)
+	`, userID, filters.StartDate, filters.EndDate, filters.WindowDays, filters.Similarity).Scan(&pairs).Error
+	if err != nil {
+		log.Printf("[GetDuplicateClusters] Error finding pairs for user %d: %v", userID, err)
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return &result, nil
+	}
+
+	clusters, similarities := hlpFeatureOne.ClusterDuplicatePairs(pairs)
+
+	ids := []int{}
+	for _, cluster := range clusters {
+		ids = append(ids, cluster...)
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(jsonb_object_agg(t.id, transaction_to_jsonb(t)), '{}'::jsonb)::text
+		FROM transactions t
+		WHERE t.user_id = ? AND t.id IN (SELECT jsonb_array_elements_text(?::jsonb)::int)
+	`, userID, string(idsJSON)).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetDuplicateClusters] Error loading expenses for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	expenses := map[int]mdlFeatureOne.ExpenseResponse{}
+	if err := json.Unmarshal([]byte(jsonResult), &expenses); err != nil {
+		log.Printf("[GetDuplicateClusters] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	for i, cluster := range clusters {
+		item := mdlFeatureOne.DuplicateCluster{
+			Similarity: similarities[i],
+			Expenses:   make([]mdlFeatureOne.ExpenseResponse, 0, len(cluster)),
+		}
+		for _, id := range cluster {
+			if expense, ok := expenses[id]; ok {
+				item.Expenses = append(item.Expenses, expense)
+			}
+		}
+		result.Clusters = append(result.Clusters, item)
+	}
+
+	log.Printf("[GetDuplicateClusters] Success - UserID: %d, Pairs: %d, Clusters: %d",
+		userID, len(pairs), len(result.Clusters))
+	return &result, nil
+}
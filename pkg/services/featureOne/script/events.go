@@ -0,0 +1,111 @@
+package scpFeatureOne
+
+import (
+	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+)
+
+// ============================================
+// USER EVENTS
+// ============================================
+
+// emitUserEvent reports a change to the user's webhooks and to the clients
+// listening on their event stream
+func emitUserEvent(userID int, event string, data interface{}) {
+	EnqueueWebhookEvent(userID, event, data)
+	utils.PublishUserEvent(userID, event, data)
+}
+
+// emitExpenseUpdated reports an expense changed outside UpdateExpense with its
+// current data
+func emitExpenseUpdated(userID, expenseID int) {
+	expense, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
+		return
+	}
+
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseUpdated, expense)
+}
+
+// emitExpensesUpdated reports expenses changed together, such as those carrying
+// a renamed tag; callers run it in the background since there can be many
+func emitExpensesUpdated(userID int, expenseIDs []int) {
+	for _, expenseID := range expenseIDs {
+		emitExpenseUpdated(userID, expenseID)
+	}
+}
+
+// publishBatchJobEvent streams a batch job's progress; a finished job is also
+// reported with its results as batch_job.completed or batch_job.failed
+func publishBatchJobEvent(jobID int, status string) {
+	var job mdlFeatureOne.BatchJobEntity
+	err := config.DBConnList[0].Raw(`
+		SELECT id, user_id, job_type, status, total_items, processed_items, successful_items, failed_items
+		FROM batch_jobs
+		WHERE id = ?
+	`, jobID).Scan(&job).Error
+	if err != nil || job.UserID == 0 {
+		log.Printf("[publishBatchJobEvent] Error finding job %d: %v", jobID, err)
+		return
+	}
+
+	utils.PublishUserEvent(job.UserID, mdlFeatureOne.EventBatchJobProgress, mdlFeatureOne.BatchJobProgressEvent{
+		JobID:           job.ID,
+		JobType:         job.JobType,
+		Status:          job.Status,
+		TotalItems:      job.TotalItems,
+		ProcessedItems:  job.ProcessedItems,
+		SuccessfulItems: job.SuccessfulItems,
+		FailedItems:     job.FailedItems,
+	})
+
+	if status != "completed" && status != "failed" {
+		return
+	}
+
+	event := mdlFeatureOne.WebhookEventBatchJobCompleted
+	if status == "failed" {
+		event = mdlFeatureOne.WebhookEventBatchJobFailed
+	}
+
+	result, err := GetBatchJob(job.UserID, jobID)
+	if err != nil {
+		return
+	}
+	emitUserEvent(job.UserID, event, result)
+}
+
+// emitTransactionEvent reports an expense created or changed through the
+// transactions API with the same data as the expenses API
+func emitTransactionEvent(userID int, transaction *mdlFeatureOne.TransactionResponse, event string) {
+	if transaction.Type != "expense" {
+		return
+	}
+
+	expense, err := GetExpenseByID(userID, transaction.ID)
+	if err != nil {
+		return
+	}
+
+	emitUserEvent(userID, event, expense)
+}
+
+// emitTransactionDeletedEvent reports a transaction deleted through the
+// transactions API when it was an expense
+func emitTransactionDeletedEvent(userID, transactionID int) {
+	var transactionType string
+	err := config.DBConnList[0].Raw(
+		`SELECT type FROM transactions WHERE id = ? AND user_id = ?`, transactionID, userID,
+	).Scan(&transactionType).Error
+	if err != nil {
+		log.Printf("[emitTransactionDeletedEvent] Error for user %d, transaction %d: %v", userID, transactionID, err)
+		return
+	}
+	if transactionType != "expense" {
+		return
+	}
+
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseDeleted, mdlFeatureOne.WebhookExpenseDeletedData{ID: transactionID})
+}
@@ -0,0 +1,84 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+)
+
+// exportSortColumns maps sortBy to the ORDER BY expression of an export; $2 is the filters JSON
+var exportSortColumns = map[string]string{
+	"date":      "t.date",
+	"amount":    "t.amount",
+	"createdAt": "t.created_at",
+	"title":     "t.title",
+	"relevance": "ts_rank(t.search_vector, to_tsquery('simple', $2::jsonb->>'searchQuery'))",
+}
+
+// ============================================
+// EXPORT OPERATIONS
+// ============================================
+
+// StreamExpensesForExport calls fn for every expense matching the filters, in the
+// filters' sort order, reading rows from the database one at a time. Paging
+// (limit, offset, cursor) does not apply: an export covers every match.
+func StreamExpensesForExport(userID int, filters *mdlFeatureOne.ExpenseFilters, fn func(row *mdlFeatureOne.ExportExpenseRow) error) error {
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		log.Printf("[StreamExpensesForExport] Error marshaling filters: %v", err)
+		return err
+	}
+
+	sortColumn, ok := exportSortColumns[filters.SortBy]
+	if !ok {
+		sortColumn = exportSortColumns["date"]
+	}
+	direction := "DESC"
+	if filters.Order == "asc" {
+		direction = "ASC"
+	}
+
+	db := &config.DBConnList[0]
+	rows, err := db.Raw(`
+		SELECT t.id, t.title, t.amount, t.category_id, c.name AS category_name, m.name AS merchant,
+			to_char(t.date, 'YYYY-MM-DD') AS date, t.notes,
+			COALESCE((
+				SELECT string_agg(tg.name, ';' ORDER BY tg.name)
+				FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+				WHERE tt.transaction_id = t.id
+			), '') AS tags,
+			to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS') AS created_at
+		FROM filter_transactions($1, $2::jsonb || '{"type": "expense"}'::jsonb) t
+		LEFT JOIN expense_categories c ON c.id = t.category_id
+		LEFT JOIN merchants m ON m.id = t.merchant_id
+		ORDER BY `+sortColumn+` `+direction+`, t.id `+direction,
+		userID,
+		string(filtersJSON),
+	).Rows()
+	if err != nil {
+		log.Printf("[StreamExpensesForExport] Error for user %d: %v", userID, err)
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var row mdlFeatureOne.ExportExpenseRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			log.Printf("[StreamExpensesForExport] Error scanning row for user %d: %v", userID, err)
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[StreamExpensesForExport] Error reading rows for user %d: %v", userID, err)
+		return err
+	}
+
+	log.Printf("[StreamExpensesForExport] Success - UserID: %d, Count: %d", userID, count)
+	return nil
+}
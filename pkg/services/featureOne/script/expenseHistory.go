@@ -0,0 +1,197 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// ============================================
+// EXPENSE HISTORY OPERATIONS
+// ============================================
+
+// recordExpenseRevision stores the expense's current state as a revision when a
+// tracked field changed. The change itself is already saved, so failures are logged.
+func recordExpenseRevision(userID, expenseID int, source mdlFeatureOne.RevisionSource) {
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(
+		`SELECT COALESCE(record_expense_revision($1, $2, $3, $4)::text, '')`,
+		userID,
+		expenseID,
+		source.Type,
+		source.Ref,
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[recordExpenseRevision] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return
+	}
+	if jsonResult == "" {
+		return
+	}
+
+	log.Printf("[recordExpenseRevision] Success - ExpenseID: %d, UserID: %d, Source: %s",
+		expenseID, userID, source.Type)
+}
+
+// revisionSourceWithRef attributes a change to a batch job or recurring rule by ID
+func revisionSourceWithRef(sourceType string, id int) mdlFeatureOne.RevisionSource {
+	ref := strconv.Itoa(id)
+	return mdlFeatureOne.RevisionSource{Type: sourceType, Ref: &ref}
+}
+
+// ExpenseRevisionExists checks if an expense of the user has the revision
+func ExpenseRevisionExists(userID, expenseID, revision int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM expense_revisions r
+			JOIN transactions t ON t.id = r.transaction_id
+			WHERE r.transaction_id = $1 AND r.revision = $2 AND t.user_id = $3)`,
+		expenseID,
+		revision,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[ExpenseRevisionExists] Error checking revision %d of expense %d: %v", revision, expenseID, err)
+		return false
+	}
+
+	return exists
+}
+
+// GetExpenseHistory retrieves the revisions of an expense, newest first
+func GetExpenseHistory(userID, expenseID, limit, offset int) (*mdlFeatureOne.ExpenseHistoryResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.ExpenseHistoryResponse{
+		ExpenseID: expenseID,
+		Revisions: []mdlFeatureOne.ExpenseRevisionResponse{},
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	err := db.Raw(`
+		SELECT COUNT(*) FROM expense_revisions r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.transaction_id = ? AND t.user_id = ?
+	`, expenseID, userID).Scan(&result.Pagination.Total).Error
+	if err != nil {
+		log.Printf("[GetExpenseHistory] Error counting revisions of expense %d: %v", expenseID, err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(jsonb_agg(expense_revision_to_jsonb(x.r) ORDER BY (x.r).revision DESC), '[]'::jsonb)::text
+		FROM (
+			SELECT r
+			FROM expense_revisions r
+			JOIN transactions t ON t.id = r.transaction_id
+			WHERE r.transaction_id = ? AND t.user_id = ?
+			ORDER BY r.revision DESC
+			LIMIT ? OFFSET ?
+		) x
+	`, expenseID, userID, limit, offset).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetExpenseHistory] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Revisions); err != nil {
+		log.Printf("[GetExpenseHistory] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetExpenseHistory] Success - ExpenseID: %d, UserID: %d, Count: %d",
+		expenseID, userID, len(result.Revisions))
+	return &result, nil
+}
+
+// GetExpenseRevision retrieves a single revision of an expense
+func GetExpenseRevision(userID, expenseID, revision int) (*mdlFeatureOne.ExpenseRevisionResponse, error) {
+	var rev mdlFeatureOne.ExpenseRevisionResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT expense_revision_to_jsonb(r)::text
+		FROM expense_revisions r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.transaction_id = ? AND r.revision = ? AND t.user_id = ?
+	`, expenseID, revision, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetExpenseRevision] Error for expense %d, revision %d: %v", expenseID, revision, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &rev); err != nil {
+		log.Printf("[GetExpenseRevision] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+// RevertExpense restores the tracked fields of an expense to their state after
+// the revision. Unlike UpdateExpense it also clears fields that were empty then.
+// The revert is itself recorded as a new revision.
+func RevertExpense(userID, expenseID, revision int) (*mdlFeatureOne.ExpenseResponse, error) {
+	target, err := GetExpenseRevision(userID, expenseID, revision)
+	if err != nil {
+		return nil, err
+	}
+	state := target.Snapshot
+
+	// Fields and tags are reverted together, as UpdateExpense writes them
+	err = config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		// A category, merchant or account deleted since the revision is left empty
+		err := tx.Exec(`
+			UPDATE transactions SET
+				title       = ?,
+				amount      = ?,
+				category_id = (SELECT id FROM expense_categories WHERE id = ?),
+				merchant_id = (SELECT id FROM merchants WHERE id = ? AND user_id = ?),
+				account_id  = (SELECT id FROM accounts WHERE id = ? AND user_id = ? AND deleted_at IS NULL),
+				date        = ?::date,
+				notes       = ?,
+				image_url   = ?,
+				updated_at  = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ? AND type = 'expense' AND deleted_at IS NULL
+		`, state.Title, state.Amount, state.CategoryID, state.MerchantID, userID, state.AccountID, userID,
+			state.Date, state.Notes, state.ImageURL,
+			expenseID, userID).Error
+		if err != nil {
+			return err
+		}
+
+		return setTransactionTags(tx, userID, expenseID, state.Tags)
+	})
+	if err != nil {
+		log.Printf("[RevertExpense] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, asExpenseLocked(err)
+	}
+
+	ref := strconv.Itoa(revision)
+	recordExpenseRevision(userID, expenseID, mdlFeatureOne.RevisionSource{
+		Type: mdlFeatureOne.RevisionSourceRevert,
+		Ref:  &ref,
+	})
+
+	reverted, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[RevertExpense] Success - ExpenseID: %d, UserID: %d, Revision: %d", expenseID, userID, revision)
+	checkBudgetAlertsAsync(userID, reverted)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseUpdated, reverted)
+	return reverted, nil
+}
@@ -0,0 +1,198 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// ============================================
+// SAVED VIEW OPERATIONS
+// ============================================
+
+// ExpenseViewExists checks if a saved view exists for a user
+func ExpenseViewExists(userID, viewID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM expense_views WHERE id = $1 AND user_id = $2)`,
+		viewID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[ExpenseViewExists] Error checking view %d for user %d: %v", viewID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// ExpenseViewNameTaken checks if another view of the user already has the name, ignoring case
+func ExpenseViewNameTaken(userID int, name string, excludeViewID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM expense_views WHERE user_id = $1 AND lower(name) = lower($2) AND id <> $3)`,
+		userID,
+		name,
+		excludeViewID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[ExpenseViewNameTaken] Error checking view name %s for user %d: %v", name, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CountExpenseViews returns how many saved views a user has
+func CountExpenseViews(userID int) (int, error) {
+	var count int
+
+	err := config.DBConnList[0].Raw(
+		`SELECT COUNT(*) FROM expense_views WHERE user_id = ?`, userID,
+	).Scan(&count).Error
+
+	if err != nil {
+		log.Printf("[CountExpenseViews] Error for user %d: %v", userID, err)
+	}
+
+	return count, err
+}
+
+// CreateExpenseView inserts a saved view; a new default view replaces the old one
+func CreateExpenseView(userID int, req *mdlFeatureOne.ExpenseViewRequest) (*mdlFeatureOne.ExpenseViewResponse, error) {
+	filtersJSON, err := json.Marshal(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var viewID int
+	err = config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		if req.IsDefault {
+			if err := clearDefaultExpenseView(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Raw(`
+			INSERT INTO expense_views (user_id, name, filters, is_pinned, is_default)
+			VALUES (?, ?, ?::jsonb, ?, ?)
+			RETURNING id
+		`, userID, req.Name, string(filtersJSON), req.IsPinned, req.IsDefault).Scan(&viewID).Error
+	})
+
+	if err != nil {
+		log.Printf("[CreateExpenseView] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[CreateExpenseView] Success - ViewID: %d, UserID: %d, Name: %s", viewID, userID, req.Name)
+	return GetExpenseViewByID(userID, viewID)
+}
+
+// GetExpenseViews retrieves a user's saved views: the default first, then pinned, then by name
+func GetExpenseViews(userID int) ([]mdlFeatureOne.ExpenseViewResponse, error) {
+	views := []mdlFeatureOne.ExpenseViewResponse{}
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(jsonb_agg(expense_view_to_jsonb(v)
+			ORDER BY v.is_default DESC, v.is_pinned DESC, lower(v.name), v.id), '[]'::jsonb)::text
+		FROM expense_views v
+		WHERE v.user_id = ?
+	`, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetExpenseViews] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &views); err != nil {
+		log.Printf("[GetExpenseViews] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetExpenseViews] Success - UserID: %d, Count: %d", userID, len(views))
+	return views, nil
+}
+
+// GetExpenseViewByID retrieves a single saved view
+func GetExpenseViewByID(userID, viewID int) (*mdlFeatureOne.ExpenseViewResponse, error) {
+	var view mdlFeatureOne.ExpenseViewResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT expense_view_to_jsonb(v)::text
+		FROM expense_views v
+		WHERE v.id = ? AND v.user_id = ?
+	`, viewID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetExpenseViewByID] Error for user %d, view %d: %v", userID, viewID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &view); err != nil {
+		log.Printf("[GetExpenseViewByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &view, nil
+}
+
+// UpdateExpenseView replaces the name, filters and flags of a saved view
+func UpdateExpenseView(userID, viewID int, req *mdlFeatureOne.ExpenseViewRequest) (*mdlFeatureOne.ExpenseViewResponse, error) {
+	filtersJSON, err := json.Marshal(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		if req.IsDefault {
+			if err := clearDefaultExpenseView(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`
+			UPDATE expense_views
+			SET name = ?, filters = ?::jsonb, is_pinned = ?, is_default = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ?
+		`, req.Name, string(filtersJSON), req.IsPinned, req.IsDefault, viewID, userID).Error
+	})
+
+	if err != nil {
+		log.Printf("[UpdateExpenseView] Error for user %d, view %d: %v", userID, viewID, err)
+		return nil, err
+	}
+
+	log.Printf("[UpdateExpenseView] Success - ViewID: %d, UserID: %d, Name: %s", viewID, userID, req.Name)
+	return GetExpenseViewByID(userID, viewID)
+}
+
+// DeleteExpenseView deletes a saved view
+func DeleteExpenseView(userID, viewID int) error {
+	err := config.DBConnList[0].Exec(`DELETE FROM expense_views WHERE id = ? AND user_id = ?`, viewID, userID).Error
+
+	if err != nil {
+		log.Printf("[DeleteExpenseView] Error for user %d, view %d: %v", userID, viewID, err)
+		return err
+	}
+
+	log.Printf("[DeleteExpenseView] Success - ViewID: %d, UserID: %d", viewID, userID)
+	return nil
+}
+
+// clearDefaultExpenseView unsets the user's current default view
+func clearDefaultExpenseView(tx *gorm.DB, userID int) error {
+	return tx.Exec(`
+		UPDATE expense_views SET is_default = FALSE, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND is_default
+	`, userID).Error
+}
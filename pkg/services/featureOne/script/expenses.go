@@ -3,9 +3,12 @@ package scpFeatureOne
 import (
 	"encoding/json"
 	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
 	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
 
 	"log"
+
+	"gorm.io/gorm"
 )
 
 // ============================================
@@ -13,11 +16,29 @@ import (
 // ============================================
 
 // CreateExpense inserts a new expense into the database
-func CreateExpense(userID int, req *mdlFeatureOne.CreateExpenseRequest) (*mdlFeatureOne.ExpenseResponse, error) {
-	var expense mdlFeatureOne.ExpenseResponse
+func CreateExpense(userID int, req *mdlFeatureOne.CreateExpenseRequest, source mdlFeatureOne.RevisionSource) (*mdlFeatureOne.ExpenseResponse, error) {
+	var expenseID int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var err error
+		expenseID, err = insertExpense(tx, userID, req)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("[CreateExpense] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	return finishNewExpense(userID, expenseID, req, source)
+}
+
+// insertExpense creates the expense row and its tags inside the caller's
+// database transaction, so callers can save whatever links to it atomically
+func insertExpense(tx *gorm.DB, userID int, req *mdlFeatureOne.CreateExpenseRequest) (int, error) {
 	var jsonResult string
 
-	err := config.DBConnList[0].Debug().Raw(
+	err := tx.Raw(
 		`SELECT * FROM create_expense($1, $2, $3, $4, $5, $6, $7)`,
 		userID,
 		req.Title,
@@ -27,20 +48,76 @@ func CreateExpense(userID int, req *mdlFeatureOne.CreateExpenseRequest) (*mdlFea
 		req.Notes,
 		req.ImageURL,
 	).Scan(&jsonResult).Error
-
 	if err != nil {
-		log.Printf("[CreateExpense] Error for user %d: %v", userID, err)
-		return nil, err
+		return 0, err
 	}
 
-	if err := json.Unmarshal([]byte(jsonResult), &expense); err != nil {
-		log.Printf("[CreateExpense] JSON parse error: %v", err)
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(jsonResult), &created); err != nil {
+		return 0, err
+	}
+
+	if len(req.Tags) > 0 {
+		if err := setTransactionTags(tx, userID, created.ID, req.Tags); err != nil {
+			return 0, err
+		}
+	}
+	return created.ID, nil
+}
+
+// finishNewExpense completes an expense once its row is committed: merchant,
+// account and rules, its first revision, budget alerts and the created event
+func finishNewExpense(userID, expenseID int, req *mdlFeatureOne.CreateExpenseRequest, source mdlFeatureOne.RevisionSource) (*mdlFeatureOne.ExpenseResponse, error) {
+	completeNewExpense(userID, expenseID, req)
+
+	recordExpenseRevision(userID, expenseID, source)
+
+	// Re-read so the response carries tags like GetExpenseByID
+	created, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
 		return nil, err
 	}
 
 	log.Printf("[CreateExpense] Success - ExpenseID: %d, UserID: %d, Title: %s, Amount: %.2f",
-		expense.ID, userID, expense.Title, expense.Amount)
-	return &expense, nil
+		created.ID, userID, created.Title, created.Amount)
+	checkBudgetAlertsAsync(userID, created)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseCreated, created)
+	return created, nil
+}
+
+// completeNewExpense links a new expense to its merchant and account and fills it
+// in from the categorization rules. The expense is already saved, so failures are logged.
+func completeNewExpense(userID, expenseID int, req *mdlFeatureOne.CreateExpenseRequest) {
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		if req.Merchant != nil {
+			merchantID, err := resolveMerchantID(tx, userID, *req.Merchant)
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(`UPDATE transactions SET merchant_id = ? WHERE id = ? AND user_id = ?`,
+				merchantID, expenseID, userID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if req.AccountID != nil {
+			err := tx.Exec(`UPDATE transactions SET account_id = ? WHERE id = ? AND user_id = ?`,
+				*req.AccountID, expenseID, userID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := applyCategorizationRules(tx, userID, expenseID, false)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("[completeNewExpense] Error for user %d, expense %d: %v", userID, expenseID, err)
+	}
 }
 
 // GetExpenses retrieves expenses with filters
@@ -65,15 +142,21 @@ func GetExpenses(userID int, filters *mdlFeatureOne.ExpenseFilters) (*mdlFeature
 	}
 
 	// Parse JSON result
-	var result mdlFeatureOne.ExpenseListResponse
+	var result struct {
+		mdlFeatureOne.ExpenseListResponse
+		Cursors mdlFeatureOne.ListCursors `json:"cursors"`
+	}
 	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
 		log.Printf("[GetExpenses] Error parsing result: %v", err)
 		return nil, err
 	}
 
+	// Turn the page's boundary keys into opaque cursors
+	hlpFeatureOne.ApplyListCursors(filters, &result.Pagination, result.Cursors)
+
 	log.Printf("[GetExpenses] Success - UserID: %d, Count: %d, Total: %d",
 		userID, len(result.Expenses), result.Pagination.Total)
-	return &result, nil
+	return &result.ExpenseListResponse, nil
 }
 
 // ExpenseExists checks if an expense exists for a user
@@ -120,47 +203,136 @@ func GetExpenseByID(userID, expenseID int) (*mdlFeatureOne.ExpenseResponse, erro
 	return &expense, nil
 }
 
-// UpdateExpense updates an existing expense
-func UpdateExpense(userID, expenseID int, req *mdlFeatureOne.UpdateExpenseRequest) (*mdlFeatureOne.ExpenseResponse, error) {
-	var expense mdlFeatureOne.ExpenseResponse
-	var jsonResult string
-
-	err := config.DBConnList[0].Raw(
-		`SELECT * FROM update_expense($1, $2, $3, $4, $5, $6, $7, $8)`,
-		userID,
-		expenseID,
-		req.Title,
-		req.Amount,
-		req.CategoryID,
-		req.Date,
-		req.Notes,
-		req.ImageURL,
-	).Scan(&jsonResult).Error
+// UpdateExpense updates an existing expense and records the change in its history.
+// When expectedVersion is set and the expense has moved on, nothing is changed and
+// it returns nil without an error.
+func UpdateExpense(userID, expenseID int, req *mdlFeatureOne.UpdateExpenseRequest, expectedVersion *int, source mdlFeatureOne.RevisionSource) (*mdlFeatureOne.ExpenseResponse, error) {
+	patch := expensePatch(req)
+	versionMismatch := false
+
+	// The fields and tags change together; the row lock keeps the version
+	// from moving between the check and the update
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		if expectedVersion != nil {
+			var version int
+			err := tx.Raw(`SELECT version FROM transactions WHERE id = ? AND user_id = ? FOR UPDATE`,
+				expenseID, userID).Scan(&version).Error
+			if err != nil {
+				return err
+			}
+			if version != *expectedVersion {
+				versionMismatch = true
+				return nil
+			}
+		}
+
+		if req.Merchant != nil {
+			merchantID, err := resolveMerchantID(tx, userID, *req.Merchant)
+			if err != nil {
+				return err
+			}
+			patch["merchantId"] = merchantID
+		}
+
+		patchJSON, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(
+			`SELECT update_expense($1, $2, $3::jsonb)`,
+			userID,
+			expenseID,
+			string(patchJSON),
+		).Error
+		if err != nil {
+			return err
+		}
+
+		// nil keeps the current tags, an empty list clears them
+		if req.Tags != nil {
+			if err := setTransactionTags(tx, userID, expenseID, req.Tags); err != nil {
+				return err
+			}
+		}
+
+		if req.ApplyRules {
+			_, err := applyCategorizationRules(tx, userID, expenseID, false)
+			return err
+		}
+		return nil
+	})
 
 	if err != nil {
 		log.Printf("[UpdateExpense] Error for user %d, expense %d: %v", userID, expenseID, err)
 		return nil, err
 	}
+	if versionMismatch {
+		log.Printf("[UpdateExpense] Version mismatch - ExpenseID: %d, UserID: %d, Expected: %d",
+			expenseID, userID, *expectedVersion)
+		return nil, nil
+	}
 
-	if err := json.Unmarshal([]byte(jsonResult), &expense); err != nil {
-		log.Printf("[UpdateExpense] JSON parse error: %v", err)
+	recordExpenseRevision(userID, expenseID, source)
+
+	// Re-read so the response carries tags like GetExpenseByID
+	updated, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
 		return nil, err
 	}
 
 	log.Printf("[UpdateExpense] Success - ExpenseID: %d, UserID: %d, Title: %s",
-		expense.ID, userID, expense.Title)
-	return &expense, nil
+		updated.ID, userID, updated.Title)
+	checkBudgetAlertsAsync(userID, updated)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseUpdated, updated)
+	return updated, nil
+}
+
+// expensePatch is the merge patch update_expense applies: set fields and the
+// fields in Clear as null. A merchant is set by UpdateExpense once its ID is known.
+func expensePatch(req *mdlFeatureOne.UpdateExpenseRequest) map[string]interface{} {
+	patch := map[string]interface{}{}
+	if req.Title != nil {
+		patch["title"] = *req.Title
+	}
+	if req.Amount != nil {
+		patch["amount"] = *req.Amount
+	}
+	if req.CategoryID != nil {
+		patch["categoryId"] = *req.CategoryID
+	}
+	if req.AccountID != nil {
+		patch["accountId"] = *req.AccountID
+	}
+	if req.Date != nil {
+		patch["date"] = *req.Date
+	}
+	if req.Notes != nil {
+		patch["notes"] = *req.Notes
+	}
+	if req.ImageURL != nil {
+		patch["imageUrl"] = *req.ImageURL
+	}
+	for _, field := range req.Clear {
+		if field == "merchant" {
+			field = "merchantId"
+		}
+		patch[field] = nil
+	}
+	return patch
 }
 
-// DeleteExpense soft deletes an expense
-func DeleteExpense(userID, expenseID int) (*mdlFeatureOne.DeleteExpenseResult, error) {
+// DeleteExpense soft deletes an expense; with expectedVersion set it only does so
+// while the expense is still at that version
+func DeleteExpense(userID, expenseID int, expectedVersion *int) (*mdlFeatureOne.DeleteExpenseResult, error) {
 	var result mdlFeatureOne.DeleteExpenseResult
 	var jsonResult string
 
 	err := config.DBConnList[0].Debug().Raw(
-		`SELECT delete_expense($1, $2)`,
+		`SELECT delete_expense($1, $2, $3)`,
 		userID,
 		expenseID,
+		expectedVersion,
 	).Scan(&jsonResult).Error
 
 	if err != nil {
@@ -173,6 +345,10 @@ func DeleteExpense(userID, expenseID int) (*mdlFeatureOne.DeleteExpenseResult, e
 		return nil, err
 	}
 
+	if result.VersionMismatch {
+		log.Printf("[DeleteExpense] Version mismatch - UserID: %d, ExpenseID: %d", userID, expenseID)
+		return &result, nil
+	}
 	if !result.IsDeleted {
 		log.Printf("[DeleteExpense] Expense not found - UserID: %d, ExpenseID: %d", userID, expenseID)
 		return &result, nil
@@ -180,5 +356,6 @@ func DeleteExpense(userID, expenseID int) (*mdlFeatureOne.DeleteExpenseResult, e
 
 	log.Printf("[DeleteExpense] Success - ExpenseID: %d, UserID: %d, Had Image: %v",
 		expenseID, userID, result.ImageURL != nil)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseDeleted, mdlFeatureOne.WebhookExpenseDeletedData{ID: expenseID})
 	return &result, nil
 }
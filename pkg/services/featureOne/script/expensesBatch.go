@@ -85,6 +85,8 @@ func UpdateBatchJob(jobID int, status string, processed, successful, failed int,
 
 	log.Printf("[UpdateBatchJob] JobID: %d, Status: %s, Processed: %d/%d, Success: %d, Failed: %d",
 		jobID, status, processed, processed, successful, failed)
+
+	publishBatchJobEvent(jobID, status)
 	return nil
 }
 
@@ -134,13 +136,29 @@ func ProcessBatchUpdate(jobID, userID int, updates []mdlFeatureOne.BatchUpdateIt
 		// Add delay to prevent overwhelming database
 		time.Sleep(30 * time.Second)
 
-		// Build update request
+		// Build update request; rules fill in what the update leaves empty
 		req := &mdlFeatureOne.UpdateExpenseRequest{
 			Title:      update.Title,
 			Amount:     update.Amount,
 			CategoryID: update.CategoryID,
+			Merchant:   update.Merchant,
+			AccountID:  update.AccountID,
 			Date:       update.Date,
 			Notes:      update.Notes,
+			Tags:       update.Tags,
+			Clear:      update.Clear,
+			ApplyRules: true,
+		}
+
+		if err := hlpFeatureOne.ValidateBatchUpdateItem(&update); err != nil {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Success:   false,
+				Message:   err.Error(),
+			})
+			continue
 		}
 
 		if !ExpenseExists(userID, update.ExpenseID) {
@@ -154,10 +172,42 @@ func ProcessBatchUpdate(jobID, userID int, updates []mdlFeatureOne.BatchUpdateIt
 			continue
 		}
 
+		if ExpenseLocked(update.ExpenseID) {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Success:   false,
+				Message:   "Expense is locked by a submitted reimbursement report",
+			})
+			continue
+		}
+
+		if update.AccountID != nil && !AccountExists(userID, *update.AccountID) {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Success:   false,
+				Message:   "Account not found",
+			})
+			continue
+		}
+
 		// Attempt update
-		_, err := UpdateExpense(userID, update.ExpenseID, req)
+		updated, err := UpdateExpense(userID, update.ExpenseID, req, update.Version,
+			revisionSourceWithRef(mdlFeatureOne.RevisionSourceBatch, jobID))
 
-		if err != nil {
+		if err == nil && updated == nil {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:     i,
+				ExpenseID: update.ExpenseID,
+				Success:   false,
+				Message:   "Expense was modified since the expected version",
+			})
+			log.Printf("[ProcessBatchUpdate] Version mismatch - Item %d, ExpenseID: %d", i, update.ExpenseID)
+		} else if err != nil {
 			failCount++
 			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
 				Index:     i,
@@ -186,8 +236,10 @@ func ProcessBatchUpdate(jobID, userID int, updates []mdlFeatureOne.BatchUpdateIt
 		jobID, successCount, failCount)
 }
 
-// ProcessBatchUpload processes batch expense uploads from CSV asynchronously
-func ProcessBatchUpload(jobID, userID int, expenses []mdlFeatureOne.CSVExpenseRow) {
+// ProcessBatchUpload processes batch expense uploads from CSV asynchronously.
+// Rows that look like existing expenses (or earlier rows) are created and marked
+// as duplicates in the results; force skips the check.
+func ProcessBatchUpload(jobID, userID int, expenses []mdlFeatureOne.CSVExpenseRow, force bool) {
 	log.Printf("[ProcessBatchUpload] Starting - JobID: %d, Items: %d", jobID, len(expenses))
 
 	// Update status to processing
@@ -206,8 +258,11 @@ func ProcessBatchUpload(jobID, userID int, expenses []mdlFeatureOne.CSVExpenseRo
 			Title:      expense.Title,
 			Amount:     expense.Amount,
 			CategoryID: expense.CategoryID,
+			Merchant:   expense.Merchant,
+			AccountID:  expense.AccountID,
 			Date:       expense.Date,
 			Notes:      expense.Notes,
+			Tags:       expense.Tags,
 		}
 
 		// Validate before inserting
@@ -222,9 +277,50 @@ func ProcessBatchUpload(jobID, userID int, expenses []mdlFeatureOne.CSVExpenseRo
 			UpdateBatchJob(jobID, "processing", i+1, successCount, failCount, results)
 			continue
 		}
+		if expense.AccountID != nil && !AccountExists(userID, *expense.AccountID) {
+			failCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:   i,
+				Success: false,
+				Message: "Account not found",
+			})
+			UpdateBatchJob(jobID, "processing", i+1, successCount, failCount, results)
+			continue
+		}
+
+		// Rows that look like existing expenses (or earlier rows) are still created,
+		// since identical real purchases happen, and are flagged for review
+		var duplicateOf []int
+		if !force {
+			duplicates, err := FindDuplicateExpenses(userID, req, expense.ExternalID)
+			if err != nil {
+				log.Printf("[ProcessBatchUpload] Duplicate check failed - Item %d: %v", i, err)
+			}
+			for _, d := range duplicates {
+				duplicateOf = append(duplicateOf, d.ExpenseID)
+			}
+		}
 
-		// Attempt create
-		created, err := CreateExpense(userID, req)
+		// Attempt create; statement lines carry the bank's ID and are skipped when already imported
+		var created *mdlFeatureOne.ExpenseResponse
+		var err error
+		if expense.ExternalID != nil {
+			created, err = ImportExpense(userID, req, *expense.ExternalID,
+				revisionSourceWithRef(mdlFeatureOne.RevisionSourceImport, jobID))
+			if err == nil && created == nil {
+				successCount++
+				results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+					Index:   i,
+					Success: true,
+					Message: "Already imported, skipped",
+				})
+				log.Printf("[ProcessBatchUpload] Skipped - Item %d already imported", i)
+				UpdateBatchJob(jobID, "processing", i+1, successCount, failCount, results)
+				continue
+			}
+		} else {
+			created, err = CreateExpense(userID, req, revisionSourceWithRef(mdlFeatureOne.RevisionSourceBatch, jobID))
+		}
 
 		if err != nil {
 			failCount++
@@ -234,6 +330,18 @@ func ProcessBatchUpload(jobID, userID int, expenses []mdlFeatureOne.CSVExpenseRo
 				Message: "Failed to create expense",
 			})
 			log.Printf("[ProcessBatchUpload] Failed - Item %d", i)
+		} else if len(duplicateOf) > 0 {
+			successCount++
+			results = append(results, mdlFeatureOne.BatchUpdateResultItem{
+				Index:       i,
+				ExpenseID:   created.ID,
+				Success:     true,
+				Message:     fmt.Sprintf("Created; possible duplicate of expenses %v", duplicateOf),
+				Duplicate:   true,
+				DuplicateOf: duplicateOf,
+			})
+			log.Printf("[ProcessBatchUpload] Success - Item %d, ExpenseID: %d, looks like expenses %v",
+				i, created.ID, duplicateOf)
 		} else {
 			successCount++
 			log.Printf("[ProcessBatchUpload] Success - Item %d, ExpenseID: %d", i, created.ID)
@@ -19,17 +19,17 @@ func CreateCategory(req *mdlFeatureOne.CreateCategoryRequest) (*mdlFeatureOne.Ca
 
 	// Insert the new category and return it
 	err := db.Raw(`
-		INSERT INTO expense_categories (name, description)
-		VALUES (?, ?)
-		RETURNING id, name, description, created_at
-	`, req.Name, req.Description).Scan(&category).Error
+		INSERT INTO expense_categories (name, description, kind)
+		VALUES (?, ?, ?)
+		RETURNING id, name, description, kind, created_at
+	`, req.Name, req.Description, req.Kind).Scan(&category).Error
 
 	if err != nil {
 		log.Printf("[CreateCategory] Error creating category '%s': %v", req.Name, err)
 		return nil, err
 	}
 
-	log.Printf("[CreateCategory] Success - ID: %d, Name: %s", category.ID, category.Name)
+	log.Printf("[CreateCategory] Success - ID: %d, Name: %s, Kind: %s", category.ID, category.Name, category.Kind)
 	return &category, nil
 }
 
@@ -38,9 +38,10 @@ func GetCategories(filters *mdlFeatureOne.CategoryFilters) (*mdlFeatureOne.Categ
 	var resultJSON string
 
 	err := config.DBConnList[0].Raw(
-		`SELECT get_categories($1, $2)`,
+		`SELECT get_categories($1, $2, $3)`,
 		filters.Limit,
 		filters.Offset,
+		filters.Kind,
 	).Scan(&resultJSON).Error
 
 	if err != nil {
@@ -59,3 +60,20 @@ func GetCategories(filters *mdlFeatureOne.CategoryFilters) (*mdlFeatureOne.Categ
 		len(result.Categories), result.Pagination.Total)
 	return &result, nil
 }
+
+// ExpenseCategoryExists checks if a category exists
+func ExpenseCategoryExists(categoryID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM expense_categories WHERE id = $1)`,
+		categoryID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[ExpenseCategoryExists] Error checking category %d: %v", categoryID, err)
+		return false
+	}
+
+	return exists
+}
@@ -0,0 +1,197 @@
+package scpFeatureOne
+
+import (
+	"fmt"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// merchantColumns selects a merchant in the shape of MerchantResponse
+const merchantColumns = `
+	m.id, m.name, m.created_at, m.updated_at,
+	(SELECT COUNT(*) FROM transactions t
+		WHERE t.merchant_id = m.id AND t.deleted_at IS NULL) AS usage_count`
+
+// ============================================
+// MERCHANT OPERATIONS
+// ============================================
+
+// MerchantExists checks if a merchant exists for a user
+func MerchantExists(userID, merchantID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1 AND user_id = $2)`,
+		merchantID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[MerchantExists] Error checking merchant %d for user %d: %v", merchantID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// MerchantNameTaken checks if another merchant of the user already has the name, ignoring case
+func MerchantNameTaken(userID int, name string, excludeMerchantID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM merchants WHERE user_id = $1 AND lower(name) = lower($2) AND id <> $3)`,
+		userID,
+		name,
+		excludeMerchantID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[MerchantNameTaken] Error checking merchant name %s for user %d: %v", name, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateMerchant inserts a merchant, returning the existing one if the name is already used
+func CreateMerchant(userID int, name string) (*mdlFeatureOne.MerchantResponse, error) {
+	var merchantID int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var err error
+		merchantID, err = resolveMerchantID(tx, userID, name)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("[CreateMerchant] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[CreateMerchant] Success - MerchantID: %d, UserID: %d, Name: %s", merchantID, userID, name)
+	return GetMerchantByID(userID, merchantID)
+}
+
+// GetMerchants retrieves all merchants of a user with their usage counts
+func GetMerchants(userID int) ([]mdlFeatureOne.MerchantResponse, error) {
+	merchants := []mdlFeatureOne.MerchantResponse{}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT `+merchantColumns+`
+		FROM merchants m
+		WHERE m.user_id = ?
+		ORDER BY lower(m.name)
+	`, userID).Scan(&merchants).Error
+
+	if err != nil {
+		log.Printf("[GetMerchants] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[GetMerchants] Success - UserID: %d, Count: %d", userID, len(merchants))
+	return merchants, nil
+}
+
+// GetMerchantByID retrieves a single merchant
+func GetMerchantByID(userID, merchantID int) (*mdlFeatureOne.MerchantResponse, error) {
+	var merchant mdlFeatureOne.MerchantResponse
+
+	err := config.DBConnList[0].Raw(`
+		SELECT `+merchantColumns+`
+		FROM merchants m
+		WHERE m.id = ? AND m.user_id = ?
+	`, merchantID, userID).Scan(&merchant).Error
+
+	if err != nil {
+		log.Printf("[GetMerchantByID] Error for user %d, merchant %d: %v", userID, merchantID, err)
+		return nil, err
+	}
+
+	return &merchant, nil
+}
+
+// RenameMerchant changes a merchant's name; the caller checks the name is free.
+// The merchant's expenses are reported as updated.
+func RenameMerchant(userID, merchantID int, name string) (*mdlFeatureOne.MerchantResponse, error) {
+	var affected []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE merchants SET name = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ?
+		`, hlpFeatureOne.NormalizeMerchantName(name), merchantID, userID).Error
+		if err != nil {
+			return err
+		}
+
+		affected, err = merchantExpenseIDs(tx, userID, merchantID)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("[RenameMerchant] Error for user %d, merchant %d: %v", userID, merchantID, err)
+		return nil, err
+	}
+
+	log.Printf("[RenameMerchant] Success - MerchantID: %d, UserID: %d, Name: %s", merchantID, userID, name)
+	go emitExpensesUpdated(userID, affected)
+	return GetMerchantByID(userID, merchantID)
+}
+
+// DeleteMerchant deletes a merchant; its expenses are kept without a merchant
+func DeleteMerchant(userID, merchantID int) error {
+	var affected []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var err error
+		affected, err = merchantExpenseIDs(tx, userID, merchantID)
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`DELETE FROM merchants WHERE id = ? AND user_id = ?`, merchantID, userID).Error
+	})
+
+	if err != nil {
+		log.Printf("[DeleteMerchant] Error for user %d, merchant %d: %v", userID, merchantID, err)
+		return asExpenseLocked(err)
+	}
+
+	log.Printf("[DeleteMerchant] Success - MerchantID: %d, UserID: %d", merchantID, userID)
+	go emitExpensesUpdated(userID, affected)
+	return nil
+}
+
+// merchantExpenseIDs lists the user's live expenses booked at a merchant
+func merchantExpenseIDs(tx *gorm.DB, userID, merchantID int) ([]int, error) {
+	ids := []int{}
+	err := tx.Raw(`
+		SELECT id FROM transactions
+		WHERE merchant_id = ? AND user_id = ? AND type = 'expense' AND deleted_at IS NULL
+		ORDER BY id
+	`, merchantID, userID).Scan(&ids).Error
+	return ids, err
+}
+
+// resolveMerchantID returns the ID of the user's merchant with the name, creating
+// it on first use; names match regardless of case
+func resolveMerchantID(tx *gorm.DB, userID int, name string) (int, error) {
+	name = hlpFeatureOne.NormalizeMerchantName(name)
+	if name == "" {
+		return 0, fmt.Errorf("merchant name is required")
+	}
+
+	var merchantID int
+	err := tx.Raw(`
+		INSERT INTO merchants (user_id, name)
+		VALUES (?, ?)
+		ON CONFLICT (user_id, (lower(name))) DO UPDATE SET name = merchants.name
+		RETURNING id
+	`, userID, name).Scan(&merchantID).Error
+
+	return merchantID, err
+}
@@ -0,0 +1,139 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+)
+
+// ============================================
+// NOTIFICATION OPERATIONS
+// ============================================
+
+// NotificationExists checks if a notification exists for a user
+func NotificationExists(userID, notificationID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM notifications WHERE id = $1 AND user_id = $2)`,
+		notificationID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[NotificationExists] Error checking notification %d for user %d: %v", notificationID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateNotification stores an in-app notification; data is saved as JSON
+func CreateNotification(userID int, notificationType, title, message string, data interface{}) (int, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var notificationID int
+	err = config.DBConnList[0].Raw(`
+		INSERT INTO notifications (user_id, type, title, message, data)
+		VALUES (?, ?, ?, ?, ?::jsonb)
+		RETURNING id
+	`, userID, notificationType, title, message, string(dataJSON)).Scan(&notificationID).Error
+
+	if err != nil {
+		log.Printf("[CreateNotification] Error for user %d: %v", userID, err)
+		return 0, err
+	}
+
+	log.Printf("[CreateNotification] Success - NotificationID: %d, UserID: %d, Type: %s",
+		notificationID, userID, notificationType)
+	return notificationID, nil
+}
+
+// GetNotifications retrieves a user's notifications, newest first
+func GetNotifications(userID int, filters *mdlFeatureOne.NotificationFilters) (*mdlFeatureOne.NotificationListResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.NotificationListResponse{
+		Notifications: []mdlFeatureOne.NotificationResponse{},
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  filters.Limit,
+			Offset: filters.Offset,
+		},
+	}
+
+	var counts struct {
+		Total  int
+		Unread int
+	}
+	err := db.Raw(`
+		SELECT COUNT(*) FILTER (WHERE ?::boolean = FALSE OR read_at IS NULL) AS total,
+			COUNT(*) FILTER (WHERE read_at IS NULL) AS unread
+		FROM notifications
+		WHERE user_id = ?
+	`, filters.UnreadOnly, userID).Scan(&counts).Error
+	if err != nil {
+		log.Printf("[GetNotifications] Error counting notifications for user %d: %v", userID, err)
+		return nil, err
+	}
+	result.Pagination.Total = counts.Total
+	result.Unread = counts.Unread
+
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(json_agg(x ORDER BY x."createdAt" DESC, x.id DESC), '[]')::text
+		FROM (
+			SELECT id, type, title, message, data, read_at AS "readAt", created_at AS "createdAt"
+			FROM notifications
+			WHERE user_id = ? AND (?::boolean = FALSE OR read_at IS NULL)
+			ORDER BY created_at DESC, id DESC
+			LIMIT ? OFFSET ?
+		) x
+	`, userID, filters.UnreadOnly, filters.Limit, filters.Offset).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetNotifications] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Notifications); err != nil {
+		log.Printf("[GetNotifications] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetNotifications] Success - UserID: %d, Count: %d, Unread: %d",
+		userID, len(result.Notifications), result.Unread)
+	return &result, nil
+}
+
+// MarkNotificationRead marks a single notification as read
+func MarkNotificationRead(userID, notificationID int) error {
+	err := config.DBConnList[0].Exec(`
+		UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND read_at IS NULL
+	`, notificationID, userID).Error
+
+	if err != nil {
+		log.Printf("[MarkNotificationRead] Error for user %d, notification %d: %v", userID, notificationID, err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of a user as read
+func MarkAllNotificationsRead(userID int) (int64, error) {
+	result := config.DBConnList[0].Exec(`
+		UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND read_at IS NULL
+	`, userID)
+
+	if result.Error != nil {
+		log.Printf("[MarkAllNotificationsRead] Error for user %d: %v", userID, result.Error)
+		return 0, result.Error
+	}
+
+	log.Printf("[MarkAllNotificationsRead] Success - UserID: %d, Updated: %d", userID, result.RowsAffected)
+	return result.RowsAffected, nil
+}
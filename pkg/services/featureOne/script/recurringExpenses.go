@@ -0,0 +1,342 @@
+package scpFeatureOne
+
+import (
+	"errors"
+	"fmt"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxRecurringCatchUp caps how many missed occurrences a rule materialises per tick
+const maxRecurringCatchUp = 366
+
+// recurringRuleColumns selects a rule in the shape of RecurringExpenseResponse
+const recurringRuleColumns = `
+	id, title, amount, category_id, notes, frequency, interval_count, day_of_month, rrule,
+	TO_CHAR(start_date, 'YYYY-MM-DD') AS start_date,
+	TO_CHAR(end_date, 'YYYY-MM-DD') AS end_date,
+	TO_CHAR(next_run_date, 'YYYY-MM-DD') AS next_run_date,
+	TO_CHAR(last_run_date, 'YYYY-MM-DD') AS last_run_date,
+	is_paused, created_at, updated_at`
+
+// ============================================
+// RECURRING EXPENSE RULE OPERATIONS
+// ============================================
+
+// RecurringExpenseExists checks if a recurring expense rule exists for a user
+func RecurringExpenseExists(userID, ruleID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM recurring_expense_rules WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		ruleID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[RecurringExpenseExists] Error checking rule %d for user %d: %v", ruleID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateRecurringExpense inserts a new recurring expense rule
+func CreateRecurringExpense(userID int, req *mdlFeatureOne.CreateRecurringExpenseRequest, nextRunDate *string) (*mdlFeatureOne.RecurringExpenseResponse, error) {
+	var rule mdlFeatureOne.RecurringExpenseResponse
+
+	err := config.DBConnList[0].Raw(`
+		INSERT INTO recurring_expense_rules
+			(user_id, title, amount, category_id, notes, frequency, interval_count, day_of_month, rrule, start_date, end_date, next_run_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+recurringRuleColumns,
+		userID, req.Title, req.Amount, req.CategoryID, req.Notes, req.Frequency, req.Interval,
+		req.DayOfMonth, req.RRule, req.StartDate, req.EndDate, nextRunDate,
+	).Scan(&rule).Error
+
+	if err != nil {
+		log.Printf("[CreateRecurringExpense] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[CreateRecurringExpense] Success - RuleID: %d, UserID: %d, Frequency: %s",
+		rule.ID, userID, rule.Frequency)
+	return &rule, nil
+}
+
+// GetRecurringExpenses retrieves a user's recurring expense rules with pagination
+func GetRecurringExpenses(userID int, filters *mdlFeatureOne.RecurringExpenseFilters) (*mdlFeatureOne.RecurringExpenseListResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.RecurringExpenseListResponse{
+		Rules: []mdlFeatureOne.RecurringExpenseResponse{},
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  filters.Limit,
+			Offset: filters.Offset,
+		},
+	}
+
+	var total int64
+	err := db.Raw(`
+		SELECT COUNT(*) FROM recurring_expense_rules
+		WHERE user_id = ? AND deleted_at IS NULL AND (?::boolean IS NULL OR is_paused = ?::boolean)
+	`, userID, filters.IsPaused, filters.IsPaused).Scan(&total).Error
+	if err != nil {
+		log.Printf("[GetRecurringExpenses] Error counting rules for user %d: %v", userID, err)
+		return nil, err
+	}
+	result.Pagination.Total = int(total)
+
+	err = db.Raw(`
+		SELECT `+recurringRuleColumns+`
+		FROM recurring_expense_rules
+		WHERE user_id = ? AND deleted_at IS NULL AND (?::boolean IS NULL OR is_paused = ?::boolean)
+		ORDER BY next_run_date NULLS LAST, id
+		LIMIT ? OFFSET ?
+	`, userID, filters.IsPaused, filters.IsPaused, filters.Limit, filters.Offset).Scan(&result.Rules).Error
+	if err != nil {
+		log.Printf("[GetRecurringExpenses] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[GetRecurringExpenses] Success - UserID: %d, Count: %d, Total: %d",
+		userID, len(result.Rules), result.Pagination.Total)
+	return &result, nil
+}
+
+// GetRecurringExpenseByID retrieves a single recurring expense rule
+func GetRecurringExpenseByID(userID, ruleID int) (*mdlFeatureOne.RecurringExpenseResponse, error) {
+	var rule mdlFeatureOne.RecurringExpenseResponse
+
+	err := config.DBConnList[0].Raw(`
+		SELECT `+recurringRuleColumns+`
+		FROM recurring_expense_rules
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, ruleID, userID).Scan(&rule).Error
+
+	if err != nil {
+		log.Printf("[GetRecurringExpenseByID] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// UpdateRecurringExpense saves an edited rule; the caller passes the merged
+// rule and its recomputed next run date
+func UpdateRecurringExpense(userID, ruleID int, rule *mdlFeatureOne.RecurringExpenseResponse) (*mdlFeatureOne.RecurringExpenseResponse, error) {
+	var updated mdlFeatureOne.RecurringExpenseResponse
+
+	err := config.DBConnList[0].Raw(`
+		UPDATE recurring_expense_rules
+		SET title = ?, amount = ?, category_id = ?, notes = ?, frequency = ?, interval_count = ?,
+			day_of_month = ?, rrule = ?, start_date = ?, end_date = ?, next_run_date = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+		RETURNING `+recurringRuleColumns,
+		rule.Title, rule.Amount, rule.CategoryID, rule.Notes, rule.Frequency, rule.Interval,
+		rule.DayOfMonth, rule.RRule, rule.StartDate, rule.EndDate, rule.NextRunDate,
+		ruleID, userID,
+	).Scan(&updated).Error
+
+	if err != nil {
+		log.Printf("[UpdateRecurringExpense] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return nil, err
+	}
+
+	log.Printf("[UpdateRecurringExpense] Success - RuleID: %d, UserID: %d", ruleID, userID)
+	return &updated, nil
+}
+
+// SetRecurringExpensePaused pauses or resumes a rule
+func SetRecurringExpensePaused(userID, ruleID int, paused bool, nextRunDate *string) (*mdlFeatureOne.RecurringExpenseResponse, error) {
+	var updated mdlFeatureOne.RecurringExpenseResponse
+
+	err := config.DBConnList[0].Raw(`
+		UPDATE recurring_expense_rules
+		SET is_paused = ?, next_run_date = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+		RETURNING `+recurringRuleColumns,
+		paused, nextRunDate, ruleID, userID,
+	).Scan(&updated).Error
+
+	if err != nil {
+		log.Printf("[SetRecurringExpensePaused] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return nil, err
+	}
+
+	log.Printf("[SetRecurringExpensePaused] Success - RuleID: %d, Paused: %v", ruleID, paused)
+	return &updated, nil
+}
+
+// DeleteRecurringExpense soft deletes a rule; expenses it already created are kept
+func DeleteRecurringExpense(userID, ruleID int) error {
+	err := config.DBConnList[0].Exec(`
+		UPDATE recurring_expense_rules
+		SET deleted_at = CURRENT_TIMESTAMP, next_run_date = NULL
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, ruleID, userID).Error
+
+	if err != nil {
+		log.Printf("[DeleteRecurringExpense] Error for user %d, rule %d: %v", userID, ruleID, err)
+		return err
+	}
+
+	log.Printf("[DeleteRecurringExpense] Success - RuleID: %d, UserID: %d", ruleID, userID)
+	return nil
+}
+
+// ============================================
+// RECURRING EXPENSE SCHEDULER
+// ============================================
+
+// StartRecurringExpenseScheduler materialises due occurrences on a fixed interval
+func StartRecurringExpenseScheduler(interval time.Duration) {
+	log.Printf("[RecurringExpenseScheduler] Started - Interval: %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ProcessDueRecurringExpenses(time.Now())
+		<-ticker.C
+	}
+}
+
+// ProcessDueRecurringExpenses creates the expenses for every occurrence due on or before asOf
+func ProcessDueRecurringExpenses(asOf time.Time) {
+	today := asOf.Format("2006-01-02")
+
+	var rules []mdlFeatureOne.RecurringExpenseEntity
+	err := config.DBConnList[0].Raw(`
+		SELECT id, user_id, title, amount, category_id, notes, frequency, interval_count, day_of_month, rrule,
+			TO_CHAR(start_date, 'YYYY-MM-DD') AS start_date,
+			TO_CHAR(end_date, 'YYYY-MM-DD') AS end_date,
+			TO_CHAR(next_run_date, 'YYYY-MM-DD') AS next_run_date,
+			TO_CHAR(last_run_date, 'YYYY-MM-DD') AS last_run_date,
+			is_paused
+		FROM recurring_expense_rules
+		WHERE deleted_at IS NULL AND is_paused = FALSE AND next_run_date <= ?
+		ORDER BY next_run_date, id
+	`, today).Scan(&rules).Error
+
+	if err != nil {
+		log.Printf("[ProcessDueRecurringExpenses] Error loading due rules: %v", err)
+		return
+	}
+
+	for i := range rules {
+		if err := processRecurringRule(&rules[i], asOf); err != nil {
+			log.Printf("[ProcessDueRecurringExpenses] Rule %d failed: %v", rules[i].ID, err)
+		}
+	}
+
+	if len(rules) > 0 {
+		log.Printf("[ProcessDueRecurringExpenses] Completed - Rules: %d, AsOf: %s", len(rules), today)
+	}
+}
+
+func processRecurringRule(rule *mdlFeatureOne.RecurringExpenseEntity, asOf time.Time) error {
+	recurrence, err := hlpFeatureOne.BuildRecurrence(rule.Frequency, rule.Interval, rule.DayOfMonth,
+		rule.RRule, rule.StartDate, rule.EndDate)
+	if err != nil {
+		return err
+	}
+
+	from, err := time.Parse("2006-01-02", *rule.NextRunDate)
+	if err != nil {
+		return err
+	}
+	asOfDate, _ := time.Parse("2006-01-02", asOf.Format("2006-01-02"))
+
+	// Catch up on everything missed since the last run, one bounded batch per tick
+	occurrences := recurrence.Occurrences(from, asOfDate, maxRecurringCatchUp)
+	lastDone := rule.LastRunDate
+	for _, occurrence := range occurrences {
+		date := occurrence.Format("2006-01-02")
+		if err := materializeOccurrence(rule, date); err != nil {
+			log.Printf("[processRecurringRule] Error materialising rule %d on %s: %v", rule.ID, date, err)
+			// Stop here so the failed date is retried on the next tick
+			return errors.Join(err, saveRecurringProgress(rule.ID, lastDone, &date))
+		}
+		lastDone = &date
+	}
+
+	nextAfter := asOfDate
+	if len(occurrences) == maxRecurringCatchUp {
+		nextAfter = occurrences[len(occurrences)-1]
+	}
+
+	var nextRunDate *string
+	if next, ok := recurrence.NextAfter(nextAfter); ok {
+		nextStr := next.Format("2006-01-02")
+		nextRunDate = &nextStr
+	}
+	return saveRecurringProgress(rule.ID, lastDone, nextRunDate)
+}
+
+// materializeOccurrence claims the (rule, date) slot and creates its expense in
+// one database transaction, so a slot is never left claimed without its expense.
+// A slot that is already claimed was handled by an earlier run and is skipped.
+func materializeOccurrence(rule *mdlFeatureOne.RecurringExpenseEntity, date string) error {
+	req := &mdlFeatureOne.CreateExpenseRequest{
+		Title:      rule.Title,
+		Amount:     rule.Amount,
+		CategoryID: rule.CategoryID,
+		Date:       date,
+		Notes:      rule.Notes,
+	}
+
+	var expenseID int
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var occurrenceIDs []int
+		err := tx.Raw(`
+			INSERT INTO recurring_expense_occurrences (rule_id, occurrence_date)
+			VALUES (?, ?)
+			ON CONFLICT (rule_id, occurrence_date) DO NOTHING
+			RETURNING id
+		`, rule.ID, date).Scan(&occurrenceIDs).Error
+		if err != nil {
+			return fmt.Errorf("claim occurrence %s: %w", date, err)
+		}
+		if len(occurrenceIDs) == 0 {
+			return nil
+		}
+
+		expenseID, err = insertExpense(tx, rule.UserID, req)
+		if err != nil {
+			return fmt.Errorf("create expense for %s: %w", date, err)
+		}
+
+		return tx.Exec(`UPDATE recurring_expense_occurrences SET expense_id = ? WHERE id = ?`,
+			expenseID, occurrenceIDs[0]).Error
+	})
+	if err != nil {
+		return err
+	}
+	if expenseID == 0 {
+		log.Printf("[materializeOccurrence] Already materialised - RuleID: %d, Date: %s", rule.ID, date)
+		return nil
+	}
+
+	// The occurrence is saved with its expense, so what follows cannot repeat it
+	if _, err := finishNewExpense(rule.UserID, expenseID, req,
+		revisionSourceWithRef(mdlFeatureOne.RevisionSourceRecurring, rule.ID)); err != nil {
+		log.Printf("[materializeOccurrence] Error completing expense %d: %v", expenseID, err)
+	}
+
+	log.Printf("[materializeOccurrence] Success - RuleID: %d, Date: %s, ExpenseID: %d", rule.ID, date, expenseID)
+	return nil
+}
+
+func saveRecurringProgress(ruleID int, lastRunDate, nextRunDate *string) error {
+	return config.DBConnList[0].Exec(`
+		UPDATE recurring_expense_rules
+		SET last_run_date = ?, next_run_date = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, lastRunDate, nextRunDate, ruleID).Error
+}
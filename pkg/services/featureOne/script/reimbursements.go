@@ -0,0 +1,409 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrReimbursementStatusChanged means the report left the status the request was
+// checked against before the change could be written
+var ErrReimbursementStatusChanged = errors.New("reimbursement report status changed")
+
+// ErrExpenseLocked means the database refused to change an expense that a
+// submitted, approved or paid report has locked
+var ErrExpenseLocked = errors.New("expense is locked by a submitted reimbursement report")
+
+// expenseLockedSQLState is raised by the triggers that enforce expense locks
+const expenseLockedSQLState = "EXLCK"
+
+// errInvalidReimbursementExpenses rolls back a report whose expenses cannot be claimed
+var errInvalidReimbursementExpenses = errors.New("invalid reimbursement expenses")
+
+// reimbursementVisible restricts r to reports the user (bound twice) owns or has
+// been submitted to approve
+const reimbursementVisible = `(r.user_id = ? OR (r.approver_id = ? AND r.status <> 'draft'))`
+
+// ============================================
+// REIMBURSEMENT REPORT OPERATIONS
+// ============================================
+
+// GetReimbursementReportRole returns whether the user sees a report as its owner
+// or approver, or "" if the report does not exist for them. Approvers only see
+// reports once they are submitted.
+func GetReimbursementReportRole(userID, reportID int) string {
+	var role string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT CASE WHEN r.user_id = ? THEN 'owner' ELSE 'approver' END
+		FROM reimbursement_reports r
+		WHERE r.id = ? AND `+reimbursementVisible,
+		userID, reportID, userID, userID,
+	).Scan(&role).Error
+
+	if err != nil {
+		log.Printf("[GetReimbursementReportRole] Error checking report %d for user %d: %v", reportID, userID, err)
+		return ""
+	}
+
+	return role
+}
+
+// ExpenseLocked checks if an expense is claimed by a submitted, approved or paid report
+func ExpenseLocked(expenseID int) bool {
+	var locked bool
+
+	err := config.DBConnList[0].Raw(`SELECT expense_locked($1)`, expenseID).Scan(&locked).Error
+
+	if err != nil {
+		log.Printf("[ExpenseLocked] Error checking expense %d: %v", expenseID, err)
+		return false
+	}
+
+	return locked
+}
+
+// asExpenseLocked turns the lock triggers' exception into ErrExpenseLocked and
+// passes any other error through
+func asExpenseLocked(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == expenseLockedSQLState {
+		return ErrExpenseLocked
+	}
+	return err
+}
+
+// ResolveReimbursementApprover maps the approverId or approverEmail of a report to
+// a user ID; nil means the report has no approver yet
+func ResolveReimbursementApprover(userID int, approverID *int, approverEmail *string) (*int, error) {
+	if approverID == nil && approverEmail == nil {
+		return nil, nil
+	}
+
+	var id int
+	if approverID != nil {
+		if !UserExists(*approverID) {
+			return nil, fmt.Errorf("approver %d not found", *approverID)
+		}
+		id = *approverID
+	} else {
+		email := strings.TrimSpace(*approverEmail)
+		var ids []int
+		err := config.DBConnList[0].Raw(
+			`SELECT id FROM users WHERE email = $1 AND deleted_at IS NULL`,
+			email,
+		).Scan(&ids).Error
+		if err != nil {
+			log.Printf("[ResolveReimbursementApprover] Error looking up email %s: %v", email, err)
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no user with email %s", email)
+		}
+		id = ids[0]
+	}
+
+	if id == userID {
+		return nil, fmt.Errorf("you cannot approve your own report")
+	}
+	return &id, nil
+}
+
+// CreateReimbursementReport creates a draft report claiming the given expenses.
+// When some of them cannot be claimed nothing is created and their IDs are returned.
+func CreateReimbursementReport(userID int, req *mdlFeatureOne.ReimbursementReportRequest, approverID *int) (*mdlFeatureOne.ReimbursementReportDetailResponse, []int, error) {
+	var reportID int
+	var invalidIDs []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`
+			INSERT INTO reimbursement_reports (user_id, approver_id, title, description)
+			VALUES (?, ?, ?, ?)
+			RETURNING id
+		`, userID, approverID, req.Title, req.Description).Scan(&reportID).Error
+		if err != nil {
+			return err
+		}
+
+		if invalidIDs, err = setReimbursementExpenses(tx, userID, reportID, req.ExpenseIDs); err != nil {
+			return err
+		}
+
+		return recordReimbursementEvent(tx, reportID, userID, nil, mdlFeatureOne.ReimbursementDraft, nil)
+	})
+
+	if errors.Is(err, errInvalidReimbursementExpenses) {
+		return nil, invalidIDs, nil
+	}
+	if err != nil {
+		log.Printf("[CreateReimbursementReport] Error for user %d: %v", userID, err)
+		return nil, nil, err
+	}
+
+	log.Printf("[CreateReimbursementReport] Success - ReportID: %d, UserID: %d, Expenses: %d",
+		reportID, userID, len(req.ExpenseIDs))
+	report, err := GetReimbursementReportByID(userID, reportID)
+	return report, nil, err
+}
+
+// UpdateReimbursementReport replaces the title, description, approver and
+// expenses of a draft report. Invalid expense IDs are returned as on creation.
+func UpdateReimbursementReport(userID, reportID int, req *mdlFeatureOne.ReimbursementReportRequest, approverID *int) (*mdlFeatureOne.ReimbursementReportDetailResponse, []int, error) {
+	var invalidIDs []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			UPDATE reimbursement_reports
+			SET title = ?, description = ?, approver_id = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ? AND status = 'draft'
+		`, req.Title, req.Description, approverID, reportID, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReimbursementStatusChanged
+		}
+
+		var err error
+		invalidIDs, err = setReimbursementExpenses(tx, userID, reportID, req.ExpenseIDs)
+		return err
+	})
+
+	if errors.Is(err, errInvalidReimbursementExpenses) {
+		return nil, invalidIDs, nil
+	}
+	if err != nil {
+		log.Printf("[UpdateReimbursementReport] Error for user %d, report %d: %v", userID, reportID, err)
+		return nil, nil, err
+	}
+
+	log.Printf("[UpdateReimbursementReport] Success - ReportID: %d, UserID: %d", reportID, userID)
+	report, err := GetReimbursementReportByID(userID, reportID)
+	return report, nil, err
+}
+
+// GetReimbursementReports retrieves the reports a user owns or approves, most recently changed first
+func GetReimbursementReports(userID int, filters *mdlFeatureOne.ReimbursementReportFilters) ([]mdlFeatureOne.ReimbursementReportResponse, error) {
+	reports := []mdlFeatureOne.ReimbursementReportResponse{}
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(jsonb_agg(reimbursement_report_to_jsonb(r)
+			ORDER BY r.updated_at DESC, r.id DESC), '[]'::jsonb)::text
+		FROM reimbursement_reports r
+		WHERE CASE WHEN ? = 'approver'
+				THEN r.approver_id = ? AND r.status <> 'draft'
+				ELSE r.user_id = ? END
+		  AND (?::text IS NULL OR r.status = ?)
+	`, filters.Role, userID, userID, filters.Status, filters.Status).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetReimbursementReports] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &reports); err != nil {
+		log.Printf("[GetReimbursementReports] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetReimbursementReports] Success - UserID: %d, Role: %s, Count: %d", userID, filters.Role, len(reports))
+	return reports, nil
+}
+
+// GetReimbursementReportByID retrieves a report the user owns or approves, with
+// its expenses and status history
+func GetReimbursementReportByID(userID, reportID int) (*mdlFeatureOne.ReimbursementReportDetailResponse, error) {
+	var report mdlFeatureOne.ReimbursementReportDetailResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT reimbursement_report_detail_to_jsonb(r)::text
+		FROM reimbursement_reports r
+		WHERE r.id = ? AND `+reimbursementVisible,
+		reportID, userID, userID,
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetReimbursementReportByID] Error for user %d, report %d: %v", userID, reportID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &report); err != nil {
+		log.Printf("[GetReimbursementReportByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// TransitionReimbursementReport moves a report from one status to another, records
+// the change with its comment and notifies the other party. The caller checks the
+// move is allowed for the actor; a report no longer in from is left alone.
+func TransitionReimbursementReport(userID, reportID int, from, to string, comment *string) (*mdlFeatureOne.ReimbursementReportDetailResponse, error) {
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			UPDATE reimbursement_reports SET
+				status       = $1,
+				submitted_at = CASE WHEN $1 = 'submitted' THEN CURRENT_TIMESTAMP ELSE submitted_at END,
+				decided_at   = CASE WHEN $1 IN ('approved', 'rejected') THEN CURRENT_TIMESTAMP
+				                    WHEN $1 = 'draft' THEN NULL ELSE decided_at END,
+				paid_at      = CASE WHEN $1 = 'paid' THEN CURRENT_TIMESTAMP ELSE paid_at END,
+				updated_at   = CURRENT_TIMESTAMP
+			WHERE id = $2 AND status = $3
+		`, to, reportID, from)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReimbursementStatusChanged
+		}
+
+		return recordReimbursementEvent(tx, reportID, userID, &from, to, comment)
+	})
+
+	if err != nil {
+		log.Printf("[TransitionReimbursementReport] Error for user %d, report %d (%s -> %s): %v",
+			userID, reportID, from, to, err)
+		return nil, err
+	}
+
+	log.Printf("[TransitionReimbursementReport] Success - ReportID: %d, UserID: %d, %s -> %s", reportID, userID, from, to)
+
+	report, err := GetReimbursementReportByID(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	notifyReimbursementTransition(userID, report, from, comment)
+	return report, nil
+}
+
+// DeleteReimbursementReport deletes a draft or rejected report; its expenses are kept
+func DeleteReimbursementReport(userID, reportID int) error {
+	result := config.DBConnList[0].Exec(`
+		DELETE FROM reimbursement_reports
+		WHERE id = ? AND user_id = ? AND status IN ('draft', 'rejected')
+	`, reportID, userID)
+
+	if result.Error != nil {
+		log.Printf("[DeleteReimbursementReport] Error for user %d, report %d: %v", userID, reportID, result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReimbursementStatusChanged
+	}
+
+	log.Printf("[DeleteReimbursementReport] Success - ReportID: %d, UserID: %d", reportID, userID)
+	return nil
+}
+
+// setReimbursementExpenses replaces the expenses of a report. Expenses that are not
+// the user's, are deleted or belong to another report are returned, and the caller's
+// transaction is rolled back with errInvalidReimbursementExpenses.
+func setReimbursementExpenses(tx *gorm.DB, userID, reportID int, expenseIDs []int) ([]int, error) {
+	idsJSON, err := json.Marshal(expenseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidIDs := []int{}
+	err = tx.Raw(`
+		SELECT x.id
+		FROM (SELECT jsonb_array_elements_text(?::jsonb)::int AS id) x
+		WHERE NOT EXISTS (
+				SELECT 1 FROM transactions t
+				WHERE t.id = x.id AND t.user_id = ? AND t.type = 'expense' AND t.deleted_at IS NULL
+			)
+		   OR EXISTS (
+				SELECT 1 FROM reimbursement_report_expenses e
+				WHERE e.transaction_id = x.id AND e.report_id <> ?
+			)
+		ORDER BY x.id
+	`, string(idsJSON), userID, reportID).Scan(&invalidIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(invalidIDs) > 0 {
+		return invalidIDs, errInvalidReimbursementExpenses
+	}
+
+	if err := tx.Exec(`DELETE FROM reimbursement_report_expenses WHERE report_id = ?`, reportID).Error; err != nil {
+		return nil, err
+	}
+
+	return nil, tx.Exec(`
+		INSERT INTO reimbursement_report_expenses (report_id, transaction_id)
+		SELECT ?, jsonb_array_elements_text(?::jsonb)::int
+	`, reportID, string(idsJSON)).Error
+}
+
+// recordReimbursementEvent adds a status change to a report's history
+func recordReimbursementEvent(tx *gorm.DB, reportID, actorID int, from *string, to string, comment *string) error {
+	return tx.Exec(`
+		INSERT INTO reimbursement_report_events (report_id, actor_id, from_status, to_status, comment)
+		VALUES (?, ?, ?, ?, ?)
+	`, reportID, actorID, from, to, comment).Error
+}
+
+// notifyReimbursementTransition tells the other party of a report about a status
+// change: the approver about submissions and recalls, the owner about decisions
+// and payment. Reopening a rejected report is the owner's own business.
+func notifyReimbursementTransition(actorID int, report *mdlFeatureOne.ReimbursementReportDetailResponse, from string, comment *string) {
+	actorName := report.Owner.Name
+	if report.Approver != nil && report.Approver.ID == actorID {
+		actorName = report.Approver.Name
+	}
+
+	var recipientID int
+	var title, message string
+	switch report.Status {
+	case mdlFeatureOne.ReimbursementSubmitted:
+		if report.Approver == nil {
+			return
+		}
+		recipientID = report.Approver.ID
+		title = fmt.Sprintf("%s submitted a reimbursement report", actorName)
+		message = fmt.Sprintf("\"%s\" claims %.2f for %d expenses.", report.Title, report.Total, report.ExpenseCount)
+	case mdlFeatureOne.ReimbursementDraft:
+		if from != mdlFeatureOne.ReimbursementSubmitted || report.Approver == nil {
+			return
+		}
+		recipientID = report.Approver.ID
+		title = fmt.Sprintf("%s recalled a reimbursement report", actorName)
+		message = fmt.Sprintf("\"%s\" was moved back to draft.", report.Title)
+	case mdlFeatureOne.ReimbursementApproved:
+		recipientID = report.Owner.ID
+		title = "Reimbursement report approved"
+		message = fmt.Sprintf("%s approved \"%s\" (%.2f).", actorName, report.Title, report.Total)
+	case mdlFeatureOne.ReimbursementRejected:
+		recipientID = report.Owner.ID
+		title = "Reimbursement report rejected"
+		message = fmt.Sprintf("%s rejected \"%s\" (%.2f).", actorName, report.Title, report.Total)
+	case mdlFeatureOne.ReimbursementPaid:
+		recipientID = report.Owner.ID
+		title = "Reimbursement report paid"
+		message = fmt.Sprintf("%s marked \"%s\" (%.2f) as paid.", actorName, report.Title, report.Total)
+	default:
+		return
+	}
+	if comment != nil {
+		message += fmt.Sprintf(" Comment: %s", *comment)
+	}
+
+	data := map[string]interface{}{
+		"reportId":   report.ID,
+		"fromStatus": from,
+		"status":     report.Status,
+		"comment":    comment,
+	}
+	if _, err := CreateNotification(recipientID, "reimbursement", title, message, data); err != nil {
+		log.Printf("[notifyReimbursementTransition] Error notifying user %d: %v", recipientID, err)
+	}
+}
@@ -0,0 +1,110 @@
+package scpFeatureOne
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"time"
+)
+
+// ============================================
+// REPORT OPERATIONS
+// ============================================
+
+// runReport calls a report_* SQL function and decodes its JSON into out. Results
+// are cached under a key that includes the user's data fingerprint, so any change
+// to their transactions or tags makes earlier entries unreachable.
+func runReport(name string, userID int, filters *mdlFeatureOne.ExpenseFilters, out interface{}, query string, extraArgs ...interface{}) error {
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		log.Printf("[%s] Error marshaling filters: %v", name, err)
+		return err
+	}
+
+	var fingerprint string
+	if err := config.DBConnList[0].Raw(`SELECT report_fingerprint($1)`, userID).Scan(&fingerprint).Error; err != nil {
+		log.Printf("[%s] Error reading fingerprint for user %d: %v", name, userID, err)
+		return err
+	}
+
+	extraJSON, _ := json.Marshal(extraArgs)
+	hash := sha256.Sum256([]byte(fingerprint + "|" + string(filtersJSON) + "|" + string(extraJSON)))
+	cacheKey := fmt.Sprintf("reports:%d:%s:%x", userID, name, hash)
+
+	resultJSON, cached := utils.CacheGet(cacheKey)
+	if !cached {
+		args := append([]interface{}{userID, string(filtersJSON)}, extraArgs...)
+		if err := config.DBConnList[0].Raw(query, args...).Scan(&resultJSON).Error; err != nil {
+			log.Printf("[%s] Error for user %d: %v", name, userID, err)
+			return err
+		}
+		utils.CacheSet(cacheKey, resultJSON, utils.GetEnvDuration("REPORT_CACHE_TTL", 10*time.Minute))
+	}
+
+	if err := json.Unmarshal([]byte(resultJSON), out); err != nil {
+		log.Printf("[%s] Error parsing result: %v", name, err)
+		return err
+	}
+
+	log.Printf("[%s] Success - UserID: %d, Cached: %t", name, userID, cached)
+	return nil
+}
+
+// GetReportSummary returns totals, averages and the comparison with the previous period
+func GetReportSummary(userID int, filters *mdlFeatureOne.ExpenseFilters) (*mdlFeatureOne.ReportSummaryResponse, error) {
+	var result mdlFeatureOne.ReportSummaryResponse
+	err := runReport("GetReportSummary", userID, filters, &result,
+		`SELECT report_summary($1, $2::jsonb)::text`)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetCategoryReport returns spending totals per category
+func GetCategoryReport(userID int, filters *mdlFeatureOne.ExpenseFilters, period *mdlFeatureOne.ReportPeriod) (*mdlFeatureOne.CategoryReportResponse, error) {
+	result := mdlFeatureOne.CategoryReportResponse{Period: *period}
+	err := runReport("GetCategoryReport", userID, filters, &result.Categories,
+		`SELECT report_by_category($1, $2::jsonb)::text`)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTimeReport returns spending totals per day, week, month or year
+func GetTimeReport(userID int, filters *mdlFeatureOne.ExpenseFilters, period *mdlFeatureOne.ReportPeriod, interval string) (*mdlFeatureOne.TimeReportResponse, error) {
+	result := mdlFeatureOne.TimeReportResponse{Period: *period, Interval: interval}
+	err := runReport("GetTimeReport", userID, filters, &result.Series,
+		`SELECT report_over_time($1, $2::jsonb, $3)::text`, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTagReport returns spending totals per tag
+func GetTagReport(userID int, filters *mdlFeatureOne.ExpenseFilters, period *mdlFeatureOne.ReportPeriod) (*mdlFeatureOne.TagReportResponse, error) {
+	result := mdlFeatureOne.TagReportResponse{Period: *period}
+	err := runReport("GetTagReport", userID, filters, &result.Tags,
+		`SELECT report_by_tag($1, $2::jsonb)::text`)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTitleReport returns the titles the user spends the most on
+func GetTitleReport(userID int, filters *mdlFeatureOne.ExpenseFilters, period *mdlFeatureOne.ReportPeriod, limit int) (*mdlFeatureOne.TitleReportResponse, error) {
+	result := mdlFeatureOne.TitleReportResponse{Period: *period}
+	err := runReport("GetTitleReport", userID, filters, &result.Titles,
+		`SELECT report_top_titles($1, $2::jsonb, $3)::text`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,472 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"fmt"
+	"go_template_v3/pkg/config"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// settlementColumns selects a settlement in the shape of SettlementResponse
+const settlementColumns = `
+	id, payer_id AS "payerId", payee_id AS "payeeId", amount, date::text AS date, notes,
+	created_by AS "createdBy", created_at AS "createdAt"`
+
+// ============================================
+// SPLIT OPERATIONS
+// ============================================
+
+// ResolveSplitUserIDs maps each participant's userId or email to a user ID
+func ResolveSplitUserIDs(participants []mdlFeatureOne.SplitParticipantRequest) ([]int, error) {
+	userIDs := make([]int, len(participants))
+	for i, p := range participants {
+		if p.UserID != nil {
+			if !UserExists(*p.UserID) {
+				return nil, fmt.Errorf("participant %d: user %d not found", i+1, *p.UserID)
+			}
+			userIDs[i] = *p.UserID
+			continue
+		}
+
+		email := strings.TrimSpace(*p.Email)
+		var ids []int
+		err := config.DBConnList[0].Raw(
+			`SELECT id FROM users WHERE email = $1 AND deleted_at IS NULL`,
+			email,
+		).Scan(&ids).Error
+		if err != nil {
+			log.Printf("[ResolveSplitUserIDs] Error looking up email %s: %v", email, err)
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("participant %d: no user with email %s", i+1, email)
+		}
+		userIDs[i] = ids[0]
+	}
+	return userIDs, nil
+}
+
+// SetExpenseSplit creates or replaces the split of an expense and notifies the
+// participants who now owe a share of it
+func SetExpenseSplit(userID, expenseID int, method string, userIDs []int, weights []float64) (*mdlFeatureOne.SplitResponse, error) {
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var splitID int
+		err := tx.Raw(`
+			INSERT INTO expense_splits (expense_id, method)
+			VALUES (?, ?)
+			ON CONFLICT (expense_id) DO UPDATE
+				SET method = EXCLUDED.method, updated_at = CURRENT_TIMESTAMP
+			RETURNING id
+		`, expenseID, method).Scan(&splitID).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`DELETE FROM expense_split_shares WHERE split_id = ?`, splitID).Error; err != nil {
+			return err
+		}
+		for i, participantID := range userIDs {
+			err := tx.Exec(`
+				INSERT INTO expense_split_shares (split_id, user_id, weight)
+				VALUES (?, ?, ?)
+			`, splitID, participantID, weights[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[SetExpenseSplit] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+
+	split, err := GetExpenseSplit(userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[SetExpenseSplit] Success - ExpenseID: %d, UserID: %d, Method: %s, Participants: %d",
+		expenseID, userID, method, len(userIDs))
+	go notifySplitParticipants(userID, expenseID, split)
+	return split, nil
+}
+
+// GetExpenseSplit retrieves the split of an expense owned by the user, or nil if it is not split
+func GetExpenseSplit(userID, expenseID int) (*mdlFeatureOne.SplitResponse, error) {
+	var jsonResult string
+
+	result := config.DBConnList[0].Raw(`
+		SELECT json_build_object(
+			'expenseId', t.id,
+			'payerId', t.user_id,
+			'method', s.method,
+			'total', t.amount,
+			'shares', COALESCE((
+				SELECT json_agg(json_build_object(
+					'userId', a.user_id, 'name', u.name, 'email', u.email,
+					'value', a.weight, 'amount', a.amount
+				) ORDER BY a.amount DESC, a.user_id)
+				FROM expense_split_amounts a
+				JOIN users u ON u.id = a.user_id
+				WHERE a.split_id = s.id
+			), '[]'::json),
+			'createdAt', s.created_at,
+			'updatedAt', s.updated_at
+		)::text
+		FROM expense_splits s
+		JOIN transactions t ON t.id = s.expense_id
+		WHERE s.expense_id = ? AND t.user_id = ? AND t.deleted_at IS NULL
+	`, expenseID, userID).Scan(&jsonResult)
+
+	if result.Error != nil {
+		log.Printf("[GetExpenseSplit] Error for user %d, expense %d: %v", userID, expenseID, result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || jsonResult == "" {
+		return nil, nil
+	}
+
+	var split mdlFeatureOne.SplitResponse
+	if err := json.Unmarshal([]byte(jsonResult), &split); err != nil {
+		log.Printf("[GetExpenseSplit] JSON parse error: %v", err)
+		return nil, err
+	}
+	return &split, nil
+}
+
+// DeleteExpenseSplit removes the split of an expense, reporting whether there was one
+func DeleteExpenseSplit(userID, expenseID int) (bool, error) {
+	result := config.DBConnList[0].Exec(`
+		DELETE FROM expense_splits s
+		USING transactions t
+		WHERE s.expense_id = t.id AND t.id = ? AND t.user_id = ?
+	`, expenseID, userID)
+
+	if result.Error != nil {
+		log.Printf("[DeleteExpenseSplit] Error for user %d, expense %d: %v", userID, expenseID, result.Error)
+		return false, result.Error
+	}
+
+	log.Printf("[DeleteExpenseSplit] Success - ExpenseID: %d, UserID: %d, Deleted: %d",
+		expenseID, userID, result.RowsAffected)
+	return result.RowsAffected > 0, nil
+}
+
+// notifySplitParticipants tells every participant other than the payer what they owe
+func notifySplitParticipants(payerID, expenseID int, split *mdlFeatureOne.SplitResponse) {
+	payer, err := GetUserByID(payerID)
+	if err != nil {
+		return
+	}
+	expense, err := GetExpenseByID(payerID, expenseID)
+	if err != nil {
+		return
+	}
+
+	for _, share := range split.Shares {
+		if share.UserID == payerID {
+			continue
+		}
+		title := fmt.Sprintf("%s split an expense with you", payer.Name)
+		message := fmt.Sprintf("Your share of \"%s\" (%.2f on %s) is %.2f.",
+			expense.Title, split.Total, expense.Date, share.Amount)
+		data := map[string]interface{}{
+			"expenseId": expenseID,
+			"payerId":   payerID,
+			"amount":    share.Amount,
+		}
+		if _, err := CreateNotification(share.UserID, "expense_split", title, message, data); err != nil {
+			log.Printf("[notifySplitParticipants] Error notifying user %d: %v", share.UserID, err)
+		}
+	}
+}
+
+// ============================================
+// BALANCE OPERATIONS
+// ============================================
+
+// GetBalances retrieves the user's non-zero balances with every other user
+func GetBalances(userID int) (*mdlFeatureOne.BalancesResponse, error) {
+	result := mdlFeatureOne.BalancesResponse{Balances: []mdlFeatureOne.UserBalance{}}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT u.id AS user_id, u.name, u.email, b.balance
+		FROM user_balances(?) b
+		JOIN users u ON u.id = b.other_user_id
+		WHERE b.balance <> 0
+		ORDER BY ABS(b.balance) DESC, u.id
+	`, userID).Scan(&result.Balances).Error
+
+	if err != nil {
+		log.Printf("[GetBalances] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	for _, balance := range result.Balances {
+		if balance.Balance > 0 {
+			result.OwedToYou += balance.Balance
+		} else {
+			result.YouOwe -= balance.Balance
+		}
+	}
+	result.OwedToYou = math.Round(result.OwedToYou*100) / 100
+	result.YouOwe = math.Round(result.YouOwe*100) / 100
+	result.Net = math.Round((result.OwedToYou-result.YouOwe)*100) / 100
+
+	log.Printf("[GetBalances] Success - UserID: %d, Counterparties: %d, Net: %.2f",
+		userID, len(result.Balances), result.Net)
+	return &result, nil
+}
+
+// GetPairBalance returns what otherID owes userID; negative when userID owes otherID
+func GetPairBalance(userID, otherID int) (float64, error) {
+	var balance float64
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE((SELECT balance FROM user_balances(?) WHERE other_user_id = ?), 0)
+	`, userID, otherID).Scan(&balance).Error
+
+	if err != nil {
+		log.Printf("[GetPairBalance] Error for users %d and %d: %v", userID, otherID, err)
+		return 0, err
+	}
+	return balance, nil
+}
+
+// GetBalanceWithUser retrieves the balance with one user and the splits and
+// settlements behind it, newest first
+func GetBalanceWithUser(userID, otherID, limit, offset int) (*mdlFeatureOne.UserBalanceResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.UserBalanceResponse{
+		Activity: []mdlFeatureOne.BalanceActivityItem{},
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	err := db.Raw(`
+		SELECT u.id AS user_id, u.name, u.email, COALESCE(b.balance, 0) AS balance
+		FROM users u
+		LEFT JOIN user_balances(?) b ON b.other_user_id = u.id
+		WHERE u.id = ?
+	`, userID, otherID).Scan(&result.UserBalance).Error
+	if err != nil {
+		log.Printf("[GetBalanceWithUser] Error for users %d and %d: %v", userID, otherID, err)
+		return nil, err
+	}
+
+	// Positive amounts raised what the other user owes userID
+	activity := `
+		SELECT 'split' AS type, a.split_id AS id, a.expense_id AS "expenseId", a.title, a.date::text AS date,
+			CASE WHEN a.payer_id = $1 THEN a.amount ELSE -a.amount END AS amount
+		FROM expense_split_amounts a
+		WHERE (a.payer_id = $1 AND a.user_id = $2) OR (a.payer_id = $2 AND a.user_id = $1)
+		UNION ALL
+		SELECT 'settlement', s.id, NULL, COALESCE(s.notes, 'Settlement'), s.date::text,
+			CASE WHEN s.payer_id = $1 THEN s.amount ELSE -s.amount END
+		FROM settlements s
+		WHERE s.deleted_at IS NULL
+			AND ((s.payer_id = $1 AND s.payee_id = $2) OR (s.payer_id = $2 AND s.payee_id = $1))`
+
+	err = db.Raw(`SELECT COUNT(*) FROM (`+activity+`) x`, userID, otherID).Scan(&result.Pagination.Total).Error
+	if err != nil {
+		log.Printf("[GetBalanceWithUser] Error counting activity for users %d and %d: %v", userID, otherID, err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(json_agg(x ORDER BY x.date DESC, x.type, x.id DESC), '[]')::text
+		FROM (
+			SELECT * FROM (`+activity+`) a
+			ORDER BY a.date DESC, a.type, a.id DESC
+			LIMIT $3 OFFSET $4
+		) x
+	`, userID, otherID, limit, offset).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetBalanceWithUser] Error for users %d and %d: %v", userID, otherID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Activity); err != nil {
+		log.Printf("[GetBalanceWithUser] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetBalanceWithUser] Success - UserID: %d, OtherUserID: %d, Balance: %.2f",
+		userID, otherID, result.Balance)
+	return &result, nil
+}
+
+// ============================================
+// SETTLEMENT OPERATIONS
+// ============================================
+
+// SettlementExists checks if a settlement involving the user exists
+func SettlementExists(userID, settlementID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM settlements
+			WHERE id = $1 AND (payer_id = $2 OR payee_id = $2) AND deleted_at IS NULL)`,
+		settlementID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[SettlementExists] Error checking settlement %d for user %d: %v", settlementID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateSettlement records a payment between two users and notifies the other party
+func CreateSettlement(userID int, req *mdlFeatureOne.CreateSettlementRequest, amount float64) (*mdlFeatureOne.SettlementResponse, error) {
+	var settlement mdlFeatureOne.SettlementResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		WITH created AS (
+			INSERT INTO settlements (payer_id, payee_id, amount, date, notes, created_by)
+			VALUES (?, ?, ?, ?::date, ?, ?)
+			RETURNING *
+		)
+		SELECT row_to_json(x)::text FROM (SELECT `+settlementColumns+` FROM created) x
+	`, req.PayerID, req.PayeeID, amount, req.Date, req.Notes, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[CreateSettlement] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &settlement); err != nil {
+		log.Printf("[CreateSettlement] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[CreateSettlement] Success - SettlementID: %d, PayerID: %d, PayeeID: %d, Amount: %.2f",
+		settlement.ID, settlement.PayerID, settlement.PayeeID, settlement.Amount)
+	go notifySettlement(userID, &settlement)
+	return &settlement, nil
+}
+
+// notifySettlement tells the party who did not record the settlement about it
+func notifySettlement(userID int, settlement *mdlFeatureOne.SettlementResponse) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return
+	}
+
+	otherID := settlement.PayeeID
+	message := fmt.Sprintf("%s recorded paying you %.2f on %s.", user.Name, settlement.Amount, settlement.Date)
+	if userID == settlement.PayeeID {
+		otherID = settlement.PayerID
+		message = fmt.Sprintf("%s recorded receiving %.2f from you on %s.", user.Name, settlement.Amount, settlement.Date)
+	}
+
+	data := map[string]interface{}{
+		"settlementId": settlement.ID,
+		"payerId":      settlement.PayerID,
+		"payeeId":      settlement.PayeeID,
+		"amount":       settlement.Amount,
+	}
+	if _, err := CreateNotification(otherID, "settlement", "Settlement recorded", message, data); err != nil {
+		log.Printf("[notifySettlement] Error notifying user %d: %v", otherID, err)
+	}
+}
+
+// GetSettlementByID retrieves a settlement involving the user
+func GetSettlementByID(userID, settlementID int) (*mdlFeatureOne.SettlementResponse, error) {
+	var settlement mdlFeatureOne.SettlementResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT row_to_json(x)::text
+		FROM (
+			SELECT `+settlementColumns+`
+			FROM settlements
+			WHERE id = ? AND (payer_id = ? OR payee_id = ?) AND deleted_at IS NULL
+		) x
+	`, settlementID, userID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetSettlementByID] Error for user %d, settlement %d: %v", userID, settlementID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &settlement); err != nil {
+		log.Printf("[GetSettlementByID] JSON parse error: %v", err)
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+// GetSettlements retrieves the settlements the user paid or received, newest first
+func GetSettlements(userID int, filters *mdlFeatureOne.SettlementFilters) (*mdlFeatureOne.SettlementListResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.SettlementListResponse{
+		Settlements: []mdlFeatureOne.SettlementResponse{},
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  filters.Limit,
+			Offset: filters.Offset,
+		},
+	}
+
+	where := `
+		WHERE deleted_at IS NULL
+			AND (payer_id = $1 OR payee_id = $1)
+			AND (CAST($2 AS INT) IS NULL OR payer_id = $2 OR payee_id = $2)`
+
+	err := db.Raw(`SELECT COUNT(*) FROM settlements`+where, userID, filters.UserID).Scan(&result.Pagination.Total).Error
+	if err != nil {
+		log.Printf("[GetSettlements] Error counting settlements for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(json_agg(x ORDER BY x.date DESC, x.id DESC), '[]')::text
+		FROM (
+			SELECT `+settlementColumns+`
+			FROM settlements`+where+`
+			ORDER BY date DESC, id DESC
+			LIMIT $3 OFFSET $4
+		) x
+	`, userID, filters.UserID, filters.Limit, filters.Offset).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetSettlements] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Settlements); err != nil {
+		log.Printf("[GetSettlements] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetSettlements] Success - UserID: %d, Count: %d", userID, len(result.Settlements))
+	return &result, nil
+}
+
+// DeleteSettlement soft-deletes a settlement, which restores the balance it settled
+func DeleteSettlement(userID, settlementID int) error {
+	err := config.DBConnList[0].Exec(`
+		UPDATE settlements SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND created_by = ? AND deleted_at IS NULL
+	`, settlementID, userID).Error
+
+	if err != nil {
+		log.Printf("[DeleteSettlement] Error for user %d, settlement %d: %v", userID, settlementID, err)
+		return err
+	}
+
+	log.Printf("[DeleteSettlement] Success - SettlementID: %d, UserID: %d", settlementID, userID)
+	return nil
+}
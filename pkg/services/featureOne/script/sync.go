@@ -0,0 +1,372 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// syncExpenseJSON selects an expense in the shape of SyncExpense
+const syncExpenseJSON = `transaction_to_jsonb(t) || jsonb_build_object('clientId', t.client_id)`
+
+// syncRevisionSource attributes changes pushed by offline clients
+var syncRevisionSource = mdlFeatureOne.RevisionSource{Type: mdlFeatureOne.RevisionSourceSync}
+
+// ============================================
+// SYNC PULL OPERATIONS
+// ============================================
+
+// GetSyncChanges returns the user's expenses and tags and the categories changed
+// after filters.Since, oldest change first. Each record appears once with its
+// current state, or as a tombstone if it has been deleted.
+func GetSyncChanges(userID int, filters *mdlFeatureOne.SyncFilters) (*mdlFeatureOne.SyncPullResponse, error) {
+	result := mdlFeatureOne.SyncPullResponse{
+		Expenses:   []mdlFeatureOne.SyncExpense{},
+		Categories: []mdlFeatureOne.CategoryResponse{},
+		Tags:       []mdlFeatureOne.TagResponse{},
+		Deleted:    []mdlFeatureOne.SyncTombstone{},
+		Token:      hlpFeatureOne.EncodeSyncToken(filters.Since),
+	}
+
+	// The lock waits for writers still holding an earlier seq, and keeps the
+	// records from changing until they are read
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`SELECT sync_changes_lock(?)`, userID).Error; err != nil {
+			return err
+		}
+
+		var changes []mdlFeatureOne.SyncChangeEntity
+		err := tx.Raw(`
+			SELECT entity, entity_id, client_id, operation, seq, changed_at
+			FROM sync_changes
+			WHERE (user_id = ? OR user_id IS NULL) AND seq > ?
+			ORDER BY seq
+			LIMIT ?
+		`, userID, filters.Since, filters.Limit+1).Scan(&changes).Error
+		if err != nil {
+			return err
+		}
+
+		if len(changes) > filters.Limit {
+			result.HasMore = true
+			changes = changes[:filters.Limit]
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		result.Token = hlpFeatureOne.EncodeSyncToken(changes[len(changes)-1].Seq)
+
+		upserted := map[string][]int{}
+		for _, change := range changes {
+			if change.Operation == mdlFeatureOne.SyncOperationDelete {
+				result.Deleted = append(result.Deleted, mdlFeatureOne.SyncTombstone{
+					Entity:    change.Entity,
+					ID:        change.EntityID,
+					ClientID:  change.ClientID,
+					DeletedAt: change.ChangedAt,
+				})
+				continue
+			}
+			upserted[change.Entity] = append(upserted[change.Entity], change.EntityID)
+		}
+
+		if ids := upserted[mdlFeatureOne.SyncEntityExpense]; len(ids) > 0 {
+			var expensesJSON string
+			err := tx.Raw(`
+				SELECT COALESCE(jsonb_agg(`+syncExpenseJSON+` ORDER BY t.id), '[]'::jsonb)::text
+				FROM transactions t
+				WHERE t.user_id = ? AND t.id IN ? AND t.type = 'expense' AND t.deleted_at IS NULL
+			`, userID, ids).Scan(&expensesJSON).Error
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal([]byte(expensesJSON), &result.Expenses); err != nil {
+				return err
+			}
+		}
+
+		if ids := upserted[mdlFeatureOne.SyncEntityTag]; len(ids) > 0 {
+			err := tx.Raw(`
+				SELECT `+tagColumns+`
+				FROM tags tg
+				WHERE tg.user_id = ? AND tg.id IN ?
+				ORDER BY tg.id
+			`, userID, ids).Scan(&result.Tags).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if ids := upserted[mdlFeatureOne.SyncEntityCategory]; len(ids) > 0 {
+			err := tx.Raw(`
+				SELECT id, name, description, kind, created_at, updated_at
+				FROM expense_categories
+				WHERE id IN ?
+				ORDER BY id
+			`, ids).Scan(&result.Categories).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("[GetSyncChanges] Error for user %d since %d: %v", userID, filters.Since, err)
+		return nil, err
+	}
+
+	log.Printf("[GetSyncChanges] Success - UserID: %d, Expenses: %d, Categories: %d, Tags: %d, Deleted: %d, HasMore: %v",
+		userID, len(result.Expenses), len(result.Categories), len(result.Tags), len(result.Deleted), result.HasMore)
+	return &result, nil
+}
+
+// ============================================
+// SYNC PUSH OPERATIONS
+// ============================================
+
+// ApplySyncChanges applies the changes a client pushed, in order. Each change
+// stands alone: one that conflicts with the server or is invalid does not stop
+// the others. Changes go through the usual expense operations, so they are
+// recorded in the history and reach webhooks and live events.
+func ApplySyncChanges(userID int, changes []mdlFeatureOne.SyncChange) *mdlFeatureOne.SyncPushResponse {
+	response := mdlFeatureOne.SyncPushResponse{Results: []mdlFeatureOne.SyncChangeResult{}}
+
+	for i := range changes {
+		result := applySyncChange(userID, &changes[i])
+		result.Index = i
+
+		switch result.Status {
+		case mdlFeatureOne.SyncStatusApplied:
+			response.Applied++
+		case mdlFeatureOne.SyncStatusConflict:
+			response.Conflicts++
+		default:
+			response.Rejected++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	log.Printf("[ApplySyncChanges] Completed - UserID: %d, Applied: %d, Conflicts: %d, Rejected: %d",
+		userID, response.Applied, response.Conflicts, response.Rejected)
+	return &response
+}
+
+// applySyncChange applies one pushed change to an expense
+func applySyncChange(userID int, change *mdlFeatureOne.SyncChange) mdlFeatureOne.SyncChangeResult {
+	result := mdlFeatureOne.SyncChangeResult{ClientID: change.ClientID, ID: change.ID}
+	reject := func(message string) mdlFeatureOne.SyncChangeResult {
+		result.Status = mdlFeatureOne.SyncStatusRejected
+		result.Message = message
+		return result
+	}
+	conflict := func(message string, expenseID int) mdlFeatureOne.SyncChangeResult {
+		result.Status = mdlFeatureOne.SyncStatusConflict
+		result.Message = message
+		if expenseID != 0 {
+			result.Expense, _ = getSyncExpense(userID, expenseID)
+		}
+		return result
+	}
+	applied := func(expenseID int) mdlFeatureOne.SyncChangeResult {
+		result.Status = mdlFeatureOne.SyncStatusApplied
+		if expenseID != 0 {
+			result.Expense, _ = getSyncExpense(userID, expenseID)
+		}
+		return result
+	}
+
+	if err := hlpFeatureOne.ValidateSyncChange(change); err != nil {
+		return reject(err.Error())
+	}
+	result.ClientID = change.ClientID
+
+	state, err := getSyncExpenseState(userID, change)
+	if err != nil {
+		return reject("Failed to look up expense")
+	}
+	if state.ID != 0 {
+		result.ID = &state.ID
+	}
+
+	if change.Operation == mdlFeatureOne.SyncOperationDelete {
+		switch {
+		case state.ID == 0 && change.ID != nil:
+			return reject("Expense not found")
+		case state.ID == 0 || state.Deleted:
+			// Never reached the server or already gone
+			return applied(0)
+		case ExpenseLocked(state.ID):
+			return conflict("Expense is locked by a submitted reimbursement report", state.ID)
+		}
+
+		deleted, err := DeleteExpense(userID, state.ID, change.Version)
+		if err != nil {
+			return reject("Failed to delete expense")
+		}
+		if deleted.VersionMismatch {
+			return conflict("Expense was modified since the expected version", state.ID)
+		}
+		return applied(0)
+	}
+
+	switch {
+	case state.ID == 0 && change.ID != nil:
+		return reject("Expense not found")
+	case state.Deleted:
+		return conflict("Expense was deleted on the server", 0)
+	case state.ID != 0 && change.Version == nil && change.ID == nil:
+		// A create retried after its response was lost
+		return applied(state.ID)
+	case state.ID != 0 && change.Version == nil:
+		return reject("version is required to update an expense")
+	case state.ID != 0 && ExpenseLocked(state.ID):
+		return conflict("Expense is locked by a submitted reimbursement report", state.ID)
+	}
+
+	if change.Data.CategoryID != nil && !ExpenseCategoryExists(*change.Data.CategoryID) {
+		return reject("Category not found")
+	}
+	if change.Data.AccountID != nil && !AccountExists(userID, *change.Data.AccountID) {
+		return reject("Account not found")
+	}
+
+	if state.ID == 0 {
+		expenseID, err := createSyncExpense(userID, hlpFeatureOne.SyncCreateExpenseRequest(change.Data), *change.ClientID)
+		if err != nil {
+			return reject("Failed to create expense")
+		}
+		result.ID = &expenseID
+		return applied(expenseID)
+	}
+
+	updated, err := UpdateExpense(userID, state.ID, hlpFeatureOne.SyncUpdateExpenseRequest(change.Data),
+		change.Version, syncRevisionSource)
+	if err != nil {
+		return reject("Failed to update expense")
+	}
+	if updated == nil {
+		return conflict("Expense was modified since the expected version", state.ID)
+	}
+	return applied(state.ID)
+}
+
+// createSyncExpense creates an expense pushed by a client, saving its client ID
+// in the same insert. The unique index decides atomically which of concurrent
+// pushes of the same client ID creates it; the others get the existing expense.
+func createSyncExpense(userID int, req *mdlFeatureOne.CreateExpenseRequest, clientID string) (int, error) {
+	var expenseID int
+
+	var inserted bool
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`
+			INSERT INTO transactions (user_id, type, title, amount, category_id, date, notes, client_id)
+			VALUES (?, 'expense', ?, ?, ?, ?::date, ?, ?::uuid)
+			ON CONFLICT (user_id, client_id) WHERE client_id IS NOT NULL DO NOTHING
+			RETURNING id
+		`, userID, req.Title, req.Amount, req.CategoryID, req.Date, req.Notes, clientID).Scan(&expenseID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		inserted = true
+
+		if len(req.Tags) > 0 {
+			return setTransactionTags(tx, userID, expenseID, req.Tags)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("[createSyncExpense] Error for user %d, client ID %s: %v", userID, clientID, err)
+		return 0, err
+	}
+	if !inserted {
+		err := config.DBConnList[0].Raw(
+			`SELECT id FROM transactions WHERE user_id = ? AND client_id = ?::uuid`,
+			userID, clientID,
+		).Scan(&expenseID).Error
+		if err != nil {
+			log.Printf("[createSyncExpense] Error finding client ID %s for user %d: %v", clientID, userID, err)
+			return 0, err
+		}
+		log.Printf("[createSyncExpense] Already created - UserID: %d, ClientID: %s, ExpenseID: %d", userID, clientID, expenseID)
+		return expenseID, nil
+	}
+
+	completeNewExpense(userID, expenseID, req)
+
+	recordExpenseRevision(userID, expenseID, syncRevisionSource)
+
+	created, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("[createSyncExpense] Success - ExpenseID: %d, UserID: %d, ClientID: %s, Amount: %.2f",
+		created.ID, userID, clientID, created.Amount)
+	checkBudgetAlertsAsync(userID, created)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseCreated, created)
+	return expenseID, nil
+}
+
+// getSyncExpenseState finds the expense a change names by ID, or else by client
+// ID. Purged expenses are found by their tombstones.
+func getSyncExpenseState(userID int, change *mdlFeatureOne.SyncChange) (*mdlFeatureOne.SyncExpenseState, error) {
+	var state mdlFeatureOne.SyncExpenseState
+
+	expenseMatch, tombstoneMatch := "t.id = ?", "sc.entity_id = ?"
+	var key interface{}
+	if change.ID != nil {
+		key = *change.ID
+	} else {
+		expenseMatch, tombstoneMatch = "t.client_id = ?::uuid", "sc.client_id = ?::uuid"
+		key = *change.ClientID
+	}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT id, version, deleted FROM (
+			SELECT t.id, t.version, t.deleted_at IS NOT NULL AS deleted, 1 AS priority
+			FROM transactions t
+			WHERE t.user_id = ? AND t.type = 'expense' AND `+expenseMatch+`
+			UNION ALL
+			SELECT sc.entity_id, 0, TRUE, 2
+			FROM sync_changes sc
+			WHERE sc.user_id = ? AND sc.entity = 'expense' AND sc.operation = 'delete' AND `+tombstoneMatch+`
+		) s
+		ORDER BY priority
+		LIMIT 1
+	`, userID, key, userID, key).Scan(&state).Error
+
+	if err != nil {
+		log.Printf("[getSyncExpenseState] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+	return &state, nil
+}
+
+// getSyncExpense retrieves a live expense with its client ID
+func getSyncExpense(userID, expenseID int) (*mdlFeatureOne.SyncExpense, error) {
+	var expense mdlFeatureOne.SyncExpense
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT (`+syncExpenseJSON+`)::text
+		FROM transactions t
+		WHERE t.id = ? AND t.user_id = ? AND t.type = 'expense' AND t.deleted_at IS NULL
+	`, expenseID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[getSyncExpense] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &expense); err != nil {
+		log.Printf("[getSyncExpense] JSON parse error: %v", err)
+		return nil, err
+	}
+	return &expense, nil
+}
@@ -0,0 +1,267 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// tagColumns selects a tag in the shape of TagResponse
+const tagColumns = `
+	tg.id, tg.name, tg.created_at, tg.updated_at,
+	(SELECT COUNT(*) FROM transaction_tags tt
+		JOIN transactions t ON t.id = tt.transaction_id AND t.deleted_at IS NULL
+		WHERE tt.tag_id = tg.id) AS usage_count`
+
+// ============================================
+// TAG OPERATIONS
+// ============================================
+
+// TagExists checks if a tag exists for a user
+func TagExists(userID, tagID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM tags WHERE id = $1 AND user_id = $2)`,
+		tagID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[TagExists] Error checking tag %d for user %d: %v", tagID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// TagNameTaken checks if another tag of the user already has the name
+func TagNameTaken(userID int, name string, excludeTagID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM tags WHERE user_id = $1 AND name = $2 AND id <> $3)`,
+		userID,
+		name,
+		excludeTagID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[TagNameTaken] Error checking tag name %s for user %d: %v", name, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CreateTag inserts a tag, returning the existing one if the name is already used
+func CreateTag(userID int, name string) (*mdlFeatureOne.TagResponse, error) {
+	var tagID int
+
+	err := config.DBConnList[0].Raw(`
+		INSERT INTO tags (user_id, name)
+		VALUES (?, ?)
+		ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	`, userID, name).Scan(&tagID).Error
+
+	if err != nil {
+		log.Printf("[CreateTag] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[CreateTag] Success - TagID: %d, UserID: %d, Name: %s", tagID, userID, name)
+	return GetTagByID(userID, tagID)
+}
+
+// GetTags retrieves all tags of a user with their usage counts
+func GetTags(userID int) ([]mdlFeatureOne.TagResponse, error) {
+	tags := []mdlFeatureOne.TagResponse{}
+
+	err := config.DBConnList[0].Raw(`
+		SELECT `+tagColumns+`
+		FROM tags tg
+		WHERE tg.user_id = ?
+		ORDER BY tg.name
+	`, userID).Scan(&tags).Error
+
+	if err != nil {
+		log.Printf("[GetTags] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	log.Printf("[GetTags] Success - UserID: %d, Count: %d", userID, len(tags))
+	return tags, nil
+}
+
+// GetTagByID retrieves a single tag
+func GetTagByID(userID, tagID int) (*mdlFeatureOne.TagResponse, error) {
+	var tag mdlFeatureOne.TagResponse
+
+	err := config.DBConnList[0].Raw(`
+		SELECT `+tagColumns+`
+		FROM tags tg
+		WHERE tg.id = ? AND tg.user_id = ?
+	`, tagID, userID).Scan(&tag).Error
+
+	if err != nil {
+		log.Printf("[GetTagByID] Error for user %d, tag %d: %v", userID, tagID, err)
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// RenameTag changes a tag's name; the caller checks the name is free. The
+// expenses carrying the tag are reported as updated.
+func RenameTag(userID, tagID int, name string) (*mdlFeatureOne.TagResponse, error) {
+	var affected []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE tags SET name = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ?
+		`, name, tagID, userID).Error
+		if err != nil {
+			return err
+		}
+
+		affected, err = taggedExpenseIDs(tx, userID, tagID)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("[RenameTag] Error for user %d, tag %d: %v", userID, tagID, err)
+		return nil, err
+	}
+
+	log.Printf("[RenameTag] Success - TagID: %d, UserID: %d, Name: %s", tagID, userID, name)
+	go emitExpensesUpdated(userID, affected)
+	return GetTagByID(userID, tagID)
+}
+
+// MergeTag moves every link of the source tag onto the target tag and deletes the source
+func MergeTag(userID, sourceTagID, targetTagID int) (int64, error) {
+	var moved int64
+	var affected []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var err error
+		affected, err = taggedExpenseIDs(tx, userID, sourceTagID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT tt.transaction_id, ?
+			FROM transaction_tags tt
+			JOIN tags src ON src.id = tt.tag_id AND src.user_id = ?
+			WHERE tt.tag_id = ?
+			ON CONFLICT DO NOTHING
+		`, targetTagID, userID, sourceTagID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		return tx.Exec(`DELETE FROM tags WHERE id = ? AND user_id = ?`, sourceTagID, userID).Error
+	})
+
+	if err != nil {
+		log.Printf("[MergeTag] Error for user %d, tag %d -> %d: %v", userID, sourceTagID, targetTagID, err)
+		return 0, err
+	}
+
+	log.Printf("[MergeTag] Success - UserID: %d, Source: %d, Target: %d, Moved: %d",
+		userID, sourceTagID, targetTagID, moved)
+	go emitExpensesUpdated(userID, affected)
+	return moved, nil
+}
+
+// DeleteTag deletes a tag and unlinks it from every transaction
+func DeleteTag(userID, tagID int) error {
+	var affected []int
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		var err error
+		affected, err = taggedExpenseIDs(tx, userID, tagID)
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`DELETE FROM tags WHERE id = ? AND user_id = ?`, tagID, userID).Error
+	})
+
+	if err != nil {
+		log.Printf("[DeleteTag] Error for user %d, tag %d: %v", userID, tagID, err)
+		return err
+	}
+
+	log.Printf("[DeleteTag] Success - TagID: %d, UserID: %d", tagID, userID)
+	go emitExpensesUpdated(userID, affected)
+	return nil
+}
+
+// taggedExpenseIDs lists the user's live expenses that carry a tag
+func taggedExpenseIDs(tx *gorm.DB, userID, tagID int) ([]int, error) {
+	ids := []int{}
+	err := tx.Raw(`
+		SELECT t.id
+		FROM transaction_tags tt
+		JOIN transactions t ON t.id = tt.transaction_id
+		WHERE tt.tag_id = ? AND t.user_id = ? AND t.type = 'expense' AND t.deleted_at IS NULL
+		ORDER BY t.id
+	`, tagID, userID).Scan(&ids).Error
+	return ids, err
+}
+
+// SetTransactionTags replaces the tags of a transaction, creating missing tags on the fly
+func SetTransactionTags(userID, transactionID int, tags []string) error {
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		return setTransactionTags(tx, userID, transactionID, tags)
+	})
+
+	if err != nil {
+		log.Printf("[SetTransactionTags] Error for user %d, transaction %d: %v", userID, transactionID, err)
+		return err
+	}
+
+	return nil
+}
+
+// setTransactionTags is SetTransactionTags inside the caller's database transaction
+func setTransactionTags(tx *gorm.DB, userID, transactionID int, tags []string) error {
+	namesJSON, err := json.Marshal(hlpFeatureOne.NormalizeTags(tags))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Exec(`DELETE FROM transaction_tags WHERE transaction_id = ?`, transactionID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO tags (user_id, name)
+		SELECT ?, x FROM jsonb_array_elements_text(?::jsonb) x
+		ON CONFLICT (user_id, name) DO NOTHING
+	`, userID, string(namesJSON)).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO transaction_tags (transaction_id, tag_id)
+		SELECT ?, id FROM tags
+		WHERE user_id = ? AND name IN (SELECT jsonb_array_elements_text(?::jsonb))
+		ON CONFLICT DO NOTHING
+	`, transactionID, userID, string(namesJSON)).Error; err != nil {
+		return err
+	}
+
+	// Tags are part of the transaction, so changing them counts as an update
+	return tx.Exec(`UPDATE transactions SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, transactionID).Error
+}
@@ -0,0 +1,224 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+)
+
+// ============================================
+// TRANSACTION OPERATIONS
+// ============================================
+
+// CreateTransaction inserts a new expense, income or transfer
+func CreateTransaction(userID int, req *mdlFeatureOne.CreateTransactionRequest) (*mdlFeatureOne.TransactionResponse, error) {
+	var transaction mdlFeatureOne.TransactionResponse
+
+	dataJSON, err := json.Marshal(req)
+	if err != nil {
+		log.Printf("[CreateTransaction] Error marshaling request: %v", err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = config.DBConnList[0].Raw(
+		`SELECT create_transaction($1, $2::jsonb)::text`,
+		userID,
+		string(dataJSON),
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[CreateTransaction] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &transaction); err != nil {
+		log.Printf("[CreateTransaction] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	// The transaction is already saved, so a tagging failure is logged rather than returned
+	if len(req.Tags) > 0 {
+		if err := SetTransactionTags(userID, transaction.ID, req.Tags); err != nil {
+			log.Printf("[CreateTransaction] Error tagging transaction %d: %v", transaction.ID, err)
+		}
+		if tagged, err := GetTransactionByID(userID, transaction.ID); err == nil {
+			transaction = *tagged
+		}
+	}
+
+	log.Printf("[CreateTransaction] Success - TransactionID: %d, UserID: %d, Type: %s, Amount: %.2f",
+		transaction.ID, userID, transaction.Type, transaction.Amount)
+	checkTransactionBudgetAlerts(userID, &transaction)
+	emitTransactionEvent(userID, &transaction, mdlFeatureOne.WebhookEventExpenseCreated)
+	return &transaction, nil
+}
+
+// GetTransactions retrieves transactions of every type with filters and an income/expense summary
+func GetTransactions(userID int, filters *mdlFeatureOne.TransactionFilters) (*mdlFeatureOne.TransactionListResponse, error) {
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		log.Printf("[GetTransactions] Error marshaling filters: %v", err)
+		return nil, err
+	}
+
+	var resultJSON string
+	err = config.DBConnList[0].Raw(
+		`SELECT get_transactions($1, $2::jsonb)::text`,
+		userID,
+		string(filtersJSON),
+	).Scan(&resultJSON).Error
+
+	if err != nil {
+		log.Printf("[GetTransactions] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	var result struct {
+		mdlFeatureOne.TransactionListResponse
+		Cursors mdlFeatureOne.ListCursors `json:"cursors"`
+	}
+	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
+		log.Printf("[GetTransactions] Error parsing result: %v", err)
+		return nil, err
+	}
+
+	// Turn the page's boundary keys into opaque cursors
+	hlpFeatureOne.ApplyListCursors(&filters.ExpenseFilters, &result.Pagination, result.Cursors)
+
+	log.Printf("[GetTransactions] Success - UserID: %d, Count: %d, Total: %d",
+		userID, len(result.Transactions), result.Pagination.Total)
+	return &result.TransactionListResponse, nil
+}
+
+// TransactionExists checks if a transaction of any type exists for a user
+func TransactionExists(userID, transactionID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		transactionID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[TransactionExists] Error checking transaction %d for user %d: %v", transactionID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// GetTransactionByID retrieves a single transaction by ID
+func GetTransactionByID(userID, transactionID int) (*mdlFeatureOne.TransactionResponse, error) {
+	var transaction mdlFeatureOne.TransactionResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(
+		`SELECT get_transaction_by_id($1, $2)::text`,
+		userID,
+		transactionID,
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetTransactionByID] Error for user %d, transaction %d: %v", userID, transactionID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &transaction); err != nil {
+		log.Printf("[GetTransactionByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// UpdateTransaction updates an existing transaction; omitted fields are kept
+func UpdateTransaction(userID, transactionID int, req *mdlFeatureOne.UpdateTransactionRequest) (*mdlFeatureOne.TransactionResponse, error) {
+	var transaction mdlFeatureOne.TransactionResponse
+
+	dataJSON, err := json.Marshal(req)
+	if err != nil {
+		log.Printf("[UpdateTransaction] Error marshaling request: %v", err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = config.DBConnList[0].Raw(
+		`SELECT update_transaction($1, $2, $3::jsonb)::text`,
+		userID,
+		transactionID,
+		string(dataJSON),
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[UpdateTransaction] Error for user %d, transaction %d: %v", userID, transactionID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &transaction); err != nil {
+		log.Printf("[UpdateTransaction] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	// nil keeps the current tags, an empty list clears them
+	if req.Tags != nil {
+		if err := SetTransactionTags(userID, transactionID, req.Tags); err != nil {
+			return nil, err
+		}
+		tagged, err := GetTransactionByID(userID, transactionID)
+		if err != nil {
+			return nil, err
+		}
+		transaction = *tagged
+	}
+
+	log.Printf("[UpdateTransaction] Success - TransactionID: %d, UserID: %d, Type: %s",
+		transaction.ID, userID, transaction.Type)
+	checkTransactionBudgetAlerts(userID, &transaction)
+	emitTransactionEvent(userID, &transaction, mdlFeatureOne.WebhookEventExpenseUpdated)
+	return &transaction, nil
+}
+
+// DeleteTransaction soft deletes a transaction of any type
+func DeleteTransaction(userID, transactionID int) (*mdlFeatureOne.DeleteExpenseResult, error) {
+	var result mdlFeatureOne.DeleteExpenseResult
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(
+		`SELECT delete_transaction($1, $2)::text`,
+		userID,
+		transactionID,
+	).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[DeleteTransaction] Error for user %d, transaction %d: %v", userID, transactionID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result); err != nil {
+		log.Printf("[DeleteTransaction] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[DeleteTransaction] Success - TransactionID: %d, UserID: %d, Deleted: %v",
+		transactionID, userID, result.IsDeleted)
+	if result.IsDeleted {
+		emitTransactionDeletedEvent(userID, transactionID)
+	}
+	return &result, nil
+}
+
+// checkTransactionBudgetAlerts runs the budget threshold check for expense transactions
+func checkTransactionBudgetAlerts(userID int, transaction *mdlFeatureOne.TransactionResponse) {
+	if transaction.Type != "expense" {
+		return
+	}
+	checkBudgetAlertsAsync(userID, &mdlFeatureOne.ExpenseResponse{
+		ID:       transaction.ID,
+		Category: transaction.Category,
+		Date:     transaction.Date,
+	})
+}
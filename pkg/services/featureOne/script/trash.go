@@ -0,0 +1,174 @@
+package scpFeatureOne
+
+import (
+	"encoding/json"
+	"go_template_v3/pkg/config"
+	"go_template_v3/pkg/global/utils"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"log"
+	"time"
+)
+
+// purgeBatchSize caps how many transactions one purge query removes
+const purgeBatchSize = 200
+
+// TrashRetention is how long deleted transactions stay restorable (TRASH_RETENTION, default 30 days)
+func TrashRetention() time.Duration {
+	return utils.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+}
+
+// ============================================
+// TRASH OPERATIONS
+// ============================================
+
+// TrashedExpenseExists checks if a deleted expense of the user is still in the trash
+func TrashedExpenseExists(userID, expenseID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM transactions
+			WHERE id = $1 AND user_id = $2 AND type = 'expense' AND deleted_at IS NOT NULL)`,
+		expenseID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[TrashedExpenseExists] Error checking expense %d for user %d: %v", expenseID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// GetExpenseTrash retrieves the user's deleted expenses, most recently deleted first
+func GetExpenseTrash(userID, limit, offset int) (*mdlFeatureOne.TrashListResponse, error) {
+	db := config.DBConnList[0]
+	retention := TrashRetention()
+	result := mdlFeatureOne.TrashListResponse{
+		Expenses:      []mdlFeatureOne.TrashedExpenseResponse{},
+		RetentionDays: int(retention.Hours() / 24),
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	err := db.Raw(`
+		SELECT COUNT(*) FROM transactions
+		WHERE user_id = ? AND type = 'expense' AND deleted_at IS NOT NULL
+	`, userID).Scan(&result.Pagination.Total).Error
+	if err != nil {
+		log.Printf("[GetExpenseTrash] Error counting trash for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	// Select the whole row so it keeps the transactions type for transaction_to_jsonb
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(jsonb_agg(
+			transaction_to_jsonb(s.t) || jsonb_build_object(
+				'deletedAt', (s.t).deleted_at,
+				'purgeAt', (s.t).deleted_at + make_interval(secs => ?)
+			) ORDER BY (s.t).deleted_at DESC, (s.t).id DESC), '[]'::jsonb)::text
+		FROM (
+			SELECT t
+			FROM transactions t
+			WHERE t.user_id = ? AND t.type = 'expense' AND t.deleted_at IS NOT NULL
+			ORDER BY t.deleted_at DESC, t.id DESC
+			LIMIT ? OFFSET ?
+		) s
+	`, retention.Seconds(), userID, limit, offset).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetExpenseTrash] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Expenses); err != nil {
+		log.Printf("[GetExpenseTrash] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetExpenseTrash] Success - UserID: %d, Count: %d", userID, len(result.Expenses))
+	return &result, nil
+}
+
+// RestoreExpense takes an expense out of the trash
+func RestoreExpense(userID, expenseID int) (*mdlFeatureOne.ExpenseResponse, error) {
+	err := config.DBConnList[0].Exec(`
+		UPDATE transactions
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND type = 'expense' AND deleted_at IS NOT NULL
+	`, expenseID, userID).Error
+
+	if err != nil {
+		log.Printf("[RestoreExpense] Error for user %d, expense %d: %v", userID, expenseID, err)
+		return nil, err
+	}
+
+	restored, err := GetExpenseByID(userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[RestoreExpense] Success - ExpenseID: %d, UserID: %d", expenseID, userID)
+	checkBudgetAlertsAsync(userID, restored)
+	emitUserEvent(userID, mdlFeatureOne.WebhookEventExpenseRestored, restored)
+	return restored, nil
+}
+
+// ============================================
+// TRASH PURGE SCHEDULER
+// ============================================
+
+// StartTrashPurgeScheduler purges transactions past the retention period on a fixed interval
+func StartTrashPurgeScheduler(interval time.Duration) {
+	log.Printf("[TrashPurgeScheduler] Started - Interval: %s, Retention: %s", interval, TrashRetention())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		PurgeDeletedTransactions(TrashRetention())
+		<-ticker.C
+	}
+}
+
+// PurgeDeletedTransactions permanently deletes transactions soft-deleted longer
+// than retention ago, then removes their attachment files from local storage or
+// Cloudinary. Only files the server recorded as attachments are removed.
+// The cutoff is computed by the database, which also set deleted_at.
+func PurgeDeletedTransactions(retention time.Duration) {
+	total := 0
+	for {
+		var jsonResult string
+		err := config.DBConnList[0].Raw(
+			`SELECT purge_deleted_transactions((CURRENT_TIMESTAMP - make_interval(secs => $1))::timestamp, $2)::text`,
+			retention.Seconds(),
+			purgeBatchSize,
+		).Scan(&jsonResult).Error
+		if err != nil {
+			log.Printf("[PurgeDeletedTransactions] Error purging with retention %s: %v", retention, err)
+			return
+		}
+
+		var result mdlFeatureOne.PurgeResult
+		if err := json.Unmarshal([]byte(jsonResult), &result); err != nil {
+			log.Printf("[PurgeDeletedTransactions] JSON parse error: %v", err)
+			return
+		}
+
+		// The rows are gone, so a file that fails to delete is only logged
+		for _, file := range result.Files {
+			RemoveAttachmentFile(file)
+		}
+
+		total += result.Purged
+		if result.Purged < purgeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("[PurgeDeletedTransactions] Completed - Purged: %d, Retention: %s", total, retention)
+	}
+}
@@ -0,0 +1,575 @@
+package scpFeatureOne
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go_template_v3/pkg/config"
+	hlpFeatureOne "go_template_v3/pkg/services/featureOne/helper"
+	mdlFeatureOne "go_template_v3/pkg/services/featureOne/model"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	webhookDeliveryBatchSize = 20
+	webhookRequestTimeout    = 10 * time.Second
+	// A claimed delivery is not picked up again for this long, which covers a
+	// worker that stops between sending and saving the result
+	webhookClaimLease = 5 * time.Minute
+)
+
+var webhookClient = &http.Client{
+	Timeout: webhookRequestTimeout,
+	// Every connection is checked against the resolved address, and no proxy
+	// is used so the address checked is the one the request goes to
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: webhookRequestTimeout,
+			Control: hlpFeatureOne.WebhookDialControl,
+		}).DialContext,
+		TLSHandshakeTimeout:   webhookRequestTimeout,
+		ResponseHeaderTimeout: webhookRequestTimeout,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       90 * time.Second,
+	},
+	// Redirects are reported as failures rather than followed
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// ============================================
+// WEBHOOK ENDPOINT OPERATIONS
+// ============================================
+
+// WebhookEndpointExists checks if a webhook endpoint exists for a user
+func WebhookEndpointExists(userID, endpointID int) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(
+		`SELECT EXISTS(SELECT 1 FROM webhook_endpoints WHERE id = $1 AND user_id = $2)`,
+		endpointID,
+		userID,
+	).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[WebhookEndpointExists] Error checking endpoint %d for user %d: %v", endpointID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// CountWebhookEndpoints returns how many webhook endpoints a user has
+func CountWebhookEndpoints(userID int) (int, error) {
+	var count int
+
+	err := config.DBConnList[0].Raw(
+		`SELECT COUNT(*) FROM webhook_endpoints WHERE user_id = ?`, userID,
+	).Scan(&count).Error
+
+	if err != nil {
+		log.Printf("[CountWebhookEndpoints] Error for user %d: %v", userID, err)
+	}
+
+	return count, err
+}
+
+// CreateWebhookEndpoint registers an endpoint with a new signing secret
+func CreateWebhookEndpoint(userID int, req *mdlFeatureOne.WebhookEndpointRequest) (*mdlFeatureOne.WebhookEndpointCreatedResponse, error) {
+	secret, err := hlpFeatureOne.GenerateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	eventsJSON, err := json.Marshal(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpointID int
+	err = config.DBConnList[0].Raw(`
+		INSERT INTO webhook_endpoints (user_id, url, description, secret, events)
+		VALUES (?, ?, ?, ?, ?::jsonb)
+		RETURNING id
+	`, userID, req.URL, req.Description, secret, string(eventsJSON)).Scan(&endpointID).Error
+
+	if err != nil {
+		log.Printf("[CreateWebhookEndpoint] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	endpoint, err := GetWebhookEndpointByID(userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[CreateWebhookEndpoint] Success - EndpointID: %d, UserID: %d, Events: %v", endpointID, userID, req.Events)
+	return &mdlFeatureOne.WebhookEndpointCreatedResponse{
+		WebhookEndpointResponse: *endpoint,
+		Secret:                  secret,
+	}, nil
+}
+
+// GetWebhookEndpoints retrieves a user's webhook endpoints, oldest first
+func GetWebhookEndpoints(userID int) ([]mdlFeatureOne.WebhookEndpointResponse, error) {
+	endpoints := []mdlFeatureOne.WebhookEndpointResponse{}
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT COALESCE(jsonb_agg(webhook_endpoint_to_jsonb(w) ORDER BY w.id), '[]'::jsonb)::text
+		FROM webhook_endpoints w
+		WHERE w.user_id = ?
+	`, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetWebhookEndpoints] Error for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &endpoints); err != nil {
+		log.Printf("[GetWebhookEndpoints] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetWebhookEndpoints] Success - UserID: %d, Count: %d", userID, len(endpoints))
+	return endpoints, nil
+}
+
+// GetWebhookEndpointByID retrieves a single webhook endpoint
+func GetWebhookEndpointByID(userID, endpointID int) (*mdlFeatureOne.WebhookEndpointResponse, error) {
+	var endpoint mdlFeatureOne.WebhookEndpointResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT webhook_endpoint_to_jsonb(w)::text
+		FROM webhook_endpoints w
+		WHERE w.id = ? AND w.user_id = ?
+	`, endpointID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetWebhookEndpointByID] Error for user %d, endpoint %d: %v", userID, endpointID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &endpoint); err != nil {
+		log.Printf("[GetWebhookEndpointByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// UpdateWebhookEndpoint replaces an endpoint's URL, description and events.
+// isActive true re-enables the endpoint and clears its failures; false pauses it.
+// Deliveries queued while an endpoint is disabled are sent once it is enabled again.
+func UpdateWebhookEndpoint(userID, endpointID int, req *mdlFeatureOne.WebhookEndpointRequest) (*mdlFeatureOne.WebhookEndpointResponse, error) {
+	eventsJSON, err := json.Marshal(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DBConnList[0].Exec(`
+		UPDATE webhook_endpoints SET
+			url           = ?,
+			description   = ?,
+			events        = ?::jsonb,
+			is_active     = COALESCE(?::boolean, is_active),
+			failure_count = CASE WHEN ?::boolean AND NOT is_active THEN 0 ELSE failure_count END,
+			disabled_at   = CASE
+				WHEN ?::boolean THEN NULL
+				WHEN ?::boolean = FALSE AND is_active THEN CURRENT_TIMESTAMP
+				ELSE disabled_at
+			END,
+			updated_at    = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?
+	`, req.URL, req.Description, string(eventsJSON), req.IsActive, req.IsActive, req.IsActive, req.IsActive,
+		endpointID, userID).Error
+
+	if err != nil {
+		log.Printf("[UpdateWebhookEndpoint] Error for user %d, endpoint %d: %v", userID, endpointID, err)
+		return nil, err
+	}
+
+	log.Printf("[UpdateWebhookEndpoint] Success - EndpointID: %d, UserID: %d", endpointID, userID)
+	return GetWebhookEndpointByID(userID, endpointID)
+}
+
+// DeleteWebhookEndpoint deletes an endpoint together with its delivery log
+func DeleteWebhookEndpoint(userID, endpointID int) error {
+	err := config.DBConnList[0].Exec(
+		`DELETE FROM webhook_endpoints WHERE id = ? AND user_id = ?`, endpointID, userID,
+	).Error
+
+	if err != nil {
+		log.Printf("[DeleteWebhookEndpoint] Error for user %d, endpoint %d: %v", userID, endpointID, err)
+		return err
+	}
+
+	log.Printf("[DeleteWebhookEndpoint] Success - EndpointID: %d, UserID: %d", endpointID, userID)
+	return nil
+}
+
+// ============================================
+// WEBHOOK DELIVERY LOG OPERATIONS
+// ============================================
+
+// WebhookDeliveryExists checks if a delivery belongs to the user's endpoint
+func WebhookDeliveryExists(userID, endpointID int, deliveryID int64) bool {
+	var exists bool
+
+	err := config.DBConnList[0].Raw(`
+		SELECT EXISTS(
+			SELECT 1 FROM webhook_deliveries d
+			JOIN webhook_endpoints w ON w.id = d.endpoint_id
+			WHERE d.id = $1 AND d.endpoint_id = $2 AND w.user_id = $3
+		)
+	`, deliveryID, endpointID, userID).Scan(&exists).Error
+
+	if err != nil {
+		log.Printf("[WebhookDeliveryExists] Error checking delivery %d for user %d: %v", deliveryID, userID, err)
+		return false
+	}
+
+	return exists
+}
+
+// GetWebhookDeliveries retrieves an endpoint's delivery log, newest first
+func GetWebhookDeliveries(userID, endpointID int, filters *mdlFeatureOne.WebhookDeliveryFilters) (*mdlFeatureOne.WebhookDeliveryListResponse, error) {
+	db := config.DBConnList[0]
+	result := mdlFeatureOne.WebhookDeliveryListResponse{
+		Deliveries: []mdlFeatureOne.WebhookDeliveryResponse{},
+		Pagination: mdlFeatureOne.PaginationResponse{
+			Limit:  filters.Limit,
+			Offset: filters.Offset,
+		},
+	}
+
+	const where = `
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints w ON w.id = d.endpoint_id
+		WHERE d.endpoint_id = ? AND w.user_id = ?
+		  AND (?::text = '' OR d.status = ?::text)
+		  AND (?::text = '' OR d.event = ?::text)
+	`
+	args := []interface{}{endpointID, userID, filters.Status, filters.Status, filters.Event, filters.Event}
+
+	err := db.Raw(`SELECT COUNT(*) `+where, args...).Scan(&result.Pagination.Total).Error
+	if err != nil {
+		log.Printf("[GetWebhookDeliveries] Error counting deliveries for endpoint %d: %v", endpointID, err)
+		return nil, err
+	}
+
+	var jsonResult string
+	err = db.Raw(`
+		SELECT COALESCE(jsonb_agg(webhook_delivery_to_jsonb(x) ORDER BY x.created_at DESC, x.id DESC), '[]'::jsonb)::text
+		FROM (
+			SELECT d.* `+where+`
+			ORDER BY d.created_at DESC, d.id DESC
+			LIMIT ? OFFSET ?
+		) x
+	`, append(args, filters.Limit, filters.Offset)...).Scan(&jsonResult).Error
+	if err != nil {
+		log.Printf("[GetWebhookDeliveries] Error for user %d, endpoint %d: %v", userID, endpointID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &result.Deliveries); err != nil {
+		log.Printf("[GetWebhookDeliveries] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[GetWebhookDeliveries] Success - EndpointID: %d, UserID: %d, Count: %d",
+		endpointID, userID, len(result.Deliveries))
+	return &result, nil
+}
+
+// GetWebhookDeliveryByID retrieves a single delivery of an endpoint
+func GetWebhookDeliveryByID(userID, endpointID int, deliveryID int64) (*mdlFeatureOne.WebhookDeliveryResponse, error) {
+	var delivery mdlFeatureOne.WebhookDeliveryResponse
+	var jsonResult string
+
+	err := config.DBConnList[0].Raw(`
+		SELECT webhook_delivery_to_jsonb(d)::text
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints w ON w.id = d.endpoint_id
+		WHERE d.id = ? AND d.endpoint_id = ? AND w.user_id = ?
+	`, deliveryID, endpointID, userID).Scan(&jsonResult).Error
+
+	if err != nil {
+		log.Printf("[GetWebhookDeliveryByID] Error for user %d, delivery %d: %v", userID, deliveryID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(jsonResult), &delivery); err != nil {
+		log.Printf("[GetWebhookDeliveryByID] JSON parse error: %v", err)
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// RedeliverWebhook queues a delivery to be sent again right away with a fresh
+// set of attempts. It keeps its ID, so the receiver sees the same event ID.
+func RedeliverWebhook(userID, endpointID int, deliveryID int64) (*mdlFeatureOne.WebhookDeliveryResponse, error) {
+	err := config.DBConnList[0].Exec(`
+		UPDATE webhook_deliveries d SET
+			status          = 'pending',
+			attempts        = 0,
+			next_attempt_at = CURRENT_TIMESTAMP,
+			delivered_at    = NULL
+		FROM webhook_endpoints w
+		WHERE w.id = d.endpoint_id AND d.id = ? AND d.endpoint_id = ? AND w.user_id = ?
+	`, deliveryID, endpointID, userID).Error
+
+	if err != nil {
+		log.Printf("[RedeliverWebhook] Error for user %d, delivery %d: %v", userID, deliveryID, err)
+		return nil, err
+	}
+
+	log.Printf("[RedeliverWebhook] Queued - DeliveryID: %d, EndpointID: %d, UserID: %d", deliveryID, endpointID, userID)
+	return GetWebhookDeliveryByID(userID, endpointID, deliveryID)
+}
+
+// ============================================
+// WEBHOOK EVENTS
+// ============================================
+
+// EnqueueWebhookEvent queues an event for every active endpoint of the user that
+// subscribes to it. The change it reports is already saved, so failures are logged.
+func EnqueueWebhookEvent(userID int, event string, data interface{}) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[EnqueueWebhookEvent] Error marshaling %s for user %d: %v", event, userID, err)
+		return
+	}
+
+	result := config.DBConnList[0].Exec(`
+		INSERT INTO webhook_deliveries (endpoint_id, event, payload)
+		SELECT w.id, ?, ?::jsonb
+		FROM webhook_endpoints w
+		WHERE w.user_id = ? AND w.is_active AND w.events @> jsonb_build_array(?::text)
+	`, event, string(dataJSON), userID, event)
+
+	if result.Error != nil {
+		log.Printf("[EnqueueWebhookEvent] Error queuing %s for user %d: %v", event, userID, result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("[EnqueueWebhookEvent] Queued - UserID: %d, Event: %s, Deliveries: %d",
+			userID, event, result.RowsAffected)
+	}
+}
+
+// ============================================
+// WEBHOOK DELIVERY WORKER
+// ============================================
+
+// StartWebhookDeliveryScheduler sends due webhook deliveries on a fixed interval
+func StartWebhookDeliveryScheduler(interval time.Duration) {
+	log.Printf("[WebhookDeliveryScheduler] Started - Interval: %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ProcessDueWebhookDeliveries()
+		<-ticker.C
+	}
+}
+
+// ProcessDueWebhookDeliveries sends every pending delivery whose next attempt is due.
+// Deliveries are claimed with SKIP LOCKED, so several instances can run side by side.
+func ProcessDueWebhookDeliveries() {
+	total := 0
+	for {
+		deliveries, err := claimWebhookDeliveries()
+		if err != nil {
+			log.Printf("[ProcessDueWebhookDeliveries] Error claiming deliveries: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(d *mdlFeatureOne.WebhookDeliveryEntity) {
+				defer wg.Done()
+				sendWebhookDelivery(d)
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		total += len(deliveries)
+		if len(deliveries) < webhookDeliveryBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("[ProcessDueWebhookDeliveries] Completed - Deliveries: %d", total)
+	}
+}
+
+// claimWebhookDeliveries takes the next due deliveries of active endpoints and
+// moves their next attempt past the lease so no other worker sends them meanwhile
+func claimWebhookDeliveries() ([]mdlFeatureOne.WebhookDeliveryEntity, error) {
+	var deliveries []mdlFeatureOne.WebhookDeliveryEntity
+
+	err := config.DBConnList[0].Raw(`
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => ?)
+		FROM webhook_endpoints w
+		WHERE w.id = d.endpoint_id
+		  AND d.id IN (
+			SELECT q.id
+			FROM webhook_deliveries q
+			JOIN webhook_endpoints e ON e.id = q.endpoint_id
+			WHERE q.status = 'pending' AND q.next_attempt_at <= CURRENT_TIMESTAMP AND e.is_active
+			ORDER BY q.next_attempt_at, q.id
+			LIMIT ?
+			FOR UPDATE OF q SKIP LOCKED
+		  )
+		RETURNING d.id, d.endpoint_id, w.user_id, w.url, w.secret, d.event, d.payload::text AS payload,
+			d.attempts, to_jsonb(d.created_at) #>> '{}' AS created_at
+	`, webhookClaimLease.Seconds(), webhookDeliveryBatchSize).Scan(&deliveries).Error
+
+	return deliveries, err
+}
+
+// sendWebhookDelivery POSTs the signed event and records the outcome. Any 2xx
+// answer counts as delivered; everything else is retried with backoff.
+func sendWebhookDelivery(d *mdlFeatureOne.WebhookDeliveryEntity) {
+	body, err := json.Marshal(mdlFeatureOne.WebhookEventEnvelope{
+		ID:        d.ID,
+		Event:     d.Event,
+		CreatedAt: d.CreatedAt,
+		Data:      json.RawMessage(d.Payload),
+	})
+	if err != nil {
+		recordWebhookAttempt(d, nil, err.Error(), 0)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		recordWebhookAttempt(d, nil, err.Error(), 0)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go_template_v3-webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Signature", hlpFeatureOne.SignWebhookPayload(d.Secret, time.Now(), body))
+
+	start := time.Now()
+	resp, err := webhookClient.Do(req)
+	duration := time.Since(start)
+	if err != nil {
+		recordWebhookAttempt(d, nil, err.Error(), duration)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		recordWebhookAttempt(d, &resp.StatusCode, "", duration)
+		return
+	}
+
+	// The response body is not kept: the delivery log is readable by the
+	// endpoint's owner and should not echo what the target answered
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	recordWebhookAttempt(d, &resp.StatusCode, fmt.Sprintf("HTTP %d", resp.StatusCode), duration)
+}
+
+// recordWebhookAttempt saves the outcome of one try. A failure schedules the next
+// try with backoff, or marks the delivery failed after the last attempt, and
+// disables the endpoint once too many tries in a row have failed.
+func recordWebhookAttempt(d *mdlFeatureOne.WebhookDeliveryEntity, statusCode *int, errMessage string, duration time.Duration) {
+	attempts := d.Attempts + 1
+	delivered := errMessage == ""
+	disabled := false
+
+	err := config.DBConnList[0].Transaction(func(tx *gorm.DB) error {
+		if delivered {
+			err := tx.Exec(`
+				UPDATE webhook_deliveries SET
+					status = 'delivered', attempts = ?, last_attempt_at = CURRENT_TIMESTAMP,
+					last_status_code = ?, last_error = NULL, duration_ms = ?, delivered_at = CURRENT_TIMESTAMP
+				WHERE id = ?
+			`, attempts, statusCode, duration.Milliseconds(), d.ID).Error
+			if err != nil {
+				return err
+			}
+
+			return tx.Exec(`
+				UPDATE webhook_endpoints SET failure_count = 0, last_success_at = CURRENT_TIMESTAMP
+				WHERE id = ?
+			`, d.EndpointID).Error
+		}
+
+		status := mdlFeatureOne.WebhookDeliveryStatusPending
+		if attempts >= hlpFeatureOne.MaxWebhookAttempts {
+			status = mdlFeatureOne.WebhookDeliveryStatusFailed
+		}
+		err := tx.Exec(`
+			UPDATE webhook_deliveries SET
+				status = ?, attempts = ?, last_attempt_at = CURRENT_TIMESTAMP,
+				next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => ?),
+				last_status_code = ?, last_error = ?, duration_ms = ?
+			WHERE id = ?
+		`, status, attempts, hlpFeatureOne.WebhookRetryDelay(attempts).Seconds(),
+			statusCode, errMessage, duration.Milliseconds(), d.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+			UPDATE webhook_endpoints SET failure_count = failure_count + 1, last_failure_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, d.EndpointID).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			UPDATE webhook_endpoints SET is_active = FALSE, disabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND is_active AND failure_count >= ?
+		`, d.EndpointID, hlpFeatureOne.WebhookDisableAfter)
+		disabled = result.RowsAffected > 0
+		return result.Error
+	})
+
+	if err != nil {
+		log.Printf("[recordWebhookAttempt] Error saving delivery %d: %v", d.ID, err)
+		return
+	}
+
+	if delivered {
+		log.Printf("[recordWebhookAttempt] Delivered - DeliveryID: %d, EndpointID: %d, Event: %s, Attempt: %d",
+			d.ID, d.EndpointID, d.Event, attempts)
+		return
+	}
+
+	log.Printf("[recordWebhookAttempt] Failed - DeliveryID: %d, EndpointID: %d, Attempt: %d/%d, Error: %s",
+		d.ID, d.EndpointID, attempts, hlpFeatureOne.MaxWebhookAttempts, errMessage)
+
+	if disabled {
+		log.Printf("[recordWebhookAttempt] Endpoint disabled - EndpointID: %d, UserID: %d", d.EndpointID, d.UserID)
+		message := fmt.Sprintf("Webhook %s was disabled after %d failed delivery attempts in a row. Fix the endpoint and enable it again to resume.",
+			d.URL, hlpFeatureOne.WebhookDisableAfter)
+		data := map[string]interface{}{"endpointId": d.EndpointID, "url": d.URL}
+		if _, err := CreateNotification(d.UserID, "webhook_disabled", "Webhook disabled", message, data); err != nil {
+			log.Printf("[recordWebhookAttempt] Error notifying user %d: %v", d.UserID, err)
+		}
+	}
+}
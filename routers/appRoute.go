@@ -56,22 +56,189 @@ func APIRoute(app *fiber.App) {
 	// ============================================
 	expenseGroup := publicV1.Group("/expenses", middleware.AuthMiddleware)
 
-	// Batch operations
-	expenseGroup.Put("/batch", ctrFeatureOne.BatchUpdateExpenses)            // Sync
-	expenseGroup.Put("/batch-async", ctrFeatureOne.BatchUpdateExpensesAsync) // Async
+	// Batch operations; creating routes take an optional Idempotency-Key
+	// (fiber runs the trailing middleware before the handler)
+	expenseGroup.Put("/batch", ctrFeatureOne.BatchUpdateExpenses)                                              // Sync
+	expenseGroup.Put("/batch-async", ctrFeatureOne.BatchUpdateExpensesAsync, middleware.IdempotencyMiddleware) // Async
 	expenseGroup.Get("/batch-async/:id", ctrFeatureOne.GetBatchJobStatus)
-	expenseGroup.Post("/batch-upload", ctrFeatureOne.BatchUploadExpensesFromCSV) // CSV Upload
+	expenseGroup.Post("/batch-upload", ctrFeatureOne.BatchUploadExpensesFromCSV, middleware.IdempotencyMiddleware) // CSV Upload
+	expenseGroup.Post("/import-statement", ctrFeatureOne.ImportBankStatement)                                      // OFX/QFX, QIF or CAMT.053
+	expenseGroup.Get("/export", ctrFeatureOne.ExportExpenses)                                                      // CSV, XLSX, JSON or PDF
+	expenseGroup.Get("/trash", ctrFeatureOne.GetExpenseTrash)
+	expenseGroup.Get("/duplicates", ctrFeatureOne.GetDuplicateExpenses) // Suspected duplicate clusters
+
+	// Saved views; GET /expenses and /export take ?viewId=
+	expenseGroup.Post("/views", ctrFeatureOne.CreateExpenseView)
+	expenseGroup.Get("/views", ctrFeatureOne.GetExpenseViews)
+	expenseGroup.Get("/views/:id", ctrFeatureOne.GetExpenseViewByID)
+	expenseGroup.Put("/views/:id", ctrFeatureOne.UpdateExpenseView)
+	expenseGroup.Delete("/views/:id", ctrFeatureOne.DeleteExpenseView)
 
 	// Basic CRUD
-	expenseGroup.Post("/", ctrFeatureOne.CreateExpense)
-	expenseGroup.Post("/v2", ctrFeatureOne.CreateExpenseV2)                     // With file upload
-	expenseGroup.Post("/cloudinary", ctrFeatureOne.CreateExpenseWithCloudinary) // With cloudinary file upload
+	expenseGroup.Post("/", ctrFeatureOne.CreateExpense, middleware.IdempotencyMiddleware)
+	expenseGroup.Post("/v2", ctrFeatureOne.CreateExpenseV2, middleware.IdempotencyMiddleware)                     // With file upload
+	expenseGroup.Post("/cloudinary", ctrFeatureOne.CreateExpenseWithCloudinary, middleware.IdempotencyMiddleware) // With cloudinary file upload
 	expenseGroup.Get("/", ctrFeatureOne.GetExpenses)
 	expenseGroup.Get("/:id", ctrFeatureOne.GetExpense)
 	expenseGroup.Put("/:id", ctrFeatureOne.UpdateExpense)
+	expenseGroup.Patch("/:id", ctrFeatureOne.PatchExpense) // Merge patch or JSON Patch
 	expenseGroup.Delete("/:id", ctrFeatureOne.DeleteExpense)
+	expenseGroup.Post("/:id/restore", ctrFeatureOne.RestoreExpense)
+	expenseGroup.Get("/:id/history", ctrFeatureOne.GetExpenseHistory)
+	expenseGroup.Post("/:id/revert/:revision", ctrFeatureOne.RevertExpense)
+	expenseGroup.Put("/:id/split", ctrFeatureOne.SetExpenseSplit)
+	expenseGroup.Get("/:id/split", ctrFeatureOne.GetExpenseSplit)
+	expenseGroup.Delete("/:id/split", ctrFeatureOne.DeleteExpenseSplit)
+	expenseGroup.Post("/:id/attachments", ctrFeatureOne.UploadExpenseAttachments)
+	expenseGroup.Get("/:id/attachments", ctrFeatureOne.GetExpenseAttachments)
+	expenseGroup.Delete("/:id/attachments/:attachmentId", ctrFeatureOne.DeleteExpenseAttachment)
 	expenseGroup.Delete("/cloudinary/:id", ctrFeatureOne.DeleteExpenseWithCloudinary)
 
+	// ============================================
+	// TRANSACTION ROUTES (PROTECTED)
+	// ============================================
+	transactionGroup := publicV1.Group("/transactions", middleware.AuthMiddleware)
+	transactionGroup.Post("/", ctrFeatureOne.CreateTransaction)
+	transactionGroup.Get("/", ctrFeatureOne.GetTransactions)
+	transactionGroup.Get("/:id", ctrFeatureOne.GetTransaction)
+	transactionGroup.Put("/:id", ctrFeatureOne.UpdateTransaction)
+	transactionGroup.Delete("/:id", ctrFeatureOne.DeleteTransaction)
+
+	// ============================================
+	// ACCOUNT ROUTES (PROTECTED)
+	// ============================================
+	accountGroup := publicV1.Group("/accounts", middleware.AuthMiddleware)
+	accountGroup.Post("/", ctrFeatureOne.CreateAccount)
+	accountGroup.Get("/", ctrFeatureOne.GetAccounts)
+	accountGroup.Get("/:id", ctrFeatureOne.GetAccountByID)
+	accountGroup.Put("/:id", ctrFeatureOne.UpdateAccount)
+	accountGroup.Get("/:id/ledger", ctrFeatureOne.GetAccountLedger)
+	accountGroup.Get("/:id/balances", ctrFeatureOne.GetAccountBalances)
+	accountGroup.Post("/:id/reconciliations", ctrFeatureOne.ReconcileAccount)
+	accountGroup.Get("/:id/reconciliations", ctrFeatureOne.GetAccountReconciliations)
+	accountGroup.Delete("/:id/reconciliations/:reconciliationId", ctrFeatureOne.DeleteAccountReconciliation)
+
+	// ============================================
+	// TAG ROUTES (PROTECTED)
+	// ============================================
+	tagGroup := publicV1.Group("/tags", middleware.AuthMiddleware)
+	tagGroup.Post("/", ctrFeatureOne.CreateTag)
+	tagGroup.Get("/", ctrFeatureOne.GetTags)
+	tagGroup.Put("/:id", ctrFeatureOne.RenameTag)
+	tagGroup.Post("/:id/merge", ctrFeatureOne.MergeTag)
+	tagGroup.Delete("/:id", ctrFeatureOne.DeleteTag)
+
+	// ============================================
+	// MERCHANT ROUTES (PROTECTED)
+	// ============================================
+	merchantGroup := publicV1.Group("/merchants", middleware.AuthMiddleware)
+	merchantGroup.Post("/", ctrFeatureOne.CreateMerchant)
+	merchantGroup.Get("/", ctrFeatureOne.GetMerchants)
+	merchantGroup.Put("/:id", ctrFeatureOne.RenameMerchant)
+	merchantGroup.Delete("/:id", ctrFeatureOne.DeleteMerchant)
+
+	// ============================================
+	// CATEGORIZATION RULE ROUTES (PROTECTED)
+	// ============================================
+	ruleGroup := publicV1.Group("/categorization-rules", middleware.AuthMiddleware)
+	ruleGroup.Post("/", ctrFeatureOne.CreateCategorizationRule)
+	ruleGroup.Get("/", ctrFeatureOne.GetCategorizationRules)
+	ruleGroup.Post("/apply", ctrFeatureOne.ApplyCategorizationRules, middleware.IdempotencyMiddleware) // Batch job; status via /expenses/batch-async/:id
+	ruleGroup.Put("/:id", ctrFeatureOne.UpdateCategorizationRule)
+	ruleGroup.Delete("/:id", ctrFeatureOne.DeleteCategorizationRule)
+
+	// ============================================
+	// REPORT ROUTES (PROTECTED)
+	// ============================================
+	reportGroup := publicV1.Group("/reports", middleware.AuthMiddleware)
+	reportGroup.Get("/summary", ctrFeatureOne.GetReportSummary)
+	reportGroup.Get("/by-category", ctrFeatureOne.GetCategoryReport)
+	reportGroup.Get("/over-time", ctrFeatureOne.GetTimeReport)
+	reportGroup.Get("/by-tag", ctrFeatureOne.GetTagReport)
+	reportGroup.Get("/top-titles", ctrFeatureOne.GetTitleReport)
+
+	// ============================================
+	// RECURRING EXPENSE ROUTES (PROTECTED)
+	// ============================================
+	recurringGroup := publicV1.Group("/recurring-expenses", middleware.AuthMiddleware)
+	recurringGroup.Post("/", ctrFeatureOne.CreateRecurringExpense)
+	recurringGroup.Get("/", ctrFeatureOne.GetRecurringExpenses)
+	recurringGroup.Get("/:id", ctrFeatureOne.GetRecurringExpense)
+	recurringGroup.Get("/:id/preview", ctrFeatureOne.PreviewRecurringExpense)
+	recurringGroup.Put("/:id", ctrFeatureOne.UpdateRecurringExpense)
+	recurringGroup.Put("/:id/pause", ctrFeatureOne.PauseRecurringExpense)
+	recurringGroup.Put("/:id/resume", ctrFeatureOne.ResumeRecurringExpense)
+	recurringGroup.Delete("/:id", ctrFeatureOne.DeleteRecurringExpense)
+
+	// ============================================
+	// BUDGET ROUTES (PROTECTED)
+	// ============================================
+	budgetGroup := publicV1.Group("/budgets", middleware.AuthMiddleware)
+	budgetGroup.Post("/", ctrFeatureOne.CreateBudget)
+	budgetGroup.Get("/", ctrFeatureOne.GetBudgets)
+	budgetGroup.Get("/status", ctrFeatureOne.GetBudgetStatus)
+	budgetGroup.Get("/:id", ctrFeatureOne.GetBudget)
+	budgetGroup.Put("/:id", ctrFeatureOne.UpdateBudget)
+	budgetGroup.Delete("/:id", ctrFeatureOne.DeleteBudget)
+
+	// ============================================
+	// BALANCE & SETTLEMENT ROUTES (PROTECTED)
+	// ============================================
+	balanceGroup := publicV1.Group("/balances", middleware.AuthMiddleware)
+	balanceGroup.Get("/", ctrFeatureOne.GetBalances)
+	balanceGroup.Get("/:userId", ctrFeatureOne.GetBalanceWithUser)
+
+	settlementGroup := publicV1.Group("/settlements", middleware.AuthMiddleware)
+	settlementGroup.Post("/", ctrFeatureOne.CreateSettlement)
+	settlementGroup.Get("/", ctrFeatureOne.GetSettlements)
+	settlementGroup.Delete("/:id", ctrFeatureOne.DeleteSettlement)
+
+	// ============================================
+	// REIMBURSEMENT ROUTES (PROTECTED)
+	// ============================================
+	reimbursementGroup := publicV1.Group("/reimbursements", middleware.AuthMiddleware)
+	reimbursementGroup.Post("/", ctrFeatureOne.CreateReimbursementReport)
+	reimbursementGroup.Get("/", ctrFeatureOne.GetReimbursementReports) // ?role=owner|approver
+	reimbursementGroup.Get("/:id", ctrFeatureOne.GetReimbursementReport)
+	reimbursementGroup.Put("/:id", ctrFeatureOne.UpdateReimbursementReport)
+	reimbursementGroup.Delete("/:id", ctrFeatureOne.DeleteReimbursementReport)
+	reimbursementGroup.Post("/:id/transitions", ctrFeatureOne.TransitionReimbursementReport) // submit, approve, reject, pay
+
+	// ============================================
+	// NOTIFICATION ROUTES (PROTECTED)
+	// ============================================
+	notificationGroup := publicV1.Group("/notifications", middleware.AuthMiddleware)
+	notificationGroup.Get("/", ctrFeatureOne.GetNotifications)
+	notificationGroup.Put("/read-all", ctrFeatureOne.MarkAllNotificationsRead)
+	notificationGroup.Put("/:id/read", ctrFeatureOne.MarkNotificationRead)
+
+	// ============================================
+	// WEBHOOK ROUTES (PROTECTED)
+	// ============================================
+	webhookGroup := publicV1.Group("/webhooks", middleware.AuthMiddleware)
+	webhookGroup.Post("/", ctrFeatureOne.CreateWebhook)
+	webhookGroup.Get("/", ctrFeatureOne.GetWebhooks)
+	webhookGroup.Get("/:id", ctrFeatureOne.GetWebhook)
+	webhookGroup.Put("/:id", ctrFeatureOne.UpdateWebhook)
+	webhookGroup.Delete("/:id", ctrFeatureOne.DeleteWebhook)
+	webhookGroup.Get("/:id/deliveries", ctrFeatureOne.GetWebhookDeliveries) // ?status=&event=
+	webhookGroup.Get("/:id/deliveries/:deliveryId", ctrFeatureOne.GetWebhookDelivery)
+	webhookGroup.Post("/:id/deliveries/:deliveryId/redeliver", ctrFeatureOne.RedeliverWebhook)
+
+	// ============================================
+	// LIVE EVENT ROUTES (PROTECTED)
+	// ============================================
+	eventGroup := publicV1.Group("/events")
+	eventGroup.Post("/tickets", ctrFeatureOne.CreateEventStreamTicket, middleware.AuthMiddleware)
+	eventGroup.Get("/stream", ctrFeatureOne.StreamEvents, middleware.EventStreamAuthMiddleware) // Server-Sent Events; bearer token or ?ticket=
+
+	// ============================================
+	// SYNC ROUTES (PROTECTED)
+	// ============================================
+	syncGroup := publicV1.Group("/sync", middleware.AuthMiddleware)
+	syncGroup.Get("/", ctrFeatureOne.GetSync) // ?since=<token>&limit=
+	syncGroup.Post("/", ctrFeatureOne.PushSync)
+
 	// ============================================
 	// BATCH JOB ROUTES (PROTECTED)
 	// ============================================